@@ -0,0 +1,145 @@
+// Package analytics calculates performance statistics from value series.
+//
+// Invalid observations should be passed as NaN and are skipped.
+package analytics
+
+import (
+	"gobacktrader/calendar"
+	"math"
+	"time"
+)
+
+// validValues returns the values that are not NaN.
+func validValues(values []float64) []float64 {
+	var valid []float64
+	for _, value := range values {
+		if !math.IsNaN(value) {
+			valid = append(valid, value)
+		}
+	}
+	return valid
+}
+
+// Returns returns the simple returns between consecutive valid values.
+func Returns(values []float64) []float64 {
+	valid := validValues(values)
+	if len(valid) < 2 {
+		return nil
+	}
+	returns := make([]float64, len(valid)-1)
+	for i := 1; i < len(valid); i++ {
+		returns[i-1] = valid[i]/valid[i-1] - 1
+	}
+	return returns
+}
+
+// TotalReturn returns the return from the first to the last valid value,
+// or NaN if there are fewer than two valid values.
+func TotalReturn(values []float64) float64 {
+	valid := validValues(values)
+	if len(valid) < 2 {
+		return math.NaN()
+	}
+	return valid[len(valid)-1]/valid[0] - 1
+}
+
+// AnnualisedReturn returns the compound annual growth rate given
+// the number of observations per year, such as 252 for daily values.
+func AnnualisedReturn(values []float64, periodsPerYear float64) float64 {
+	valid := validValues(values)
+	if len(valid) < 2 {
+		return math.NaN()
+	}
+	years := float64(len(valid)-1) / periodsPerYear
+	return math.Pow(valid[len(valid)-1]/valid[0], 1/years) - 1
+}
+
+// PeriodsPerYear returns the number of observations per year for
+// observations at some times on a trading calendar. This is the number
+// of observations per trading day scaled by the calendar's annualisation
+// factor, so daily observations give the calendar's trading days per year.
+// It is NaN where the times span no trading days.
+func PeriodsPerYear(cal calendar.ITradingCalendar, times []time.Time) float64 {
+	if len(times) < 2 {
+		return math.NaN()
+	}
+	first, last := times[0], times[len(times)-1]
+	tradingDays := len(calendar.TradingDays(cal, first, last)) - 1
+	if tradingDays < 1 {
+		return math.NaN()
+	}
+	perDay := float64(len(times)-1) / float64(tradingDays)
+	return perDay * calendar.AnnualisationFactor(cal, first, last)
+}
+
+// Mean returns the mean of the valid values.
+func Mean(values []float64) float64 {
+	valid := validValues(values)
+	if len(valid) == 0 {
+		return math.NaN()
+	}
+	total := 0.0
+	for _, value := range valid {
+		total += value
+	}
+	return total / float64(len(valid))
+}
+
+// StdDev returns the sample standard deviation of the valid values.
+func StdDev(values []float64) float64 {
+	valid := validValues(values)
+	if len(valid) < 2 {
+		return math.NaN()
+	}
+	mean := Mean(valid)
+	total := 0.0
+	for _, value := range valid {
+		total += (value - mean) * (value - mean)
+	}
+	return math.Sqrt(total / float64(len(valid)-1))
+}
+
+// Volatility returns the annualised standard deviation of returns.
+func Volatility(values []float64, periodsPerYear float64) float64 {
+	return StdDev(Returns(values)) * math.Sqrt(periodsPerYear)
+}
+
+// Sharpe returns the annualised Sharpe ratio of the returns of some
+// values given an annual risk free rate. This is NaN where the
+// returns have no dispersion.
+func Sharpe(values []float64, periodsPerYear float64, riskFreeRate float64) float64 {
+	returns := Returns(values)
+	stdev := StdDev(returns)
+	if math.IsNaN(stdev) || stdev == 0 {
+		return math.NaN()
+	}
+	excess := Mean(returns) - riskFreeRate/periodsPerYear
+	return excess / stdev * math.Sqrt(periodsPerYear)
+}
+
+// Drawdowns returns the fall from the running peak at each valid value,
+// as a non-positive fraction of that peak.
+func Drawdowns(values []float64) []float64 {
+	valid := validValues(values)
+	drawdowns := make([]float64, len(valid))
+	peak := math.Inf(-1)
+	for i, value := range valid {
+		peak = math.Max(peak, value)
+		drawdowns[i] = value/peak - 1
+	}
+	return drawdowns
+}
+
+// MaxDrawdown returns the largest fall from a running peak as a
+// non-positive fraction, or NaN where there are no valid values.
+func MaxDrawdown(values []float64) float64 {
+	drawdowns := Drawdowns(values)
+	if len(drawdowns) == 0 {
+		return math.NaN()
+	}
+	maxDrawdown := 0.0
+	for _, drawdown := range drawdowns {
+		maxDrawdown = math.Min(maxDrawdown, drawdown)
+	}
+	return maxDrawdown
+}
@@ -0,0 +1,92 @@
+package analytics
+
+import (
+	"gobacktrader/btutil"
+	"gobacktrader/calendar"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReturns(t *testing.T) {
+	returns := Returns([]float64{100, math.NaN(), 110, 99})
+	if len(returns) != 2 || btutil.Round4dp(returns[0]) != 0.1 || btutil.Round4dp(returns[1]) != -0.1 {
+		t.Errorf("Unexpected returns - %v", returns)
+	}
+	if Returns([]float64{100}) != nil {
+		t.Error("Expecting no returns for a single value")
+	}
+	if btutil.Round4dp(TotalReturn([]float64{100, 110, 99})) != -0.01 {
+		t.Error("Unexpected total return")
+	}
+	if !math.IsNaN(TotalReturn(nil)) {
+		t.Error("Expecting NaN total return without values")
+	}
+}
+
+func TestAnnualisedReturn(t *testing.T) {
+	// doubling over two years of quarterly values
+	values := []float64{100, 110, 120, 130, 140, 150, 160, 180, 200}
+	if r := AnnualisedReturn(values, 4); btutil.Round4dp(r) != btutil.Round4dp(math.Sqrt2-1) {
+		t.Errorf("Unexpected annualised return - got %0.6f", r)
+	}
+}
+
+func TestPeriodsPerYear(t *testing.T) {
+	cal := calendar.NewWeekdayCalendar()
+
+	// daily observations over a week of 2021, which has 261 weekdays
+	var daily []time.Time
+	for day := 1; day <= 5; day++ {
+		daily = append(daily, btutil.Date(2021, 3, day))
+	}
+	if p := PeriodsPerYear(cal, daily); p != 261 {
+		t.Errorf("Unexpected daily periods per year - got %0.2f", p)
+	}
+
+	// observations every other trading day
+	alternate := []time.Time{daily[0], daily[2], daily[4]}
+	if p := PeriodsPerYear(cal, alternate); p != 130.5 {
+		t.Errorf("Unexpected alternate periods per year - got %0.2f", p)
+	}
+
+	if !math.IsNaN(PeriodsPerYear(cal, daily[:1])) {
+		t.Error("Expecting NaN periods per year for a single observation")
+	}
+	weekend := []time.Time{btutil.Date(2021, 3, 6), btutil.Date(2021, 3, 7)}
+	if !math.IsNaN(PeriodsPerYear(cal, weekend)) {
+		t.Error("Expecting NaN periods per year without trading days")
+	}
+}
+
+func TestVolatilityAndSharpe(t *testing.T) {
+	if s := StdDev([]float64{2, 4, 4, 4, 5, 5, 7, 9}); btutil.Round4dp(s) != btutil.Round4dp(math.Sqrt(32.0/7.0)) {
+		t.Errorf("Unexpected standard deviation - got %0.6f", s)
+	}
+	values := []float64{100, 101, 100, 101, 100}
+	returns := Returns(values)
+	expected := Mean(returns) / StdDev(returns) * math.Sqrt(252)
+	if s := Sharpe(values, 252, 0); btutil.Round4dp(s) != btutil.Round4dp(expected) {
+		t.Errorf("Unexpected Sharpe ratio - wanted %0.6f, got %0.6f", expected, s)
+	}
+	if v := Volatility(values, 252); btutil.Round4dp(v) != btutil.Round4dp(StdDev(returns)*math.Sqrt(252)) {
+		t.Errorf("Unexpected volatility - got %0.6f", v)
+	}
+	if !math.IsNaN(Sharpe([]float64{100, 100, 100}, 252, 0)) {
+		t.Error("Expecting NaN Sharpe ratio without dispersion")
+	}
+}
+
+func TestDrawdown(t *testing.T) {
+	values := []float64{100, 120, 90, 130, 117}
+	drawdowns := Drawdowns(values)
+	if btutil.Round4dp(drawdowns[2]) != -0.25 || btutil.Round4dp(drawdowns[4]) != -0.1 {
+		t.Errorf("Unexpected drawdowns - %v", drawdowns)
+	}
+	if d := MaxDrawdown(values); btutil.Round4dp(d) != -0.25 {
+		t.Errorf("Unexpected max drawdown - wanted -0.25, got %0.4f", d)
+	}
+	if !math.IsNaN(MaxDrawdown(nil)) {
+		t.Error("Expecting NaN max drawdown without values")
+	}
+}
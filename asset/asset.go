@@ -3,6 +3,7 @@ package asset
 
 import (
 	"gobacktrader/btutil"
+	"gobacktrader/calendar"
 	"time"
 )
 
@@ -15,6 +16,8 @@ type Asset struct {
 	baseCurrency string
 	multiplier   float64
 	value        Price
+	calendar     calendar.ITradingCalendar
+	tradingRules TradingRules
 }
 
 // IAssetReadOnly defines the interface for read only assets.
@@ -27,6 +30,14 @@ type IAssetReadOnly interface {
 	GetValue() Price
 	TakeSnapshot(time.Time, iHasGetPrice)
 	GetHistory() History
+	GetPriceSeries() *PriceSeries
+}
+
+// IHasCalendar defines the interface for assets that
+// trade according to some trading calendar. GetCalendar
+// returns false where no calendar has been set explicitly.
+type IHasCalendar interface {
+	GetCalendar() (calendar.ITradingCalendar, bool)
 }
 
 // IAssetWriteOnly defines the interface for write only assets.
@@ -46,6 +57,7 @@ func NewAsset(ticker string, baseCurrency string) (*Asset, error) {
 		baseCurrency: baseCurrency,
 		multiplier:   defaultMultiplier,
 	}
+	asset.series = NewPriceSeries()
 	return &asset, err
 }
 
@@ -59,6 +71,7 @@ func NewAssetWithMultiplier(ticker string, baseCurrency string, multiplier float
 		baseCurrency: baseCurrency,
 		multiplier:   multiplier,
 	}
+	asset.series = NewPriceSeries()
 	return &asset, err
 }
 
@@ -77,6 +90,33 @@ func (a *Asset) GetMultiplier() float64 {
 	return a.multiplier
 }
 
+// SetCalendar sets the trading calendar for this asset.
+func (a *Asset) SetCalendar(cal calendar.ITradingCalendar) {
+	a.calendar = cal
+}
+
+// GetCalendar returns the asset's trading calendar and true if
+// it has been set. Assets trade on weekdays unless some other
+// calendar is set, so a weekday calendar and false are returned
+// otherwise.
+func (a *Asset) GetCalendar() (calendar.ITradingCalendar, bool) {
+	if a.calendar == nil {
+		return calendar.NewWeekdayCalendar(), false
+	}
+	return a.calendar, true
+}
+
+// SetTradingRules sets the rules on the units in which this asset trades.
+func (a *Asset) SetTradingRules(rules TradingRules) {
+	a.tradingRules = rules
+}
+
+// GetTradingRules returns the asset's trading rules,
+// which allow any units unless some other rules are set.
+func (a *Asset) GetTradingRules() TradingRules {
+	return a.tradingRules
+}
+
 // SetPrice sets the asset's price.
 // The Revalue method is automatically called after setting price.
 func (a *Asset) SetPrice(price Price) {
@@ -1,6 +1,7 @@
 package asset
 
 import (
+	"gobacktrader/calendar"
 	"testing"
 	"time"
 )
@@ -120,3 +121,25 @@ func TestAssetHistory(t *testing.T) {
 		t.Error("snap2 - unexpected price.")
 	}
 }
+
+func TestAssetCalendar(t *testing.T) {
+	asset, err := NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+
+	var hasCalendar IHasCalendar = asset
+	defaultCalendar, ok := hasCalendar.GetCalendar()
+	if _, isWeekday := defaultCalendar.(calendar.WeekdayCalendar); !isWeekday || ok {
+		t.Error("Expecting a weekday calendar that was not set by default")
+	}
+
+	cal, err := calendar.NewCalendar("ASX", time.UTC)
+	if err != nil {
+		t.Fatalf("Error in NewCalendar - %s", err)
+	}
+	asset.SetCalendar(cal)
+	if assetCalendar, ok := asset.GetCalendar(); assetCalendar != cal || !ok {
+		t.Error("Unexpected asset calendar")
+	}
+}
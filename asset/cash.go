@@ -1,44 +1,72 @@
 package asset
 
+import "sync"
+
 // Cash represents a cash asset.
 type Cash struct {
 	priceHistory
 	currency string
 }
 
-type cashObjects struct {
+// CashRegistry holds a single cash object per currency so that all
+// cash in a currency is held as one position. A registry is safe for
+// concurrent use. Backtests run side by side should each use their own
+// registry so that cash price histories are not shared.
+type CashRegistry struct {
+	mu   sync.Mutex
 	pool map[string]*Cash
 }
 
-var cashObjectsSingleton = cashObjects{}
+var defaultCashRegistry = NewCashRegistry()
 
-func (c *cashObjects) getCash(currency string) (*Cash, error) {
-	if c.pool == nil {
-		c.pool = make(map[string]*Cash)
-	}
+// NewCashRegistry returns a new empty cash registry.
+func NewCashRegistry() *CashRegistry {
+	return &CashRegistry{pool: make(map[string]*Cash)}
+}
+
+// DefaultCashRegistry returns the registry used by NewCash and
+// by portfolios without a registry of their own.
+func DefaultCashRegistry() *CashRegistry {
+	return defaultCashRegistry
+}
+
+// GetCash returns the cash object for some currency,
+// creating it on first use.
+func (r *CashRegistry) GetCash(currency string) (*Cash, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-	_, ok := c.pool[currency]
+	_, ok := r.pool[currency]
 	if !ok {
 		cash, err := cashFactory(currency)
 		if err != nil {
 			return &cash, err
 		}
-		c.pool[currency] = &cash
+		r.pool[currency] = &cash
 	}
 
-	return c.pool[currency], nil
+	return r.pool[currency], nil
+}
+
+// Len returns the number of currencies in the registry.
+func (r *CashRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pool)
 }
 
 func cashFactory(currency string) (Cash, error) {
 	currency, err := ValidateCurrency(currency)
 	cash := Cash{currency: currency}
 	cash.price = unitPrice
+	cash.series = NewPriceSeries()
 	return cash, err
 }
 
-// NewCash returns a new cash asset.
+// NewCash returns the cash asset for some currency
+// from the default cash registry.
 func NewCash(currency string) (*Cash, error) {
-	return cashObjectsSingleton.getCash(currency)
+	return defaultCashRegistry.GetCash(currency)
 }
 
 // GetCurrency returns the cash currency.
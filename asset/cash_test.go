@@ -109,3 +109,45 @@ func TestCashInstances(t *testing.T) {
 		t.Error("AUD cash should be distinct from USD")
 	}
 }
+
+func TestCashRegistry(t *testing.T) {
+	registry1, registry2 := NewCashRegistry(), NewCashRegistry()
+	aud1, err1 := registry1.GetCash("AUD")
+	aud2, err2 := registry2.GetCash("AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in GetCash - %s", err)
+	}
+	if aud1 == aud2 {
+		t.Error("Expecting separate registries to hold separate cash")
+	}
+	if again, _ := registry1.GetCash("AUD"); again != aud1 {
+		t.Error("Expecting a registry to return the same cash for a currency")
+	}
+	if defaultCash, _ := NewCash("AUD"); defaultCash == aud1 {
+		t.Error("Expecting NewCash to use the default registry")
+	}
+	if DefaultCashRegistry() != defaultCashRegistry {
+		t.Error("Unexpected default registry")
+	}
+	if _, err := registry1.GetCash("AUDX"); err == nil {
+		t.Error("Expecting error for an invalid currency")
+	}
+
+	// concurrent access to one registry
+	done := make(chan *Cash)
+	for i := 0; i < 8; i++ {
+		go func() {
+			cash, _ := registry2.GetCash("USD")
+			done <- cash
+		}()
+	}
+	first := <-done
+	for i := 1; i < 8; i++ {
+		if cash := <-done; cash != first {
+			t.Error("Expecting concurrent calls to share one cash object")
+		}
+	}
+	if registry2.Len() != 2 {
+		t.Errorf("Unexpected registry size - wanted 2, got %d", registry2.Len())
+	}
+}
@@ -73,6 +73,7 @@ type FxRate struct {
 func NewFxRate(pair string, price Price) (*FxRate, error) {
 	pair, err := ValidatePair(pair)
 	fxrate := FxRate{pair: pair}
+	fxrate.series = NewPriceSeries()
 	fxrate.SetRate(price)
 	return &fxrate, err
 }
@@ -35,6 +35,12 @@ type ITrade interface {
 	PassesCompliance() (bool, error)
 }
 
+// IResizableTrade is implemented by trades that record the units a
+// broker executed where these differ from the units requested.
+type IResizableTrade interface {
+	SetExecutedUnits(units float64)
+}
+
 // IBroker defines the broker interface.
 type IBroker interface {
 	Execute(ITrade) error
@@ -61,6 +67,17 @@ func newPortfolioSnapshot(timestamp time.Time, p *Portfolio) (PortfolioSnapshot,
 	return snap, err
 }
 
+// RestorePortfolioSnapshot returns a portfolio snapshot from previously
+// recorded values, such as when resuming from a checkpoint.
+func RestorePortfolioSnapshot(timestamp time.Time, value Price, weights map[IAssetReadOnly]Weight, holdings map[IAssetReadOnly]float64) PortfolioSnapshot {
+	return PortfolioSnapshot{
+		timestamp: timestamp,
+		value:     value,
+		weights:   weights,
+		holdings:  holdings,
+	}
+}
+
 // GetTime returns the timestamp for our snapshot.
 func (s PortfolioSnapshot) GetTime() time.Time {
 	return s.timestamp
@@ -87,9 +104,59 @@ type Portfolio struct {
 	baseCurrency    string
 	positions       map[IAssetReadOnly]*Position
 	fxRates         *FxRates
-	history         PortfolioHistory
+	history         *PortfolioSeries
 	complianceRules []IComplianceRule
 	broker          IBroker
+	cashRegistry    *CashRegistry
+}
+
+// SetCashRegistry sets the registry the portfolio takes cash from.
+// This should be set before any cash is transferred into the portfolio.
+func (p *Portfolio) SetCashRegistry(registry *CashRegistry) {
+	p.cashRegistry = registry
+}
+
+// GetCashRegistry returns the registry the portfolio takes cash from,
+// which is the default registry unless another has been set.
+func (p *Portfolio) GetCashRegistry() *CashRegistry {
+	if p.cashRegistry == nil {
+		return defaultCashRegistry
+	}
+	return p.cashRegistry
+}
+
+// AdoptCashRegistry sets the registry the portfolio takes cash from and
+// moves any cash positions onto the cash held in that registry.
+func (p *Portfolio) AdoptCashRegistry(registry *CashRegistry) {
+	p.SetCashRegistry(registry)
+	for a, position := range p.positions {
+		cash := p.cashAsset(a)
+		if cash == a {
+			continue
+		}
+		delete(p.positions, a)
+		p.ModifyPositions(cash, position.GetUnits())
+	}
+}
+
+// cashAsset returns the portfolio's own cash in place of cash in the
+// same currency from another registry, so that cash is always held as
+// a single position per currency. Other assets are returned as is.
+func (p *Portfolio) cashAsset(a IAssetReadOnly) IAssetReadOnly {
+	cash, ok := a.(*Cash)
+	if !ok {
+		return a
+	}
+	registryCash, err := p.GetCashRegistry().GetCash(cash.GetCurrency())
+	if err != nil {
+		return a
+	}
+	return registryCash
+}
+
+// GetCash returns the portfolio's cash asset for some currency.
+func (p *Portfolio) GetCash(currency string) (*Cash, error) {
+	return p.GetCashRegistry().GetCash(currency)
 }
 
 // SetBroker sets the portfolio broker.
@@ -134,7 +201,7 @@ func (p Portfolio) Show() string {
 // NewPortfolio returns a new instance of Portfolio.
 func NewPortfolio(code string, baseCurrency string) (*Portfolio, error) {
 	positions := make(map[IAssetReadOnly]*Position)
-	history := make(map[time.Time]PortfolioSnapshot)
+	history := NewPortfolioSeries()
 	baseCurrency, err := ValidateCurrency(baseCurrency)
 	fxRates := &FxRates{}
 	portfolio := Portfolio{
@@ -170,13 +237,13 @@ func (p *Portfolio) NumPositions() int {
 // HasAsset returns a boolean of true if a portfolio
 // contains some asset, false otherwise.
 func (p *Portfolio) HasAsset(a IAssetReadOnly) bool {
-	_, ok := p.positions[a]
+	_, ok := p.positions[p.cashAsset(a)]
 	return ok
 }
 
 // GetUnits returns the units held for a given asset.
 func (p *Portfolio) GetUnits(a IAssetReadOnly) float64 {
-	position, ok := p.positions[a]
+	position, ok := p.positions[p.cashAsset(a)]
 	if !ok {
 		return 0.0
 	}
@@ -199,7 +266,7 @@ func (p *Portfolio) GetWeight(a IAssetReadOnly) (Weight, error) {
 		return nullWeight, err
 	}
 
-	weight, ok := positionWeights[a]
+	weight, ok := positionWeights[p.cashAsset(a)]
 	if !ok {
 		return nullWeight, nil
 	}
@@ -208,8 +275,10 @@ func (p *Portfolio) GetWeight(a IAssetReadOnly) (Weight, error) {
 }
 
 // ModifyPositions allows us to increment and decrement positions
-// in the portfolio.
+// in the portfolio. Cash is held as the portfolio registry's cash
+// in the same currency.
 func (p *Portfolio) ModifyPositions(a IAssetReadOnly, units float64) {
+	a = p.cashAsset(a)
 	// if the asset is already held then modify its position
 	if p.HasAsset(a) {
 		p.positions[a].Increment(units)
@@ -218,6 +287,14 @@ func (p *Portfolio) ModifyPositions(a IAssetReadOnly, units float64) {
 	}
 }
 
+// SetPositions replaces all portfolio positions with the given units.
+func (p *Portfolio) SetPositions(units map[IAssetReadOnly]float64) {
+	p.positions = make(map[IAssetReadOnly]*Position)
+	for a, assetUnits := range units {
+		p.positions[a] = &Position{a, assetUnits}
+	}
+}
+
 // Transfer has identical functionality to ModifyPositions
 // and will increment or decrement some asset in the portfolio.
 func (p *Portfolio) Transfer(a IAssetReadOnly, units float64) {
@@ -322,12 +399,22 @@ func (p *Portfolio) TakeSnapshot(timestamp time.Time) error {
 		return err
 	}
 
-	p.history[timestamp] = snap
+	p.history.Add(snap)
 	return nil
 }
 
-// GetHistory returns the portfolio snapshot history.
+// GetHistory returns a copy of the portfolio history keyed by time.
+// The copy is built on every call, so changes to it do not affect the
+// portfolio. Use GetPortfolioSeries for lookups, ordered or range queries.
 func (p Portfolio) GetHistory() PortfolioHistory {
+	if p.history == nil {
+		return PortfolioHistory{}
+	}
+	return p.history.ToHistory()
+}
+
+// GetPortfolioSeries returns the portfolio snapshot history ordered by time.
+func (p Portfolio) GetPortfolioSeries() *PortfolioSeries {
 	return p.history
 }
 
@@ -338,6 +425,9 @@ func (p *Portfolio) Copy() (*Portfolio, error) {
 		return portfolioCopy, err
 	}
 
+	// cash must come from the same registry
+	portfolioCopy.SetCashRegistry(p.cashRegistry)
+
 	// copy over positions
 	for asset, position := range p.positions {
 		portfolioCopy.Transfer(asset, position.GetUnits())
@@ -425,7 +515,7 @@ func (p *Portfolio) Trade(asset IAssetReadOnly, units float64, consideration *fl
 	}
 
 	currencyCode := asset.GetBaseCurrency()
-	cash, err := NewCash(currencyCode)
+	cash, err := p.GetCash(currencyCode)
 	if err != nil {
 		return err
 	}
@@ -966,3 +966,63 @@ func TestGetAllUnits(t *testing.T) {
 		t.Errorf("Unexpected units in stock1 - wanted 200, got %0.2f", units)
 	}
 }
+
+func TestPortfolioCashRegistry(t *testing.T) {
+	portfolio, err1 := NewPortfolio("XXX", "AUD")
+	stock, err2 := NewStock("ZZB AU", "AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	if portfolio.GetCashRegistry() != DefaultCashRegistry() {
+		t.Error("Expecting the default cash registry")
+	}
+
+	registry := NewCashRegistry()
+	portfolio.SetCashRegistry(registry)
+	cash, err := portfolio.GetCash("AUD")
+	if err != nil {
+		t.Fatalf("Error in GetCash - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+	stock.SetPrice(Price{Float64: 2.0, Valid: true})
+	if err := portfolio.Trade(stock, 100, nil); err != nil {
+		t.Fatalf("Error in Trade - %s", err)
+	}
+	if units := portfolio.GetUnits(cash); units != 800 {
+		t.Errorf("Unexpected registry cash - wanted 800, got %0.2f", units)
+	}
+
+	portfolioCopy, err := portfolio.Copy()
+	if err != nil {
+		t.Fatalf("Error in Copy - %s", err)
+	}
+	if portfolioCopy.GetCashRegistry() != registry || portfolioCopy.GetUnits(cash) != 800 {
+		t.Error("Expecting the copy to share the cash registry")
+	}
+}
+
+func TestPortfolioAdoptCashRegistry(t *testing.T) {
+	portfolio, err1 := NewPortfolio("XXX", "AUD")
+	aud, err2 := NewCash("AUD")
+	usd, err3 := NewCash("USD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(aud, 1000)
+	portfolio.Transfer(usd, 50)
+
+	registry := NewCashRegistry()
+	portfolio.AdoptCashRegistry(registry)
+	registryAud, _ := registry.GetCash("AUD")
+	registryUsd, _ := registry.GetCash("USD")
+	units := portfolio.GetAllUnits()
+	if len(units) != 2 || units[registryAud] != 1000 || units[registryUsd] != 50 {
+		t.Errorf("Unexpected units after adopting a registry - %v", units)
+	}
+
+	// cash from another registry is held as the registry cash
+	portfolio.Transfer(aud, 100)
+	if portfolio.NumPositions() != 2 || portfolio.GetUnits(aud) != 1100 || portfolio.GetUnits(registryAud) != 1100 {
+		t.Error("Expecting one cash position per currency")
+	}
+}
@@ -35,6 +35,15 @@ func NewPriceSnapshot(timestamp time.Time, a iHasGetPrice) PriceSnapshot {
 	}
 }
 
+// RestorePriceSnapshot returns a price snapshot from a previously
+// recorded price, such as when resuming from a checkpoint.
+func RestorePriceSnapshot(timestamp time.Time, price Price) PriceSnapshot {
+	return PriceSnapshot{
+		timestamp: timestamp,
+		price:     price,
+	}
+}
+
 // GetTime returns the timestamp for this snapshot.
 func (s PriceSnapshot) GetTime() time.Time {
 	return s.timestamp
@@ -46,8 +55,8 @@ func (s PriceSnapshot) GetPrice() Price {
 }
 
 type priceHistory struct {
-	price   Price
-	history map[time.Time]PriceSnapshot
+	price  Price
+	series *PriceSeries
 }
 
 type iPriceHistory interface {
@@ -63,14 +72,19 @@ func (h *priceHistory) GetPrice() Price {
 
 // TakeSnapshot records a snapshot at a point in time for future reference.
 func (h *priceHistory) TakeSnapshot(timestamp time.Time, asset iHasGetPrice) {
-	snap := NewPriceSnapshot(timestamp, asset)
-	if h.history == nil {
-		h.history = make(History)
-	}
-	h.history[timestamp] = snap
+	h.series.Add(NewPriceSnapshot(timestamp, asset))
 }
 
-// GetHistory returns the record of asset history.
+// GetHistory returns a copy of the asset history keyed by time.
+// The copy is built on every call, so changes to it do not affect
+// the asset. Use GetPriceSeries for lookups, ordered or range queries.
 func (h *priceHistory) GetHistory() History {
-	return h.history
+	return h.GetPriceSeries().ToHistory()
+}
+
+// GetPriceSeries returns the record of asset history ordered by time.
+// The series is created by the asset constructors so that it can be
+// shared safely between goroutines.
+func (h *priceHistory) GetPriceSeries() *PriceSeries {
+	return h.series
 }
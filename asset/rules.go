@@ -0,0 +1,138 @@
+package asset
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// unitTolerance allows for floating point error when checking
+// units are whole numbers or whole numbers of lots.
+const unitTolerance = 1e-9
+
+// TradingRules describe the units in which an asset can be traded.
+// The zero value allows any units.
+type TradingRules struct {
+	lotSize     float64
+	minUnits    float64
+	minNotional float64
+	wholeUnits  bool
+}
+
+// IHasTradingRules defines the interface for assets
+// with rules on the units that can be traded.
+type IHasTradingRules interface {
+	GetTradingRules() TradingRules
+}
+
+// UnitRuleError is returned for trades in units that break
+// the trading rules of an asset.
+type UnitRuleError struct {
+	Ticker string
+	Units  float64
+	Reason string
+}
+
+// Error returns the error message.
+func (e *UnitRuleError) Error() string {
+	return fmt.Sprintf("'%s' cannot trade %g units as %s", e.Ticker, e.Units, e.Reason)
+}
+
+// NewTradingRules returns new trading rules, where units must be a
+// multiple of the lot size, at least the minimum units and at least the
+// minimum notional in local currency. A lot size, minimum units or
+// minimum notional of zero has no effect. Where fractional is false
+// units must be whole numbers.
+func NewTradingRules(lotSize float64, minUnits float64, minNotional float64, fractional bool) (TradingRules, error) {
+	for _, value := range []float64{lotSize, minUnits, minNotional} {
+		if value < 0 || math.IsNaN(value) || math.IsInf(value, 0) {
+			return TradingRules{}, errors.New("lot size, minimum units and minimum notional must not be negative")
+		}
+	}
+	if !fractional && !isWhole(lotSize) {
+		return TradingRules{}, fmt.Errorf("lot size %g must be whole where fractional units are not allowed", lotSize)
+	}
+	return TradingRules{
+		lotSize:     lotSize,
+		minUnits:    minUnits,
+		minNotional: minNotional,
+		wholeUnits:  !fractional,
+	}, nil
+}
+
+// GetLotSize returns the lot size, which is zero where there is none.
+func (r TradingRules) GetLotSize() float64 {
+	return r.lotSize
+}
+
+// GetMinUnits returns the minimum units that can be traded.
+func (r TradingRules) GetMinUnits() float64 {
+	return r.minUnits
+}
+
+// GetMinNotional returns the minimum value in local currency that can be traded.
+func (r TradingRules) GetMinNotional() float64 {
+	return r.minNotional
+}
+
+// AllowsFractional returns true if fractional units can be traded.
+func (r TradingRules) AllowsFractional() bool {
+	return !r.wholeUnits
+}
+
+// Round rounds units toward zero to a whole number of lots,
+// or to whole units where fractional units are not allowed.
+// Minimum units and notional are not applied.
+func (r TradingRules) Round(units float64) float64 {
+	step := r.lotSize
+	if step == 0 && r.wholeUnits {
+		step = 1
+	}
+	if step == 0 {
+		return units
+	}
+	return math.Trunc(units/step+math.Copysign(unitTolerance, units)) * step
+}
+
+// Check returns a UnitRuleError if trading some units of an asset
+// breaks these rules. Trading zero units breaks no rules.
+func (r TradingRules) Check(a IAssetReadOnly, units float64) error {
+	size := math.Abs(units)
+	reason := ""
+	switch {
+	case size == 0:
+		return nil
+	case r.lotSize > 0 && !isWhole(size/r.lotSize):
+		reason = fmt.Sprintf("they are not a multiple of the lot size %g", r.lotSize)
+	case r.wholeUnits && !isWhole(size):
+		reason = "fractional units are not allowed"
+	case size < r.minUnits-unitTolerance:
+		reason = fmt.Sprintf("they are below the minimum of %g units", r.minUnits)
+	case r.minNotional > 0:
+		value := a.GetValue()
+		if !value.Valid {
+			reason = "there is no value to check the minimum notional"
+		} else if size*value.Float64 < r.minNotional-unitTolerance {
+			reason = fmt.Sprintf("the notional %g is below the minimum of %g", size*value.Float64, r.minNotional)
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+	return &UnitRuleError{Ticker: a.GetTicker(), Units: units, Reason: reason}
+}
+
+// GetTradingRules returns the trading rules for some asset,
+// which allow any units for assets without rules.
+func GetTradingRules(a IAssetReadOnly) TradingRules {
+	if hasRules, ok := a.(IHasTradingRules); ok {
+		return hasRules.GetTradingRules()
+	}
+	return TradingRules{}
+}
+
+// isWhole returns true if a value is a whole number
+// allowing for floating point error.
+func isWhole(value float64) bool {
+	return math.Abs(value-math.Round(value)) < unitTolerance
+}
@@ -0,0 +1,116 @@
+package asset
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNewTradingRules(t *testing.T) {
+	rules, err := NewTradingRules(100, 200, 1000, false)
+	if err != nil {
+		t.Fatalf("Error in NewTradingRules - %s", err)
+	}
+	if rules.GetLotSize() != 100 || rules.GetMinUnits() != 200 || rules.GetMinNotional() != 1000 || rules.AllowsFractional() {
+		t.Error("Unexpected trading rules")
+	}
+	if !(TradingRules{}).AllowsFractional() {
+		t.Error("Expecting the zero rules to allow fractional units")
+	}
+
+	if _, err := NewTradingRules(-1, 0, 0, true); err == nil {
+		t.Error("Expecting an error for a negative lot size")
+	}
+	if _, err := NewTradingRules(0, 0, math.NaN(), true); err == nil {
+		t.Error("Expecting an error for an invalid minimum notional")
+	}
+	_, err = NewTradingRules(0.5, 0, 0, false)
+	if err == nil || err.Error() != "lot size 0.5 must be whole where fractional units are not allowed" {
+		t.Errorf("Unexpected error in NewTradingRules - %v", err)
+	}
+}
+
+func TestTradingRulesRound(t *testing.T) {
+	lots, _ := NewTradingRules(100, 0, 0, false)
+	whole, _ := NewTradingRules(0, 0, 0, false)
+	fractionalLots, _ := NewTradingRules(0.01, 0, 0, true)
+
+	tests := []struct {
+		rules    TradingRules
+		units    float64
+		expected float64
+	}{
+		{lots, 13.7, 0},
+		{lots, 250, 200},
+		{lots, -250, -200},
+		{lots, 299.9999999999, 300},
+		{whole, 13.7, 13},
+		{whole, -13.7, -13},
+		{fractionalLots, 1.2345, 1.23},
+		{TradingRules{}, 13.7, 13.7},
+	}
+	for _, test := range tests {
+		if rounded := test.rules.Round(test.units); math.Abs(rounded-test.expected) > 1e-9 {
+			t.Errorf("Unexpected rounding of %g - wanted %g, got %g", test.units, test.expected, rounded)
+		}
+	}
+}
+
+func TestTradingRulesCheck(t *testing.T) {
+	stock, err := NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+	if _, ok := interface{}(stock).(IHasTradingRules); !ok {
+		t.Error("Expecting assets to have trading rules")
+	}
+	rules, err := NewTradingRules(10, 20, 500, false)
+	if err != nil {
+		t.Fatalf("Error in NewTradingRules - %s", err)
+	}
+
+	stock.SetTradingRules(rules)
+	if GetTradingRules(stock) != rules {
+		t.Error("Unexpected asset trading rules")
+	}
+	cash, err := NewCash("AUD")
+	if err != nil {
+		t.Fatalf("Error in NewCash - %s", err)
+	}
+	if GetTradingRules(cash) != (TradingRules{}) {
+		t.Error("Expecting cash to allow any units")
+	}
+
+	// no value to check the notional
+	err = rules.Check(stock, 100)
+	if err == nil || err.Error() != "'ZZB AU' cannot trade 100 units as there is no value to check the minimum notional" {
+		t.Errorf("Unexpected error in Check - %v", err)
+	}
+
+	stock.SetPrice(Price{Float64: 10, Valid: true})
+	tests := []struct {
+		units  float64
+		errStr string
+	}{
+		{0, ""},
+		{100, ""},
+		{-100, ""},
+		{15, "'ZZB AU' cannot trade 15 units as they are not a multiple of the lot size 10"},
+		{10, "'ZZB AU' cannot trade 10 units as they are below the minimum of 20 units"},
+		{-40, "'ZZB AU' cannot trade -40 units as the notional 400 is below the minimum of 500"},
+	}
+	for _, test := range tests {
+		err := rules.Check(stock, test.units)
+		if test.errStr == "" && err != nil {
+			t.Errorf("Unexpected error in Check - %s", err)
+		}
+		if test.errStr != "" && (err == nil || err.Error() != test.errStr) {
+			t.Errorf("Unexpected error in Check - %v", err)
+		}
+	}
+
+	whole, _ := NewTradingRules(0, 0, 0, false)
+	err = whole.Check(stock, 1.5)
+	if unitErr, ok := err.(*UnitRuleError); !ok || unitErr.Units != 1.5 || unitErr.Reason != "fractional units are not allowed" {
+		t.Errorf("Unexpected error in Check - %v", err)
+	}
+}
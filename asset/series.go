@@ -0,0 +1,343 @@
+package asset
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// timed defines the interface for snapshots held in a time index.
+type timed interface {
+	GetTime() time.Time
+}
+
+// timeIndex holds snapshots ordered by time and is shared by
+// the typed price and portfolio series. It is safe for
+// concurrent use.
+type timeIndex struct {
+	mu    sync.RWMutex
+	items []timed
+}
+
+// search returns the index of the first item that is not
+// before t, or the number of items if all are before t.
+// The caller must hold the lock.
+func (x *timeIndex) search(t time.Time) int {
+	return sort.Search(len(x.items), func(i int) bool { return !x.items[i].GetTime().Before(t) })
+}
+
+// add adds an item in time order, replacing
+// any existing item at the same time.
+func (x *timeIndex) add(item timed) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	n := len(x.items)
+	if n == 0 || x.items[n-1].GetTime().Before(item.GetTime()) {
+		x.items = append(x.items, item) // the usual case
+		return
+	}
+	i := x.search(item.GetTime())
+	if i < n && x.items[i].GetTime().Equal(item.GetTime()) {
+		x.items[i] = item
+		return
+	}
+	x.items = append(x.items, nil)
+	copy(x.items[i+1:], x.items[i:])
+	x.items[i] = item
+}
+
+func (x *timeIndex) len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.items)
+}
+
+func (x *timeIndex) at(i int) timed {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.items[i]
+}
+
+// get returns the item at exactly some time.
+func (x *timeIndex) get(t time.Time) (timed, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	i := x.search(t)
+	if i < len(x.items) && x.items[i].GetTime().Equal(t) {
+		return x.items[i], true
+	}
+	return nil, false
+}
+
+// asOf returns the latest item at or before some time.
+func (x *timeIndex) asOf(t time.Time) (timed, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	i := x.search(t.Add(time.Nanosecond))
+	if i == 0 {
+		return nil, false
+	}
+	return x.items[i-1], true
+}
+
+// all returns a copy of all items in time order.
+func (x *timeIndex) all() []timed {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return append([]timed(nil), x.items...)
+}
+
+// between returns the items between start and end inclusive.
+func (x *timeIndex) between(start time.Time, end time.Time) []timed {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	from := x.search(start)
+	to := x.search(end.Add(time.Nanosecond))
+	if from >= to {
+		return nil
+	}
+	return append([]timed(nil), x.items[from:to]...)
+}
+
+// last returns up to the last n items in time order.
+func (x *timeIndex) last(n int) []timed {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	from := 0
+	if n < 0 {
+		from = len(x.items)
+	} else if n < len(x.items) {
+		from = len(x.items) - n
+	}
+	return append([]timed(nil), x.items[from:]...)
+}
+
+// times returns the item times in order.
+func (x *timeIndex) times() []time.Time {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	times := make([]time.Time, len(x.items))
+	for i, item := range x.items {
+		times[i] = item.GetTime()
+	}
+	return times
+}
+
+// PriceSeries holds price snapshots ordered by time.
+// It is safe for concurrent use.
+type PriceSeries struct {
+	index timeIndex
+}
+
+// NewPriceSeries returns a new empty price series.
+func NewPriceSeries() *PriceSeries {
+	return &PriceSeries{}
+}
+
+// priceSnapshots returns the price snapshots held in some items.
+func priceSnapshots(items []timed) []PriceSnapshot {
+	if len(items) == 0 {
+		return nil
+	}
+	snapshots := make([]PriceSnapshot, len(items))
+	for i, item := range items {
+		snapshots[i] = item.(PriceSnapshot)
+	}
+	return snapshots
+}
+
+// Add adds a snapshot in time order, replacing
+// any existing snapshot at the same time.
+func (s *PriceSeries) Add(snap PriceSnapshot) {
+	s.index.add(snap)
+}
+
+// Len returns the number of snapshots.
+func (s *PriceSeries) Len() int {
+	return s.index.len()
+}
+
+// At returns the snapshot at some index, where zero is the earliest.
+func (s *PriceSeries) At(i int) PriceSnapshot {
+	return s.index.at(i).(PriceSnapshot)
+}
+
+// Get returns the snapshot at exactly some time,
+// along with false if there is no such snapshot.
+func (s *PriceSeries) Get(t time.Time) (PriceSnapshot, bool) {
+	item, ok := s.index.get(t)
+	if !ok {
+		return PriceSnapshot{}, false
+	}
+	return item.(PriceSnapshot), true
+}
+
+// AsOf returns the latest snapshot at or before some time,
+// along with false if there is no such snapshot.
+func (s *PriceSeries) AsOf(t time.Time) (PriceSnapshot, bool) {
+	item, ok := s.index.asOf(t)
+	if !ok {
+		return PriceSnapshot{}, false
+	}
+	return item.(PriceSnapshot), true
+}
+
+// Snapshots returns a copy of all snapshots in time order.
+func (s *PriceSeries) Snapshots() []PriceSnapshot {
+	return priceSnapshots(s.index.all())
+}
+
+// Range returns the snapshots between start and end inclusive.
+func (s *PriceSeries) Range(start time.Time, end time.Time) []PriceSnapshot {
+	return priceSnapshots(s.index.between(start, end))
+}
+
+// Last returns up to the last n snapshots in time order.
+func (s *PriceSeries) Last(n int) []PriceSnapshot {
+	return priceSnapshots(s.index.last(n))
+}
+
+// Times returns the snapshot times in order.
+func (s *PriceSeries) Times() []time.Time {
+	return s.index.times()
+}
+
+// Floats returns the snapshot prices in time order,
+// with invalid prices returned as NaN.
+func (s *PriceSeries) Floats() []float64 {
+	return PriceFloats(s.Snapshots())
+}
+
+// ToHistory returns a new History holding the snapshots keyed by time.
+func (s *PriceSeries) ToHistory() History {
+	snapshots := s.Snapshots()
+	history := make(History, len(snapshots))
+	for _, snap := range snapshots {
+		history[snap.GetTime()] = snap
+	}
+	return history
+}
+
+// PriceFloats returns the prices of some snapshots,
+// with invalid prices returned as NaN.
+func PriceFloats(snapshots []PriceSnapshot) []float64 {
+	floats := make([]float64, len(snapshots))
+	for i, snap := range snapshots {
+		floats[i] = priceToFloat(snap.GetPrice())
+	}
+	return floats
+}
+
+// priceToFloat returns a price as a float, or NaN if invalid.
+func priceToFloat(price Price) float64 {
+	if !price.Valid {
+		return math.NaN()
+	}
+	return price.Float64
+}
+
+// PortfolioSeries holds portfolio snapshots ordered by time.
+// It is safe for concurrent use.
+type PortfolioSeries struct {
+	index timeIndex
+}
+
+// NewPortfolioSeries returns a new empty portfolio series.
+func NewPortfolioSeries() *PortfolioSeries {
+	return &PortfolioSeries{}
+}
+
+// portfolioSnapshots returns the portfolio snapshots held in some items.
+func portfolioSnapshots(items []timed) []PortfolioSnapshot {
+	if len(items) == 0 {
+		return nil
+	}
+	snapshots := make([]PortfolioSnapshot, len(items))
+	for i, item := range items {
+		snapshots[i] = item.(PortfolioSnapshot)
+	}
+	return snapshots
+}
+
+// Add adds a snapshot in time order, replacing
+// any existing snapshot at the same time.
+func (s *PortfolioSeries) Add(snap PortfolioSnapshot) {
+	s.index.add(snap)
+}
+
+// Len returns the number of snapshots.
+func (s *PortfolioSeries) Len() int {
+	return s.index.len()
+}
+
+// At returns the snapshot at some index, where zero is the earliest.
+func (s *PortfolioSeries) At(i int) PortfolioSnapshot {
+	return s.index.at(i).(PortfolioSnapshot)
+}
+
+// Get returns the snapshot at exactly some time,
+// along with false if there is no such snapshot.
+func (s *PortfolioSeries) Get(t time.Time) (PortfolioSnapshot, bool) {
+	item, ok := s.index.get(t)
+	if !ok {
+		return PortfolioSnapshot{}, false
+	}
+	return item.(PortfolioSnapshot), true
+}
+
+// AsOf returns the latest snapshot at or before some time,
+// along with false if there is no such snapshot.
+func (s *PortfolioSeries) AsOf(t time.Time) (PortfolioSnapshot, bool) {
+	item, ok := s.index.asOf(t)
+	if !ok {
+		return PortfolioSnapshot{}, false
+	}
+	return item.(PortfolioSnapshot), true
+}
+
+// Snapshots returns a copy of all snapshots in time order.
+func (s *PortfolioSeries) Snapshots() []PortfolioSnapshot {
+	return portfolioSnapshots(s.index.all())
+}
+
+// Range returns the snapshots between start and end inclusive.
+func (s *PortfolioSeries) Range(start time.Time, end time.Time) []PortfolioSnapshot {
+	return portfolioSnapshots(s.index.between(start, end))
+}
+
+// Last returns up to the last n snapshots in time order.
+func (s *PortfolioSeries) Last(n int) []PortfolioSnapshot {
+	return portfolioSnapshots(s.index.last(n))
+}
+
+// Times returns the snapshot times in order.
+func (s *PortfolioSeries) Times() []time.Time {
+	return s.index.times()
+}
+
+// Floats returns the portfolio values in time order,
+// with invalid values returned as NaN.
+func (s *PortfolioSeries) Floats() []float64 {
+	return PortfolioFloats(s.Snapshots())
+}
+
+// ToHistory returns a new PortfolioHistory holding the snapshots keyed by time.
+func (s *PortfolioSeries) ToHistory() PortfolioHistory {
+	snapshots := s.Snapshots()
+	history := make(PortfolioHistory, len(snapshots))
+	for _, snap := range snapshots {
+		history[snap.GetTime()] = snap
+	}
+	return history
+}
+
+// PortfolioFloats returns the values of some portfolio snapshots,
+// with invalid values returned as NaN.
+func PortfolioFloats(snapshots []PortfolioSnapshot) []float64 {
+	floats := make([]float64, len(snapshots))
+	for i, snap := range snapshots {
+		floats[i] = priceToFloat(snap.GetValue())
+	}
+	return floats
+}
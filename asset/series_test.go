@@ -0,0 +1,116 @@
+package asset
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPriceSeries(t *testing.T) {
+	stock, err := NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+
+	// snapshots taken out of order should be stored in time order
+	for _, day := range []int{3, 1, 4, 2} {
+		stock.SetPrice(Price{Float64: float64(day), Valid: true})
+		stock.TakeSnapshot(btutil.Date(2021, 3, day), stock)
+	}
+	stock.SetPrice(Price{})
+	stock.TakeSnapshot(btutil.Date(2021, 3, 5), stock)
+
+	series := stock.GetPriceSeries()
+	if series.Len() != 5 {
+		t.Fatalf("Unexpected series length - wanted 5, got %d", series.Len())
+	}
+	floats := series.Floats()
+	for i := 0; i < 4; i++ {
+		if floats[i] != float64(i+1) {
+			t.Errorf("Unexpected price at index %d - wanted %d, got %0.2f", i, i+1, floats[i])
+		}
+	}
+	if !math.IsNaN(floats[4]) {
+		t.Error("Expecting NaN for an invalid price")
+	}
+
+	// replacing a snapshot at an existing time
+	stock.SetPrice(Price{Float64: 20, Valid: true})
+	stock.TakeSnapshot(btutil.Date(2021, 3, 2), stock)
+	if snap, ok := series.Get(btutil.Date(2021, 3, 2)); !ok || snap.GetPrice().Float64 != 20 || series.Len() != 5 {
+		t.Error("Expecting the snapshot to be replaced")
+	}
+
+	snaps := series.Range(btutil.Date(2021, 3, 2), btutil.Date(2021, 3, 4))
+	if len(snaps) != 3 || !snaps[0].GetTime().Equal(btutil.Date(2021, 3, 2)) {
+		t.Errorf("Unexpected range - got %d snapshots", len(snaps))
+	}
+	if snaps := series.Range(btutil.Date(2021, 4, 1), btutil.Date(2021, 4, 2)); len(snaps) != 0 {
+		t.Error("Expecting an empty range")
+	}
+
+	last := series.Last(2)
+	if len(last) != 2 || !last[1].GetTime().Equal(btutil.Date(2021, 3, 5)) {
+		t.Error("Unexpected last snapshots")
+	}
+	if len(series.Last(10)) != 5 {
+		t.Error("Expecting all snapshots where n exceeds the length")
+	}
+
+	snap, ok := series.AsOf(btutil.Date(2021, 3, 3).Add(12 * time.Hour))
+	if !ok || !snap.GetTime().Equal(btutil.Date(2021, 3, 3)) {
+		t.Error("Unexpected as-of snapshot")
+	}
+	if _, ok := series.AsOf(btutil.Date(2021, 2, 28)); ok {
+		t.Error("Not expecting a snapshot before the series starts")
+	}
+
+	// the history map remains compatible
+	history := stock.GetHistory()
+	if len(history) != 5 || history[btutil.Date(2021, 3, 4)].GetPrice().Float64 != 4 {
+		t.Error("Unexpected history map")
+	}
+	delete(history, btutil.Date(2021, 3, 4))
+	if series.Len() != 5 {
+		t.Error("Expecting the history map to be a copy of the series")
+	}
+	if times := series.Times(); len(times) != 5 || !times[0].Equal(btutil.Date(2021, 3, 1)) {
+		t.Error("Unexpected series times")
+	}
+}
+
+func TestPortfolioSeries(t *testing.T) {
+	portfolio, err1 := NewPortfolio("XXX", "AUD")
+	cash, err2 := NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	for day := 1; day <= 3; day++ {
+		portfolio.Transfer(cash, 100)
+		if err := portfolio.TakeSnapshot(btutil.Date(2021, 3, day)); err != nil {
+			t.Fatalf("Error in TakeSnapshot - %s", err)
+		}
+	}
+
+	series := portfolio.GetPortfolioSeries()
+	floats := series.Floats()
+	if len(floats) != 3 || floats[0] != 100 || floats[2] != 300 {
+		t.Errorf("Unexpected portfolio values - %v", floats)
+	}
+	snap, ok := series.AsOf(btutil.Date(2021, 3, 10))
+	if !ok || snap.GetValue().Float64 != 300 {
+		t.Error("Unexpected as-of snapshot")
+	}
+	if snaps := series.Range(btutil.Date(2021, 3, 2), btutil.Date(2021, 3, 2)); len(snaps) != 1 {
+		t.Errorf("Unexpected range - got %d snapshots", len(snaps))
+	}
+	history := portfolio.GetHistory()
+	if len(history) != 3 {
+		t.Error("Unexpected history map")
+	}
+	delete(history, btutil.Date(2021, 3, 3))
+	if series.Len() != 3 || len(portfolio.GetHistory()) != 3 {
+		t.Error("Expecting the history map to be a copy of the series")
+	}
+}
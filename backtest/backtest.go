@@ -1,12 +1,17 @@
 package backtest
 
 import (
+	"context"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"gobacktrader/asset"
 	"gobacktrader/broker"
 	"gobacktrader/btutil"
 	"gobacktrader/events"
+	"gobacktrader/resample"
+	"gobacktrader/schedule"
+	"gobacktrader/trade"
 	"os"
 	"strings"
 	"time"
@@ -17,14 +22,39 @@ import (
 type Backtest struct {
 	portfolios    []*asset.Portfolio
 	assets        []asset.IAssetReadOnly
-	events        events.Events
-	strategy      IStrategy
+	events        *events.Events
+	sources       *events.MergedSource
+	strategies    []*strategyBinding
+	tradeRecords  []TradeRecord
 	snapshotTimes []time.Time
+	snapshotFreq  resample.Frequency
+	warmUpSteps   int
+	listeners     []interface{}
+	cashRegistry  *asset.CashRegistry
+	seed          int64
+	lastRun       *runInfo
+
+	checkpointPath  string
+	checkpointEvery int
+	progress        ProgressFunc
+	progressEvery   int
 }
 
-// NewBacktest returns a new Backtest instance.
+// NewBacktest returns a new Backtest instance. Where the strategy
+// is not nil it is added with the name DefaultStrategyName and may
+// trade any portfolio. Further strategies can be added with AddStrategy.
 func NewBacktest(strategy IStrategy) Backtest {
-	return Backtest{strategy: strategy}
+	return NewContextBacktest(AdaptStrategy(strategy))
+}
+
+// NewContextBacktest returns a new Backtest instance for a
+// strategy that receives a Context at each time step.
+func NewContextBacktest(strategy IContextStrategy) Backtest {
+	backtest := Backtest{cashRegistry: asset.NewCashRegistry(), events: events.NewEvents()}
+	if strategy != nil {
+		backtest.strategies = []*strategyBinding{{name: DefaultStrategyName, strategy: strategy}}
+	}
+	return backtest
 }
 
 // codeRegistered checks if a code is registered either
@@ -49,12 +79,20 @@ func (backtest Backtest) codeRegistered(code string) bool {
 	return false
 }
 
-// RegisterPortfolio registers a portfolio within our backtest.
+// RegisterPortfolio registers a portfolio within our backtest. A portfolio
+// taking cash from the default registry is moved onto the backtest's cash
+// registry, along with any cash it holds, so that backtests can run side
+// by side. Portfolios using some other registry are rejected.
 func (backtest *Backtest) RegisterPortfolio(p *asset.Portfolio) error {
 	if backtest.HasPortfolio(p) {
 		return nil // portfolio is already registered
 	}
 
+	registry := p.GetCashRegistry()
+	if registry != backtest.GetCashRegistry() && registry != asset.DefaultCashRegistry() {
+		return fmt.Errorf("portfolio '%s' takes cash from a registry other than the backtest's", p.GetCode())
+	}
+
 	// set the default executing broker if none is provided
 	if p.GetBroker() == nil {
 		executingBroker := broker.NewBroker(
@@ -69,10 +107,50 @@ func (backtest *Backtest) RegisterPortfolio(p *asset.Portfolio) error {
 		return fmt.Errorf("portfolio code '%s' is already in use and needs to be unique", portfolioCode)
 	}
 
+	p.AdoptCashRegistry(backtest.GetCashRegistry())
 	backtest.portfolios = append(backtest.portfolios, p)
 	return nil
 }
 
+// GetCashRegistry returns the cash registry owned by the backtest.
+func (backtest *Backtest) GetCashRegistry() *asset.CashRegistry {
+	if backtest.cashRegistry == nil {
+		backtest.cashRegistry = asset.NewCashRegistry()
+	}
+	return backtest.cashRegistry
+}
+
+// GetCash returns the cash asset for some currency from
+// the backtest's cash registry.
+func (backtest *Backtest) GetCash(currency string) (*asset.Cash, error) {
+	return backtest.GetCashRegistry().GetCash(currency)
+}
+
+// SetSeed records the seed used for any randomness in the
+// backtest, which is reported in the run results.
+func (backtest *Backtest) SetSeed(seed int64) {
+	backtest.seed = seed
+}
+
+// GetSeed returns the seed recorded for the backtest.
+func (backtest *Backtest) GetSeed() int64 {
+	return backtest.seed
+}
+
+// NewPortfolio returns a new portfolio that takes its cash from the
+// backtest's cash registry, registered within our backtest.
+func (backtest *Backtest) NewPortfolio(code string, baseCurrency string) (*asset.Portfolio, error) {
+	p, err := asset.NewPortfolio(code, baseCurrency)
+	if err != nil {
+		return nil, err
+	}
+	p.SetCashRegistry(backtest.GetCashRegistry())
+	if err := backtest.RegisterPortfolio(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 // RegisterAsset registers an asset within our backtest.
 func (backtest *Backtest) RegisterAsset(a asset.IAssetReadOnly) error {
 	if backtest.HasAsset(a) {
@@ -87,6 +165,16 @@ func (backtest *Backtest) RegisterAsset(a asset.IAssetReadOnly) error {
 	return nil
 }
 
+// GetPortfolios returns the registered portfolios.
+func (backtest *Backtest) GetPortfolios() []*asset.Portfolio {
+	return backtest.portfolios
+}
+
+// GetAssets returns the registered assets.
+func (backtest *Backtest) GetAssets() []asset.IAssetReadOnly {
+	return backtest.assets
+}
+
 // HasPortfolio returns true if the backtest has a specific portfolio registered, false otherwise.
 func (backtest *Backtest) HasPortfolio(p *asset.Portfolio) bool {
 	for _, registeredPortfolio := range backtest.portfolios {
@@ -119,52 +207,370 @@ func (backtest *Backtest) AddEvent(event events.IEvent) {
 	backtest.events.Add(event)
 }
 
+// AddSchedule adds callback events for all scheduled entries between
+// the dates of start and end inclusive.
+func (backtest *Backtest) AddSchedule(scheduler *schedule.Scheduler, start time.Time, end time.Time) error {
+	scheduledEvents, err := scheduler.GenerateEvents(start, end)
+	if err != nil {
+		return err
+	}
+	backtest.AddEvents(scheduledEvents)
+	return nil
+}
+
+// AddEventSource adds a source of events that will be read lazily
+// as the backtest runs. Events from all sources are merged in time
+// order with those added through AddEvent.
+func (backtest *Backtest) AddEventSource(source events.IEventSource) {
+	if backtest.sources == nil {
+		backtest.sources = events.NewMergedSource()
+	}
+	backtest.sources.Add(source)
+}
+
+// nextEventTime returns the time of the next event either in the
+// events collection or from our event sources, along with false
+// if there are no events remaining.
+func (backtest *Backtest) nextEventTime() (time.Time, bool, error) {
+	var nextTime time.Time
+	found := false
+	if nextEvent, err := backtest.events.Peek(); err == nil {
+		nextTime, found = nextEvent.GetTime(), true
+	}
+
+	if backtest.sources != nil {
+		sourceEvent, ok, err := backtest.sources.Peek()
+		if err != nil {
+			return nextTime, false, err
+		}
+		if ok && (!found || sourceEvent.GetTime().Before(nextTime)) {
+			nextTime, found = sourceEvent.GetTime(), true
+		}
+	}
+
+	return nextTime, found, nil
+}
+
+// queueSourceEvents moves events up to and including some time
+// from our event sources into the events collection.
+func (backtest *Backtest) queueSourceEvents(upTo time.Time) error {
+	if backtest.sources == nil {
+		return nil
+	}
+
+	for {
+		sourceEvent, ok, err := backtest.sources.Peek()
+		if err != nil {
+			return err
+		}
+		if !ok || sourceEvent.GetTime().After(upTo) {
+			return nil
+		}
+		_, _, err = backtest.sources.Next()
+		backtest.events.Add(sourceEvent)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// SetSnapshotFrequency sets how often snapshots are taken.
+// By default a snapshot is taken at every time step, whereas with
+// some coarser frequency the snapshot is taken at the last time
+// step within each period. Pass nil to restore the default.
+func (backtest *Backtest) SetSnapshotFrequency(freq resample.Frequency) {
+	backtest.snapshotFreq = freq
+}
+
+// GetSnapshotFrequency returns the snapshot frequency,
+// which will be nil if snapshots are taken at every time step.
+func (backtest Backtest) GetSnapshotFrequency() resample.Frequency {
+	return backtest.snapshotFreq
+}
+
+// isSnapshotDue returns true if a snapshot should be taken at the current time
+// given the number of time steps processed since the last snapshot.
+func (backtest *Backtest) isSnapshotDue(currentTime time.Time, count int) bool {
+	if backtest.snapshotFreq == nil {
+		return true
+	}
+
+	nextTime, ok, err := backtest.nextEventTime()
+	if err != nil || !ok { // no events remain, so this is the last step
+		return true
+	}
+
+	return backtest.snapshotFreq.IsNewPeriod(currentTime, nextTime, count)
+}
+
+// SetWarmUpSteps sets the number of time steps at the start of the backtest
+// during which events are processed but trades are not executed.
+// Where the strategy declares its own warm-up the longer period is used.
+func (backtest *Backtest) SetWarmUpSteps(steps int) error {
+	if steps < 0 {
+		return errors.New("the number of warm-up steps cannot be negative")
+	}
+	backtest.warmUpSteps = steps
+	return nil
+}
+
+// GetWarmUpSteps returns the number of warm-up time steps,
+// including the longest warm-up declared by any strategy.
+func (backtest Backtest) GetWarmUpSteps() int {
+	steps := backtest.warmUpSteps
+	for _, binding := range backtest.strategies {
+		if strategy, ok := hooks(binding.strategy).(IWarmUp); ok {
+			if strategySteps := strategy.GetWarmUpSteps(); strategySteps > steps {
+				steps = strategySteps
+			}
+		}
+	}
+	return steps
+}
+
+// pendingTrade is a trade waiting to be executed
+// along with the context of the strategy that generated it.
+type pendingTrade struct {
+	trade *trade.Trade
+	ctx   *Context
+}
+
+// newContexts returns a context for each strategy for some time step.
+func (backtest *Backtest) newContexts(currentTime time.Time, warmingUp bool) []*Context {
+	contexts := make([]*Context, len(backtest.strategies))
+	for i, binding := range backtest.strategies {
+		contexts[i] = backtest.newContext(binding, currentTime)
+		contexts[i].warmingUp = warmingUp
+	}
+	return contexts
+}
+
+// generateTrades collects the trades generated by each strategy in turn,
+// checking each strategy only trades the portfolios it owns.
+func (backtest *Backtest) generateTrades(contexts []*Context) ([]pendingTrade, error) {
+	var pending []pendingTrade
+	var stepTrades []*trade.Trade
+	for i, binding := range backtest.strategies {
+		ctx := contexts[i]
+		ctx.pending = stepTrades[:len(stepTrades):len(stepTrades)]
+		trades, err := binding.strategy.GenerateTradesWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range trades {
+			if !backtest.owns(binding, t.GetPortfolio()) {
+				return nil, fmt.Errorf("strategy '%s' cannot trade portfolio '%s' which it does not own",
+					binding.name, t.GetPortfolio().GetCode())
+			}
+			pending = append(pending, pendingTrade{trade: t, ctx: ctx})
+			stepTrades = append(stepTrades, t)
+		}
+	}
+	return pending, nil
+}
+
+// executeTrades executes pending trades, notifying the strategy
+// that generated each trade of its outcome.
+func (backtest *Backtest) executeTrades(pending []pendingTrade) error {
+	for _, p := range pending {
+		record := TradeRecord{
+			timestamp:    p.ctx.GetTime(),
+			strategyName: p.ctx.GetStrategyName(),
+			trade:        p.trade,
+			price:        p.trade.GetAsset().GetValue(),
+		}
+		if p.ctx.IsWarmingUp() {
+			record.reason = RejectWarmUp
+		} else {
+			portfolio := p.trade.GetPortfolio()
+			before, beforeErr := portfolio.GetValue()
+			executed, err := p.trade.Execute()
+			var unitErr *asset.UnitRuleError
+			if errors.As(err, &unitErr) {
+				record.reason = RejectUnits
+			} else if err != nil {
+				return err
+			} else if !executed {
+				record.reason = RejectCompliance
+			}
+			record.executed = executed
+			after, afterErr := portfolio.GetValue()
+			if executed && beforeErr == nil && afterErr == nil && before.Valid && after.Valid {
+				record.charges = before.Float64 - after.Float64
+			}
+		}
+		backtest.tradeRecords = append(backtest.tradeRecords, record)
+
+		strategy := hooks(p.ctx.binding.strategy)
+		var err error
+		if onFill, ok := strategy.(IOnFill); ok && record.executed {
+			err = onFill.OnFill(p.ctx, p.trade)
+		} else if onReject, ok := strategy.(IOnReject); ok && !record.executed {
+			err = onReject.OnReject(p.ctx, p.trade, record.reason)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Run will execute our backtest.
 func (backtest *Backtest) Run() error {
+	return backtest.RunContext(context.Background())
+}
+
+// RunContext will execute our backtest, stopping between time steps
+// with the context's error if it is cancelled or its deadline passes.
+func (backtest *Backtest) RunContext(ctx context.Context) error {
+	return backtest.run(ctx, nil)
+}
+
+// run executes our backtest, first restoring its state
+// from a checkpoint where one is given.
+func (backtest *Backtest) run(ctx context.Context, cp *checkpoint) error {
+	started := time.Now()
+	eventsProcessed := 0
+	backtest.lastRun = nil
 	backtest.snapshotTimes = []time.Time{}
-	for { // while we have events to process
-		if backtest.events.IsEmpty() {
+	backtest.tradeRecords = nil
+	step, stepsSinceSnapshot := 0, 0
+	warmUpSteps := backtest.GetWarmUpSteps()
+
+	// the start of the backtest is the time of the first event
+	currentTime, _, err := backtest.nextEventTime()
+	if err != nil {
+		return err
+	}
+	contexts := backtest.newContexts(currentTime, warmUpSteps > 0)
+	for i, binding := range backtest.strategies {
+		if strategy, ok := hooks(binding.strategy).(IOnStart); ok {
+			if err := strategy.OnStart(contexts[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cp != nil {
+		if err := backtest.fastForward(cp); err != nil {
+			return err
+		}
+		if err := backtest.restore(cp); err != nil {
+			return err
+		}
+		step, stepsSinceSnapshot = cp.Steps, cp.StepsSinceSnapshot
+		eventsProcessed = cp.Events
+	}
+	startTime := currentTime
+
+	for ; ; step++ { // while we have events to process
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		nextTime, ok, err := backtest.nextEventTime()
+		if err != nil {
+			return err
+		}
+		if !ok {
 			break
 		}
 
 		// process events for the next time step
-		var currentTime time.Time
+		if err := backtest.queueSourceEvents(nextTime); err != nil {
+			return err
+		}
+
 		var eventsToProcess []events.IEvent
-		eventsToProcess, err := backtest.events.FetchNextGroup()
+		eventsToProcess, err = backtest.events.FetchNextGroup()
 		if err != nil {
 			return err
 		}
 
 		currentTime = eventsToProcess[0].GetTime()
+		eventsProcessed += len(eventsToProcess)
+		contexts = backtest.newContexts(currentTime, step < warmUpSteps)
 		for _, event := range eventsToProcess {
 			if err := event.Process(); err != nil {
 				return err
 			}
+			if err := backtest.notifyEvent(event); err != nil {
+				return err
+			}
+			for i, binding := range backtest.strategies {
+				if strategy, ok := hooks(binding.strategy).(IOnEvent); ok {
+					if err := strategy.OnEvent(contexts[i], event); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if err := backtest.notifyStep(currentTime); err != nil {
+			return err
 		}
 
 		// now that events have been processed for this time
-		// we'll check to see if our strategy generates trades
-		trades, err := backtest.strategy.GenerateTrades()
+		// we'll check to see if our strategies generate trades
+		pending, err := backtest.generateTrades(contexts)
 		if err != nil {
 			return err
 		}
-		for _, trade := range trades {
-			if _, err := trade.Execute(); err != nil {
-				return err
-			}
+		if err := backtest.executeTrades(pending); err != nil {
+			return err
 		}
 
 		// once all events have been processed for this step
-		// then take snapshots
-		backtest.snapshotTimes = append(backtest.snapshotTimes, currentTime)
-		for _, asset := range backtest.assets {
-			asset.TakeSnapshot(currentTime, asset)
+		// then take snapshots if one is due
+		stepsSinceSnapshot++
+		if backtest.isSnapshotDue(currentTime, stepsSinceSnapshot) {
+			stepsSinceSnapshot = 0
+			backtest.snapshotTimes = append(backtest.snapshotTimes, currentTime)
+			for _, asset := range backtest.assets {
+				asset.TakeSnapshot(currentTime, asset)
+			}
+			for _, portfolio := range backtest.portfolios {
+				portfolio.TakeSnapshot(currentTime)
+			}
+		}
+
+		if backtest.isCheckpointDue(step + 1) {
+			if err := backtest.writeCheckpoint(currentTime, step+1, stepsSinceSnapshot, eventsProcessed); err != nil {
+				return err
+			}
 		}
-		for _, portfolio := range backtest.portfolios {
-			portfolio.TakeSnapshot(currentTime)
+
+		backtest.reportProgress(Progress{
+			Time:            currentTime,
+			Steps:           step + 1,
+			EventsProcessed: eventsProcessed,
+			EventsRemaining: backtest.events.Len(),
+			Elapsed:         time.Since(started),
+		})
+	}
+
+	for i, binding := range backtest.strategies {
+		if strategy, ok := hooks(binding.strategy).(IOnFinish); ok {
+			if err := strategy.OnFinish(contexts[i]); err != nil {
+				return err
+			}
 		}
 	}
 
+	backtest.lastRun = &runInfo{
+		start:    startTime,
+		end:      currentTime,
+		steps:    step,
+		events:   eventsProcessed,
+		wallTime: time.Since(started),
+	}
+	backtest.reportProgress(Progress{
+		Time:            currentTime,
+		Steps:           step,
+		EventsProcessed: eventsProcessed,
+		Elapsed:         backtest.lastRun.wallTime,
+		Done:            true,
+	})
 	return nil
 }
 
@@ -221,13 +627,13 @@ func (backtest *Backtest) HistoryToCsv(filePath string) error {
 	}
 
 	// collect the portfolio and asset price history
-	var portfolioHistories []asset.PortfolioHistory
-	var assetHistories []asset.History
+	var portfolioSeries []*asset.PortfolioSeries
+	var assetSeries []*asset.PriceSeries
 	for _, portfolio := range backtest.portfolios {
-		portfolioHistories = append(portfolioHistories, portfolio.GetHistory())
+		portfolioSeries = append(portfolioSeries, portfolio.GetPortfolioSeries())
 	}
 	for _, asset := range backtest.assets {
-		assetHistories = append(assetHistories, asset.GetHistory())
+		assetSeries = append(assetSeries, asset.GetPriceSeries())
 	}
 
 	// and write this history to csv
@@ -235,9 +641,9 @@ func (backtest *Backtest) HistoryToCsv(filePath string) error {
 		row := []string{snapshotTime.String()}
 
 		// add portfolio values and asset prices to our row
-		for _, portfolioHistory := range portfolioHistories {
+		for _, series := range portfolioSeries {
 			valueString := "NA"
-			portfolioSnapshot, ok := portfolioHistory[snapshotTime]
+			portfolioSnapshot, ok := series.Get(snapshotTime)
 			if ok {
 				portfolioValue := portfolioSnapshot.GetValue()
 				if portfolioValue.Valid {
@@ -247,9 +653,9 @@ func (backtest *Backtest) HistoryToCsv(filePath string) error {
 			row = append(row, valueString)
 		}
 
-		for _, assetHistory := range assetHistories {
+		for _, series := range assetSeries {
 			valueString := "NA"
-			assetSnapshot, ok := assetHistory[snapshotTime]
+			assetSnapshot, ok := series.Get(snapshotTime)
 			if ok {
 				assetPrice := assetSnapshot.GetPrice()
 				if assetPrice.Valid {
@@ -260,8 +666,8 @@ func (backtest *Backtest) HistoryToCsv(filePath string) error {
 		}
 
 		// then add portfolio units in these assets
-		for _, portfolioHistory := range portfolioHistories {
-			portfolioSnapshot, ok := portfolioHistory[snapshotTime]
+		for _, series := range portfolioSeries {
+			portfolioSnapshot, ok := series.Get(snapshotTime)
 			if ok {
 				portfolioHoldings := portfolioSnapshot.GetHoldings()
 				for _, asset := range backtest.assets {
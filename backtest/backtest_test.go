@@ -3,11 +3,16 @@ package backtest
 import (
 	"gobacktrader/asset"
 	"gobacktrader/btutil"
+	"gobacktrader/calendar"
 	"gobacktrader/compliance"
 	"gobacktrader/datasources"
 	"gobacktrader/events"
+	"gobacktrader/resample"
+	"gobacktrader/schedule"
 	"gobacktrader/trade"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -252,6 +257,215 @@ func TestBacktestBasicStrategy(t *testing.T) {
 	}
 }
 
+func TestBacktestSnapshotFrequency(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+
+	// a strategy that never trades
+	strategy := NewStrategy(func() ([]*trade.Trade, error) {
+		return nil, nil
+	})
+
+	backtest := NewBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock)
+	if backtest.GetSnapshotFrequency() != nil {
+		t.Error("Expecting no snapshot frequency by default")
+	}
+	backtest.SetSnapshotFrequency(resample.NewMonthEnd())
+
+	// daily prices spanning two month ends
+	dates := []time.Time{
+		btutil.Date(2021, 3, 30),
+		btutil.Date(2021, 3, 31),
+		btutil.Date(2021, 4, 1),
+		btutil.Date(2021, 4, 30),
+		btutil.Date(2021, 5, 3),
+		btutil.Date(2021, 5, 4),
+	}
+	for i, date := range dates {
+		event := events.NewAssetPriceEvent(stock, date, asset.Price{Float64: float64(i + 1), Valid: true})
+		backtest.AddEvent(&event)
+	}
+
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	// snapshots should only be taken on the last step of each month
+	expectedTimes := []time.Time{dates[1], dates[3], dates[5]}
+	snapshotTimes := backtest.GetSnapshotTimes()
+	if len(snapshotTimes) != len(expectedTimes) {
+		t.Fatalf("Expecting %d snapshot times, got %d", len(expectedTimes), len(snapshotTimes))
+	}
+	for i, expectedTime := range expectedTimes {
+		if !snapshotTimes[i].Equal(expectedTime) {
+			t.Errorf("Unexpected snapshot time - wanted %s, got %s", expectedTime, snapshotTimes[i])
+		}
+	}
+
+	stockHistory := stock.GetHistory()
+	if len(stockHistory) != 3 {
+		t.Errorf("Expecting 3 stock snapshots, got %d", len(stockHistory))
+	}
+	snap, ok := stockHistory[dates[3]]
+	if !ok {
+		t.Fatal("Expecting a stock snapshot at the end of April")
+	}
+	if snap.GetPrice().Float64 != 4 {
+		t.Errorf("Unexpected April close - wanted 4, got %0.2f", snap.GetPrice().Float64)
+	}
+	if len(portfolio.GetHistory()) != 3 {
+		t.Errorf("Expecting 3 portfolio snapshots, got %d", len(portfolio.GetHistory()))
+	}
+}
+
+func TestBacktestEventSources(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock1, err2 := asset.NewStock("ZZB AU", "AUD")
+	stock2, err3 := asset.NewStock("ZZC AU", "AUD")
+	cash, err4 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3, err4); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+	portfolio.Transfer(stock1, 100)
+	portfolio.Transfer(stock2, 100)
+
+	dir, err := ioutil.TempDir("", "backtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// stream stock1 prices from a csv file
+	filePath := filepath.Join(dir, "zzb.csv")
+	contents := "Date,Close\n2021-03-01,1.0\n2021-03-03,1.2\n2021-03-04,1.3\n"
+	if err := ioutil.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	csvSource, err := datasources.NewCsvSource(stock1, filePath)
+	if err != nil {
+		t.Fatalf("Error in NewCsvSource - %s", err)
+	}
+
+	// and stock2 prices from a slice
+	e1 := events.NewAssetPriceEvent(stock2, btutil.Date(2021, 3, 1), asset.Price{Float64: 2.0, Valid: true})
+	e2 := events.NewAssetPriceEvent(stock2, btutil.Date(2021, 3, 2), asset.Price{Float64: 2.1, Valid: true})
+	sliceSource := events.NewSliceSource([]events.IEvent{&e1, &e2})
+
+	// along with an event added directly
+	e3 := events.NewAssetPriceEvent(stock2, btutil.Date(2021, 3, 4), asset.Price{Float64: 2.2, Valid: true})
+
+	strategy := NewStrategy(func() ([]*trade.Trade, error) {
+		return nil, nil
+	})
+	backtest := NewBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock1)
+	backtest.RegisterAsset(stock2)
+	backtest.AddEventSource(csvSource)
+	backtest.AddEventSource(sliceSource)
+	backtest.AddEvent(&e3)
+
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	snapshotTimes := backtest.GetSnapshotTimes()
+	if len(snapshotTimes) != 4 {
+		t.Fatalf("Expecting 4 snapshot times, got %d", len(snapshotTimes))
+	}
+	for i, day := range []int{1, 2, 3, 4} {
+		if !snapshotTimes[i].Equal(btutil.Date(2021, 3, day)) {
+			t.Errorf("Unexpected snapshot time %s", snapshotTimes[i])
+		}
+	}
+
+	// 1000 cash + 100 * 1.3 + 100 * 2.2
+	series := portfolio.GetPortfolioSeries()
+	lastSnapshot, _ := series.Get(btutil.Date(2021, 3, 4))
+	lastValue := lastSnapshot.GetValue()
+	if !lastValue.Valid || btutil.Round2dp(lastValue.Float64) != 1350 {
+		t.Errorf("Unexpected final portfolio value - wanted 1350, got %0.2f", lastValue.Float64)
+	}
+	// stock1 has no price on the 2nd so carries the 1st's price
+	secondSnapshot, _ := series.Get(btutil.Date(2021, 3, 2))
+	secondValue := secondSnapshot.GetValue()
+	if btutil.Round2dp(secondValue.Float64) != 1310 {
+		t.Errorf("Unexpected portfolio value - wanted 1310, got %0.2f", secondValue.Float64)
+	}
+}
+
+func TestBacktestSchedule(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+
+	// buy 10 shares whenever a month end rebalance is scheduled
+	rebalance := false
+	strategy := NewStrategy(func() ([]*trade.Trade, error) {
+		if !rebalance {
+			return nil, nil
+		}
+		rebalance = false
+		return []*trade.Trade{trade.NewTrade(portfolio, stock, 10)}, nil
+	})
+
+	backtest := NewBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock)
+
+	start := btutil.Date(2021, 3, 1)
+	end := btutil.Date(2021, 5, 31)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		event := events.NewAssetPriceEvent(stock, day, asset.Price{Float64: 1.0, Valid: true})
+		backtest.AddEvent(&event)
+	}
+
+	var rebalanceTimes []time.Time
+	scheduler := schedule.NewScheduler(calendar.NewWeekdayCalendar())
+	scheduler.Add(schedule.NewLastTradingDayOfMonth(), func(t time.Time) error {
+		rebalance = true
+		rebalanceTimes = append(rebalanceTimes, t)
+		return nil
+	})
+	if err := backtest.AddSchedule(scheduler, start, end); err != nil {
+		t.Fatalf("Error in AddSchedule - %s", err)
+	}
+
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	// the last trading days of March, April and May 2021
+	expected := []time.Time{btutil.Date(2021, 3, 31), btutil.Date(2021, 4, 30), btutil.Date(2021, 5, 31)}
+	if len(rebalanceTimes) != len(expected) {
+		t.Fatalf("Expecting %d rebalances, got %d", len(expected), len(rebalanceTimes))
+	}
+	for i := range expected {
+		if !rebalanceTimes[i].Equal(expected[i]) {
+			t.Errorf("Unexpected rebalance time %s", rebalanceTimes[i])
+		}
+	}
+	if portfolio.GetUnits(stock) != 30 {
+		t.Errorf("Unexpected stock position - wanted 30, got %0.2f", portfolio.GetUnits(stock))
+	}
+
+	if err := backtest.AddSchedule(scheduler, end, start); err == nil {
+		t.Error("Expecting an error for an invalid schedule range")
+	}
+}
+
 func TestAaplTrading(t *testing.T) {
 	// initialise our portfolio and assets
 	portfolio, err1 := asset.NewPortfolio("MY_ACCOUNT", "USD")
@@ -0,0 +1,125 @@
+package backtest
+
+import (
+	"errors"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"sync"
+	"testing"
+)
+
+func TestBacktestCashRegistry(t *testing.T) {
+	backtest1, backtest2 := NewBacktest(nil), NewBacktest(nil)
+	aud1, err := backtest1.GetCash("AUD")
+	if err != nil {
+		t.Fatalf("Error in GetCash - %s", err)
+	}
+	aud2, _ := backtest2.GetCash("AUD")
+	if aud1 == aud2 {
+		t.Error("Expecting each backtest to hold its own cash")
+	}
+	if defaultAud, _ := asset.NewCash("AUD"); defaultAud == aud1 {
+		t.Error("Not expecting backtest cash from the default registry")
+	}
+
+	portfolio, err := backtest1.NewPortfolio("XXX", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewPortfolio - %s", err)
+	}
+	if !backtest1.HasPortfolio(portfolio) || portfolio.GetCashRegistry() != backtest1.GetCashRegistry() {
+		t.Error("Expecting a registered portfolio using the backtest cash registry")
+	}
+	if _, err := backtest1.NewPortfolio("XXX", "AUD"); err == nil {
+		t.Error("Expecting error for a duplicate portfolio code")
+	}
+
+	// the zero value creates its own registry
+	var zero Backtest
+	if zero.GetCashRegistry() == nil || zero.GetCashRegistry() == asset.DefaultCashRegistry() {
+		t.Error("Expecting a zero value backtest to create its own registry")
+	}
+}
+
+func TestRegisterPortfolioCashRegistry(t *testing.T) {
+	backtest := NewBacktest(nil)
+	portfolio, err := asset.NewPortfolio("XXX", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewPortfolio - %s", err)
+	}
+	defaultAud, _ := asset.NewCash("AUD")
+	portfolio.Transfer(defaultAud, 1000)
+
+	// portfolios on the default registry move onto the backtest registry
+	if err := backtest.RegisterPortfolio(portfolio); err != nil {
+		t.Fatalf("Error in RegisterPortfolio - %s", err)
+	}
+	aud, _ := backtest.GetCash("AUD")
+	if portfolio.GetCashRegistry() != backtest.GetCashRegistry() {
+		t.Error("Expecting the portfolio to use the backtest cash registry")
+	}
+	if units := portfolio.GetAllUnits()[aud]; units != 1000 {
+		t.Errorf("Unexpected backtest cash units - wanted 1000, got %0.2f", units)
+	}
+
+	// portfolios on some other registry are rejected
+	other, _ := asset.NewPortfolio("YYY", "AUD")
+	other.SetCashRegistry(asset.NewCashRegistry())
+	errStr := btutil.GetErrorString(backtest.RegisterPortfolio(other))
+	if errStr != "portfolio 'YYY' takes cash from a registry other than the backtest's" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+}
+
+func TestParallelBacktestCash(t *testing.T) {
+	runBacktest := func() (float64, error) {
+		portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+		stock, err2 := asset.NewStock("ZZB AU", "AUD")
+		cash, err3 := asset.NewCash("AUD")
+		if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+			return 0, err
+		}
+		portfolio.Transfer(cash, 1000)
+
+		backtest := NewBacktest(NewStrategy(func() ([]*trade.Trade, error) {
+			return []*trade.Trade{trade.NewTrade(portfolio, stock, 10)}, nil
+		}))
+		if err := backtest.RegisterPortfolio(portfolio); err != nil {
+			return 0, err
+		}
+		if portfolio.GetCashRegistry() != backtest.GetCashRegistry() {
+			return 0, errors.New("the portfolio does not use the backtest cash registry")
+		}
+		backtest.RegisterAsset(stock)
+		for day := 1; day <= 20; day++ {
+			event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, day), asset.Price{Float64: 1, Valid: true})
+			backtest.AddEvent(&event)
+		}
+		if err := backtest.Run(); err != nil {
+			return 0, err
+		}
+		return portfolio.GetUnits(cash), nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]float64, 4)
+	errs := make([]error, 4)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = runBacktest()
+		}(i)
+	}
+	wg.Wait()
+
+	for i, units := range results {
+		if errs[i] != nil {
+			t.Fatalf("Error in backtest %d - %s", i, errs[i])
+		}
+		if units != 800 { // 20 days buying 10 units at 1
+			t.Errorf("Unexpected cash in backtest %d - wanted 800, got %0.2f", i, units)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+)
+
+// checkpointVersion is incremented when the checkpoint format changes.
+const checkpointVersion = 1
+
+// IStateful is implemented by strategies whose state should be saved in
+// checkpoints. On resume OnStart is called before LoadState.
+type IStateful interface {
+	SaveState() ([]byte, error)
+	LoadState(data []byte) error
+}
+
+// checkpoint records the state of a backtest at the end of a time step.
+// Trades are executed within the step that generates them, so there are
+// no pending orders to record.
+type checkpoint struct {
+	Version            int               `json:"version"`
+	Time               time.Time         `json:"time"`
+	Steps              int               `json:"steps"`
+	StepsSinceSnapshot int               `json:"steps_since_snapshot"`
+	Events             int               `json:"events"`
+	SnapshotTimes      []time.Time       `json:"snapshot_times"`
+	Assets             []assetState      `json:"assets"`
+	Portfolios         []portfolioState  `json:"portfolios"`
+	Strategies         []strategyState   `json:"strategies"`
+	TradeRecords       []tradeRecordJSON `json:"trade_records"`
+}
+
+// assetRef refers to an asset by ticker, where cash is
+// taken from the portfolio's cash registry.
+type assetRef struct {
+	Ticker string `json:"ticker"`
+	Cash   bool   `json:"cash,omitempty"`
+}
+
+type pricePoint struct {
+	Time  time.Time `json:"time"`
+	Price *float64  `json:"price"`
+}
+
+type assetState struct {
+	Ticker  string       `json:"ticker"`
+	History []pricePoint `json:"history"`
+}
+
+type holdingState struct {
+	assetRef
+	Units     float64  `json:"units"`
+	HasWeight bool     `json:"has_weight,omitempty"`
+	Weight    *float64 `json:"weight,omitempty"`
+}
+
+type portfolioSnapshotState struct {
+	Time     time.Time      `json:"time"`
+	Value    *float64       `json:"value"`
+	Holdings []holdingState `json:"holdings"`
+}
+
+type portfolioState struct {
+	Code      string                   `json:"code"`
+	Positions []holdingState           `json:"positions"`
+	History   []portfolioSnapshotState `json:"history"`
+}
+
+type strategyState struct {
+	Name  string `json:"name"`
+	State []byte `json:"state"`
+}
+
+type tradeRecordJSON struct {
+	assetRef
+	Time      time.Time    `json:"time"`
+	Strategy  string       `json:"strategy"`
+	Portfolio string       `json:"portfolio"`
+	Units     float64      `json:"units"`
+	Executed  bool         `json:"executed"`
+	Reason    RejectReason `json:"reason,omitempty"`
+	Price     *float64     `json:"price"`
+	Charges   float64      `json:"charges,omitempty"`
+}
+
+// SetCheckpoint writes a checkpoint to some file every number of time steps
+// while the backtest runs. Pass an empty file path to stop checkpointing.
+func (backtest *Backtest) SetCheckpoint(filePath string, everySteps int) error {
+	if filePath != "" && everySteps < 1 {
+		return errors.New("the checkpoint interval must be at least one step")
+	}
+	backtest.checkpointPath = filePath
+	backtest.checkpointEvery = everySteps
+	return nil
+}
+
+// isCheckpointDue returns true if a checkpoint should be
+// written after some number of completed steps.
+func (backtest *Backtest) isCheckpointDue(steps int) bool {
+	return backtest.checkpointPath != "" && steps%backtest.checkpointEvery == 0
+}
+
+// Resume loads a checkpoint written by an earlier run and continues the
+// backtest from that point. The backtest must be set up as it was for the
+// original run, with the same portfolios, assets, strategies, listeners
+// and events. Events up to the checkpoint are replayed to restore prices
+// and listeners without calling strategies, skipping trade and callback
+// events, before positions, histories and strategy state are restored.
+func (backtest *Backtest) Resume(filePath string) error {
+	return backtest.ResumeContext(context.Background(), filePath)
+}
+
+// ResumeContext resumes the backtest from a checkpoint, stopping between
+// time steps if the context is cancelled or its deadline passes.
+func (backtest *Backtest) ResumeContext(ctx context.Context, filePath string) error {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return err
+	}
+	if cp.Version != checkpointVersion {
+		return fmt.Errorf("'%s' has unsupported checkpoint version %d", filePath, cp.Version)
+	}
+	return backtest.run(ctx, &cp)
+}
+
+// refFor returns a reference to some asset.
+func refFor(a asset.IAssetReadOnly) assetRef {
+	_, isCash := a.(*asset.Cash)
+	return assetRef{Ticker: a.GetTicker(), Cash: isCash}
+}
+
+// resolve returns the asset a reference refers to for some portfolio.
+func (backtest *Backtest) resolve(p *asset.Portfolio, ref assetRef) (asset.IAssetReadOnly, error) {
+	if ref.Cash {
+		return p.GetCash(ref.Ticker)
+	}
+	for _, a := range backtest.assets {
+		if a.GetTicker() == ref.Ticker {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("asset ticker '%s' must be registered to resume from a checkpoint", ref.Ticker)
+}
+
+// findPortfolio returns the registered portfolio with some code.
+func (backtest *Backtest) findPortfolio(code string) (*asset.Portfolio, error) {
+	for _, p := range backtest.portfolios {
+		if p.GetCode() == code {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("portfolio code '%s' must be registered to resume from a checkpoint", code)
+}
+
+func priceToPointer(price asset.Price) *float64 {
+	if !price.Valid {
+		return nil
+	}
+	value := price.Float64
+	return &value
+}
+
+func pointerToPrice(value *float64) asset.Price {
+	if value == nil {
+		return asset.Price{}
+	}
+	return asset.Price{Float64: *value, Valid: true}
+}
+
+// holdingsState returns holdings sorted by ticker, with weights where given.
+func holdingsState(units map[asset.IAssetReadOnly]float64, weights map[asset.IAssetReadOnly]asset.Weight) []holdingState {
+	var holdings []holdingState
+	for a, assetUnits := range units {
+		holding := holdingState{assetRef: refFor(a), Units: assetUnits}
+		if weight, ok := weights[a]; ok {
+			holding.HasWeight = true
+			holding.Weight = priceToPointer(asset.Price(weight))
+		}
+		holdings = append(holdings, holding)
+	}
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Ticker < holdings[j].Ticker })
+	return holdings
+}
+
+// restoreHoldings returns the units and weights of some recorded holdings.
+func (backtest *Backtest) restoreHoldings(p *asset.Portfolio, holdings []holdingState) (map[asset.IAssetReadOnly]float64, map[asset.IAssetReadOnly]asset.Weight, error) {
+	units := make(map[asset.IAssetReadOnly]float64)
+	weights := make(map[asset.IAssetReadOnly]asset.Weight)
+	for _, holding := range holdings {
+		a, err := backtest.resolve(p, holding.assetRef)
+		if err != nil {
+			return nil, nil, err
+		}
+		units[a] = holding.Units
+		if holding.HasWeight {
+			weights[a] = asset.Weight(pointerToPrice(holding.Weight))
+		}
+	}
+	return units, weights, nil
+}
+
+// writeCheckpoint writes the state of the backtest after some number of
+// completed steps, replacing any previous checkpoint file.
+func (backtest *Backtest) writeCheckpoint(currentTime time.Time, steps int, stepsSinceSnapshot int, events int) error {
+	cp := checkpoint{
+		Version:            checkpointVersion,
+		Time:               currentTime,
+		Steps:              steps,
+		StepsSinceSnapshot: stepsSinceSnapshot,
+		Events:             events,
+		SnapshotTimes:      backtest.snapshotTimes,
+	}
+
+	for _, a := range backtest.assets {
+		state := assetState{Ticker: a.GetTicker()}
+		for _, snap := range a.GetPriceSeries().Snapshots() {
+			state.History = append(state.History, pricePoint{Time: snap.GetTime(), Price: priceToPointer(snap.GetPrice())})
+		}
+		cp.Assets = append(cp.Assets, state)
+	}
+
+	for _, p := range backtest.portfolios {
+		state := portfolioState{Code: p.GetCode(), Positions: holdingsState(p.GetAllUnits(), nil)}
+		for _, snap := range p.GetPortfolioSeries().Snapshots() {
+			state.History = append(state.History, portfolioSnapshotState{
+				Time:     snap.GetTime(),
+				Value:    priceToPointer(snap.GetValue()),
+				Holdings: holdingsState(snap.GetHoldings(), snap.GetWeights()),
+			})
+		}
+		cp.Portfolios = append(cp.Portfolios, state)
+	}
+
+	for _, binding := range backtest.strategies {
+		if stateful, ok := hooks(binding.strategy).(IStateful); ok {
+			data, err := stateful.SaveState()
+			if err != nil {
+				return err
+			}
+			cp.Strategies = append(cp.Strategies, strategyState{Name: binding.name, State: data})
+		}
+	}
+
+	for _, record := range backtest.tradeRecords {
+		t := record.GetTrade()
+		if !backtest.HasPortfolio(t.GetPortfolio()) {
+			return fmt.Errorf("portfolio code '%s' must be registered to write a checkpoint", t.GetPortfolio().GetCode())
+		}
+		cp.TradeRecords = append(cp.TradeRecords, tradeRecordJSON{
+			assetRef:  refFor(t.GetAsset()),
+			Time:      record.GetTime(),
+			Strategy:  record.GetStrategyName(),
+			Portfolio: t.GetPortfolio().GetCode(),
+			Units:     t.GetUnits(),
+			Executed:  record.IsExecuted(),
+			Reason:    record.GetRejectReason(),
+			Price:     priceToPointer(record.GetPrice()),
+			Charges:   record.GetCharges(),
+		})
+	}
+
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	// write then rename so a crash never leaves a partial checkpoint
+	tempPath := backtest.checkpointPath + ".tmp"
+	if err := ioutil.WriteFile(tempPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, backtest.checkpointPath)
+}
+
+// fastForward processes events up to the checkpoint time without calling
+// strategies, notifying listeners so that their state is rebuilt.
+func (backtest *Backtest) fastForward(cp *checkpoint) error {
+	for steps := 0; steps < cp.Steps; steps++ {
+		nextTime, ok, err := backtest.nextEventTime()
+		if err != nil {
+			return err
+		}
+		if !ok || nextTime.After(cp.Time) {
+			return fmt.Errorf("the backtest has %d steps before the checkpoint, expecting %d", steps, cp.Steps)
+		}
+		if err := backtest.queueSourceEvents(nextTime); err != nil {
+			return err
+		}
+		eventsToProcess, err := backtest.events.FetchNextGroup()
+		if err != nil {
+			return err
+		}
+		for _, event := range eventsToProcess {
+			if events.GetPhase(event) >= events.PhaseTrade {
+				continue // positions are restored and callbacks are not replayed
+			}
+			if err := event.Process(); err != nil {
+				return err
+			}
+			if err := backtest.notifyEvent(event); err != nil {
+				return err
+			}
+		}
+		if err := backtest.notifyStep(nextTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restore restores positions, histories, trade records
+// and strategy state from a checkpoint.
+func (backtest *Backtest) restore(cp *checkpoint) error {
+	backtest.snapshotTimes = cp.SnapshotTimes
+
+	for _, state := range cp.Assets {
+		a, err := backtest.resolve(nil, assetRef{Ticker: state.Ticker})
+		if err != nil {
+			return err
+		}
+		for _, point := range state.History {
+			a.GetPriceSeries().Add(asset.RestorePriceSnapshot(point.Time, pointerToPrice(point.Price)))
+		}
+	}
+
+	for _, state := range cp.Portfolios {
+		p, err := backtest.findPortfolio(state.Code)
+		if err != nil {
+			return err
+		}
+		units, _, err := backtest.restoreHoldings(p, state.Positions)
+		if err != nil {
+			return err
+		}
+		p.SetPositions(units)
+		for _, snap := range state.History {
+			holdings, weights, err := backtest.restoreHoldings(p, snap.Holdings)
+			if err != nil {
+				return err
+			}
+			p.GetPortfolioSeries().Add(asset.RestorePortfolioSnapshot(snap.Time, pointerToPrice(snap.Value), weights, holdings))
+		}
+	}
+
+	for _, record := range cp.TradeRecords {
+		p, err := backtest.findPortfolio(record.Portfolio)
+		if err != nil {
+			return err
+		}
+		a, err := backtest.resolve(p, record.assetRef)
+		if err != nil {
+			return err
+		}
+		backtest.tradeRecords = append(backtest.tradeRecords, TradeRecord{
+			timestamp:    record.Time,
+			strategyName: record.Strategy,
+			trade:        trade.NewTrade(p, a, record.Units),
+			executed:     record.Executed,
+			reason:       record.Reason,
+			price:        pointerToPrice(record.Price),
+			charges:      record.Charges,
+		})
+	}
+
+	for _, state := range cp.Strategies {
+		var stateful IStateful
+		for _, binding := range backtest.strategies {
+			if binding.name == state.Name {
+				stateful, _ = hooks(binding.strategy).(IStateful)
+			}
+		}
+		if stateful == nil {
+			return fmt.Errorf("strategy '%s' cannot load its checkpoint state", state.Name)
+		}
+		if err := stateful.LoadState(state.State); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"gobacktrader/asset"
+	"gobacktrader/broker"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/indicators"
+	"gobacktrader/resample"
+	"gobacktrader/trade"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// crossoverStrategy holds a position while the price is above its average
+// and keeps state that must survive a checkpoint.
+type crossoverStrategy struct {
+	portfolio *asset.Portfolio
+	stock     asset.IAssetReadOnly
+	sma       *indicators.SMA
+	crashAt   int
+	Steps     int     `json:"steps"`
+	Target    float64 `json:"target"`
+}
+
+func (s *crossoverStrategy) GenerateTradesWithContext(ctx *Context) ([]*trade.Trade, error) {
+	s.Steps++
+	if s.Steps == s.crashAt {
+		return nil, errors.New("crash")
+	}
+	if !s.sma.IsWarmedUp() {
+		return nil, nil
+	}
+	target := 0.0
+	if s.stock.GetPrice().Float64 > s.sma.GetValue() {
+		target = 100 + float64(s.Steps)
+	}
+	units := target - s.portfolio.GetUnits(s.stock)
+	s.Target = target
+	if units == 0 {
+		return nil, nil
+	}
+	return []*trade.Trade{ctx.NewTrade(s.portfolio, s.stock, units)}, nil
+}
+
+func (s *crossoverStrategy) SaveState() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func (s *crossoverStrategy) LoadState(data []byte) error {
+	return json.Unmarshal(data, s)
+}
+
+// buildCheckpointBacktest returns a backtest over 40 days of prices.
+func buildCheckpointBacktest(t *testing.T, crashAt int) (*Backtest, *asset.Portfolio, *crossoverStrategy) {
+	sma, _ := indicators.NewSMA(5)
+	strategy := &crossoverStrategy{sma: sma, crashAt: crashAt}
+	backtest := NewContextBacktest(strategy)
+	backtest.SetSnapshotFrequency(resample.NewWeekly())
+
+	portfolio, err1 := backtest.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := backtest.GetCash("AUD")
+	charges, err4 := broker.NewFixedRatePlusPercentageCharges(5, 0.001, "AUD")
+	if err := btutil.AnyValidError(err1, err2, err3, err4); err != nil {
+		t.Fatalf("Error in setup - %s", err)
+	}
+	portfolio.SetBroker(broker.NewBroker(charges, broker.NewFillAtLast()))
+	portfolio.Transfer(cash, 10000)
+	backtest.RegisterAsset(stock)
+	backtest.RegisterAsset(cash)
+	backtest.AddListener(indicators.Bind(sma, stock))
+	strategy.portfolio, strategy.stock = portfolio, stock
+
+	for i := 0; i < 40; i++ {
+		price := asset.Price{Float64: 10 + 2*math.Sin(float64(i)/3), Valid: true}
+		event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 1).AddDate(0, 0, i), price)
+		backtest.AddEvent(&event)
+	}
+	return &backtest, portfolio, strategy
+}
+
+// compareRuns checks two backtests produced identical results.
+func compareRuns(t *testing.T, expected *Backtest, actual *Backtest) {
+	expectedTimes, actualTimes := expected.GetSnapshotTimes(), actual.GetSnapshotTimes()
+	if len(expectedTimes) != len(actualTimes) {
+		t.Fatalf("Unexpected snapshot times - wanted %d, got %d", len(expectedTimes), len(actualTimes))
+	}
+	for i := range expectedTimes {
+		if !expectedTimes[i].Equal(actualTimes[i]) {
+			t.Errorf("Unexpected snapshot time at %d", i)
+		}
+	}
+	expectedSeries := expected.GetPortfolios()[0].GetPortfolioSeries()
+	actualSeries := actual.GetPortfolios()[0].GetPortfolioSeries()
+	if !reflect.DeepEqual(expectedSeries.Floats(), actualSeries.Floats()) {
+		t.Errorf("Unexpected portfolio values - wanted %v, got %v", expectedSeries.Floats(), actualSeries.Floats())
+	}
+	for i := 0; i < expectedSeries.Len(); i++ {
+		if len(expectedSeries.At(i).GetHoldings()) != len(actualSeries.At(i).GetHoldings()) {
+			t.Errorf("Unexpected holdings at snapshot %d", i)
+		}
+	}
+	for i, a := range expected.GetAssets() {
+		if !reflect.DeepEqual(a.GetPriceSeries().Floats(), actual.GetAssets()[i].GetPriceSeries().Floats()) {
+			t.Errorf("Unexpected price history for '%s'", a.GetTicker())
+		}
+	}
+
+	expectedRecords, actualRecords := expected.GetTradeRecords(), actual.GetTradeRecords()
+	if len(expectedRecords) != len(actualRecords) {
+		t.Fatalf("Unexpected trade records - wanted %d, got %d", len(expectedRecords), len(actualRecords))
+	}
+	for i := range expectedRecords {
+		e, a := expectedRecords[i], actualRecords[i]
+		if !e.GetTime().Equal(a.GetTime()) || e.GetTrade().GetUnits() != a.GetTrade().GetUnits() ||
+			e.IsExecuted() != a.IsExecuted() || e.GetStrategyName() != a.GetStrategyName() ||
+			e.GetPrice() != a.GetPrice() || e.GetCharges() != a.GetCharges() {
+			t.Errorf("Unexpected trade record at %d", i)
+		}
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+	checkpointPath := filepath.Join(dir, "run.json")
+
+	// the uninterrupted run
+	expected, expectedPortfolio, _ := buildCheckpointBacktest(t, -1)
+	if err := expected.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	// a run that dies after writing a checkpoint at step 14
+	crashed, _, _ := buildCheckpointBacktest(t, 17)
+	if err := crashed.SetCheckpoint(checkpointPath, 7); err != nil {
+		t.Fatalf("Error in SetCheckpoint - %s", err)
+	}
+	if err := crashed.Run(); btutil.GetErrorString(err) != "crash" {
+		t.Fatalf("Expecting the run to crash - %s", err)
+	}
+
+	resumed, resumedPortfolio, strategy := buildCheckpointBacktest(t, -1)
+	if err := resumed.Resume(checkpointPath); err != nil {
+		t.Fatalf("Error in backtest.Resume() - %s", err)
+	}
+	if strategy.Steps != 40 {
+		t.Errorf("Expecting strategy state to be restored - got %d steps", strategy.Steps)
+	}
+	compareRuns(t, expected, resumed)
+
+	cash, _ := resumed.GetCash("AUD")
+	expectedCash, _ := expected.GetCash("AUD")
+	if resumedPortfolio.GetUnits(cash) != expectedPortfolio.GetUnits(expectedCash) {
+		t.Errorf("Unexpected cash - wanted %0.4f, got %0.4f",
+			expectedPortfolio.GetUnits(expectedCash), resumedPortfolio.GetUnits(cash))
+	}
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	backtest := NewBacktest(nil)
+	if err := backtest.SetCheckpoint("run.json", 0); err == nil {
+		t.Error("Expecting error for a zero checkpoint interval")
+	}
+	if err := backtest.Resume(filepath.Join(os.TempDir(), "missing-checkpoint.json")); err == nil {
+		t.Error("Expecting error for a missing checkpoint file")
+	}
+
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	versionPath := filepath.Join(dir, "version.json")
+	ioutil.WriteFile(versionPath, []byte(`{"version": 99}`), 0644)
+	if err := backtest.Resume(versionPath); err == nil {
+		t.Error("Expecting error for an unsupported version")
+	}
+
+	// a checkpoint beyond the events of the backtest
+	stepsPath := filepath.Join(dir, "steps.json")
+	ioutil.WriteFile(stepsPath, []byte(`{"version": 1, "steps": 5, "time": "2021-03-01T00:00:00Z"}`), 0644)
+	if err := backtest.Resume(stepsPath); err == nil {
+		t.Error("Expecting error where the backtest has too few steps")
+	}
+}
@@ -0,0 +1,143 @@
+package backtest
+
+import (
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/trade"
+	"time"
+)
+
+// Context describes the state of a backtest at the current time step.
+// It is passed to strategies so they don't need to close over globals
+// to find the current time, portfolios and prices.
+type Context struct {
+	binding     *strategyBinding
+	currentTime time.Time
+	portfolios  []*asset.Portfolio
+	assets      []asset.IAssetReadOnly
+	pending     []*trade.Trade
+	warmingUp   bool
+}
+
+// newContext returns the context of a strategy for some time step.
+func (backtest *Backtest) newContext(binding *strategyBinding, currentTime time.Time) *Context {
+	return &Context{
+		binding:     binding,
+		currentTime: currentTime,
+		portfolios:  backtest.ownedPortfolios(binding),
+		assets:      backtest.assets,
+	}
+}
+
+// GetStrategyName returns the name of the strategy this context is for.
+func (ctx *Context) GetStrategyName() string {
+	return ctx.binding.name
+}
+
+// GetTime returns the current backtest time.
+func (ctx *Context) GetTime() time.Time {
+	return ctx.currentTime
+}
+
+// IsWarmingUp returns true during the warm-up period,
+// when any trades generated will not be executed.
+func (ctx *Context) IsWarmingUp() bool {
+	return ctx.warmingUp
+}
+
+// GetPortfolios returns the registered portfolios the strategy may trade.
+func (ctx *Context) GetPortfolios() []*asset.Portfolio {
+	return ctx.portfolios
+}
+
+// GetPortfolio returns the registered portfolio with some code
+// provided the strategy may trade it.
+func (ctx *Context) GetPortfolio(code string) (*asset.Portfolio, error) {
+	code = btutil.CleanString(code)
+	for _, portfolio := range ctx.portfolios {
+		if btutil.CleanString(portfolio.GetCode()) == code {
+			return portfolio, nil
+		}
+	}
+	return nil, fmt.Errorf("portfolio code '%s' is not available to strategy '%s'", code, ctx.GetStrategyName())
+}
+
+// GetAssets returns the registered assets.
+func (ctx *Context) GetAssets() []asset.IAssetReadOnly {
+	return ctx.assets
+}
+
+// GetAsset returns the registered asset with some ticker.
+func (ctx *Context) GetAsset(ticker string) (asset.IAssetReadOnly, error) {
+	ticker = btutil.CleanString(ticker)
+	for _, a := range ctx.assets {
+		if btutil.CleanString(a.GetTicker()) == ticker {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("asset ticker '%s' is not registered", ticker)
+}
+
+// GetPriceHistory returns a copy of the price snapshots for some asset
+// taken up to the current time.
+func (ctx *Context) GetPriceHistory(a asset.IAssetReadOnly) asset.History {
+	history := make(asset.History)
+	for _, snap := range ctx.GetPriceSnapshots(a) {
+		history[snap.GetTime()] = snap
+	}
+	return history
+}
+
+// GetPriceSnapshots returns the price snapshots for some asset
+// taken up to the current time, ordered by time.
+func (ctx *Context) GetPriceSnapshots(a asset.IAssetReadOnly) []asset.PriceSnapshot {
+	series := a.GetPriceSeries()
+	if series.Len() == 0 {
+		return nil
+	}
+	return series.Range(series.At(0).GetTime(), ctx.currentTime)
+}
+
+// GetPortfolioHistory returns a copy of the snapshots for some portfolio
+// taken up to the current time.
+func (ctx *Context) GetPortfolioHistory(p *asset.Portfolio) asset.PortfolioHistory {
+	history := make(asset.PortfolioHistory)
+	for _, snap := range ctx.GetPortfolioSnapshots(p) {
+		history[snap.GetTime()] = snap
+	}
+	return history
+}
+
+// GetPortfolioSnapshots returns the snapshots for some portfolio
+// taken up to the current time, ordered by time.
+func (ctx *Context) GetPortfolioSnapshots(p *asset.Portfolio) []asset.PortfolioSnapshot {
+	series := p.GetPortfolioSeries()
+	if series.Len() == 0 {
+		return nil
+	}
+	return series.Range(series.At(0).GetTime(), ctx.currentTime)
+}
+
+// GetPendingTrades returns the trades generated earlier in this
+// time step, such as by other strategies, that are waiting to be executed.
+func (ctx *Context) GetPendingTrades() []*trade.Trade {
+	pending := make([]*trade.Trade, len(ctx.pending))
+	copy(pending, ctx.pending)
+	return pending
+}
+
+// NewTrade returns a new trade in some number of units.
+func (ctx *Context) NewTrade(p *asset.Portfolio, a asset.IAssetReadOnly, units float64) *trade.Trade {
+	return trade.NewTrade(p, a, units)
+}
+
+// NewTradeForValue returns a new trade for some value in the asset's
+// local currency. Positive values buy and negative values sell.
+func (ctx *Context) NewTradeForValue(p *asset.Portfolio, a asset.IAssetReadOnly, value float64) (*trade.Trade, error) {
+	assetValue := a.GetValue()
+	if !assetValue.Valid || assetValue.Float64 == 0.0 {
+		return nil, fmt.Errorf("'%s' cannot size a trade for an asset with invalid value", a.GetTicker())
+	}
+	return trade.NewTrade(p, a, value/assetValue.Float64), nil
+}
@@ -0,0 +1,140 @@
+package backtest
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"testing"
+	"time"
+)
+
+func TestContextStrategy(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+
+	t1 := btutil.Date(2021, 3, 15)
+	t2 := btutil.Date(2021, 3, 16)
+	t3 := btutil.Date(2021, 3, 17)
+
+	var seenTimes []time.Time
+	var historyLengths []int
+	strategy := NewContextStrategy(func(ctx *Context) ([]*trade.Trade, error) {
+		seenTimes = append(seenTimes, ctx.GetTime())
+		p, err := ctx.GetPortfolio("xxx")
+		if err != nil {
+			return nil, err
+		}
+		a, err := ctx.GetAsset("zzb au")
+		if err != nil {
+			return nil, err
+		}
+		historyLengths = append(historyLengths, len(ctx.GetPriceHistory(a)))
+		if len(ctx.GetPriceSnapshots(a)) != len(ctx.GetPriceHistory(a)) {
+			t.Error("Expecting snapshots to match the price history")
+		}
+		if len(ctx.GetPortfolioSnapshots(p)) != len(ctx.GetPortfolioHistory(p)) {
+			t.Error("Expecting snapshots to match the portfolio history")
+		}
+		if len(ctx.GetPendingTrades()) != 0 {
+			t.Error("Expecting no pending trades")
+		}
+		if a.GetPrice().Float64 <= 2 {
+			newTrade, err := ctx.NewTradeForValue(p, a, 200)
+			if err != nil {
+				return nil, err
+			}
+			return []*trade.Trade{newTrade}, nil
+		}
+		return nil, nil
+	})
+
+	backtest := NewContextBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock)
+	backtest.RegisterAsset(cash)
+
+	e1 := events.NewAssetPriceEvent(stock, t1, asset.Price{Float64: 2.50, Valid: true})
+	e2 := events.NewAssetPriceEvent(stock, t2, asset.Price{Float64: 2.00, Valid: true})
+	e3 := events.NewAssetPriceEvent(stock, t3, asset.Price{Float64: 2.50, Valid: true})
+	for _, event := range []events.IEvent{&e1, &e2, &e3} {
+		backtest.AddEvent(event)
+	}
+
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	if len(seenTimes) != 3 || !seenTimes[0].Equal(t1) || !seenTimes[2].Equal(t3) {
+		t.Errorf("Unexpected context times - got %v", seenTimes)
+	}
+	// snapshots are taken after the strategy runs for each step
+	for i, n := range historyLengths {
+		if n != i {
+			t.Errorf("Unexpected price history length at step %d - wanted %d, got %d", i, i, n)
+		}
+	}
+	if units := portfolio.GetUnits(stock); units != 100 {
+		t.Errorf("Unexpected stock position - wanted 100, got %0.2f", units)
+	}
+}
+
+func TestContextLookupErrors(t *testing.T) {
+	backtest := NewBacktest(nil)
+	ctx := backtest.newContext(&strategyBinding{name: "test"}, btutil.Date(2021, 3, 15))
+	if _, err := ctx.GetPortfolio("XXX"); err == nil {
+		t.Error("Expecting error for unregistered portfolio")
+	}
+	if _, err := ctx.GetAsset("ZZB AU"); err == nil {
+		t.Error("Expecting error for unregistered asset")
+	}
+
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	if _, err := ctx.NewTradeForValue(portfolio, stock, 100); err == nil {
+		t.Error("Expecting error sizing a trade without a price")
+	}
+}
+
+type bothStrategy struct {
+	legacyCalls  int
+	contextCalls int
+}
+
+func (s *bothStrategy) GenerateTrades() ([]*trade.Trade, error) {
+	s.legacyCalls++
+	return nil, nil
+}
+
+func (s *bothStrategy) GenerateTradesWithContext(ctx *Context) ([]*trade.Trade, error) {
+	s.contextCalls++
+	return nil, nil
+}
+
+func TestLegacyStrategyAdapter(t *testing.T) {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+
+	strategy := &bothStrategy{}
+	backtest := NewBacktest(strategy)
+	backtest.RegisterAsset(stock)
+	e1 := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 15), asset.Price{Float64: 2.0, Valid: true})
+	backtest.AddEvent(&e1)
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+	if strategy.contextCalls != 1 || strategy.legacyCalls != 0 {
+		t.Errorf("Expecting the context method to be preferred - got %d context and %d legacy calls",
+			strategy.contextCalls, strategy.legacyCalls)
+	}
+}
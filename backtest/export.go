@@ -0,0 +1,287 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// historyRecord holds the backtest history at one snapshot time.
+// Invalid values are written as null.
+type historyRecord struct {
+	Time       string                     `json:"time"`
+	Prices     map[string]*float64        `json:"prices"`
+	Portfolios map[string]portfolioRecord `json:"portfolios"`
+}
+
+type portfolioRecord struct {
+	Value    *float64                 `json:"value"`
+	Holdings map[string]holdingRecord `json:"holdings"`
+	Cash     map[string]holdingRecord `json:"cash"`
+}
+
+type holdingRecord struct {
+	Units  float64  `json:"units"`
+	Weight *float64 `json:"weight"`
+}
+
+// historyRecords collects the backtest history at each snapshot time,
+// where cash balances are keyed by currency and other holdings by ticker.
+func (backtest *Backtest) historyRecords() []historyRecord {
+	var records []historyRecord
+	for _, snapshotTime := range backtest.snapshotTimes {
+		record := historyRecord{
+			Time:       snapshotTime.Format(time.RFC3339Nano),
+			Prices:     make(map[string]*float64),
+			Portfolios: make(map[string]portfolioRecord),
+		}
+		for _, a := range backtest.assets {
+			var price *float64
+			if snap, ok := a.GetPriceSeries().Get(snapshotTime); ok {
+				price = priceToPointer(snap.GetPrice())
+			}
+			record.Prices[a.GetTicker()] = price
+		}
+		for _, p := range backtest.portfolios {
+			snap, ok := p.GetPortfolioSeries().Get(snapshotTime)
+			if !ok {
+				continue
+			}
+			portfolio := portfolioRecord{
+				Value:    priceToPointer(snap.GetValue()),
+				Holdings: make(map[string]holdingRecord),
+				Cash:     make(map[string]holdingRecord),
+			}
+			weights := snap.GetWeights()
+			for a, units := range snap.GetHoldings() {
+				holding := holdingRecord{Units: units}
+				if weight, ok := weights[a]; ok {
+					holding.Weight = priceToPointer(asset.Price(weight))
+				} else if units == 0 && snap.GetValue().Valid {
+					holding.Weight = new(float64) // empty positions are not weighted
+				}
+				if cash, isCash := a.(*asset.Cash); isCash {
+					portfolio.Cash[cash.GetCurrency()] = holding
+				} else {
+					portfolio.Holdings[a.GetTicker()] = holding
+				}
+			}
+			record.Portfolios[p.GetCode()] = portfolio
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// HistoryToJSON will write the backtest history to a json file as an
+// array with one record per snapshot time. Numbers are written in full
+// precision, timestamps as RFC3339 and invalid values as null.
+func (backtest *Backtest) HistoryToJSON(filePath string) error {
+	records := backtest.historyRecords()
+	if records == nil {
+		records = []historyRecord{}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(data)
+	return err
+}
+
+// HistoryToJSONLines will write the backtest history to a file with one
+// json record per line for each snapshot time, in the format used by
+// HistoryToJSON.
+func (backtest *Backtest) HistoryToJSONLines(filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range backtest.historyRecords() {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// CsvOptions sets how backtest history is written to csv.
+type CsvOptions struct {
+	// Precision is the number of decimal places written,
+	// or negative to write numbers in full precision.
+	Precision int
+	// Long writes one row per time, portfolio, ticker and field rather
+	// than one row per time with a column for each value.
+	Long bool
+	// TimeFormat is the layout used to write timestamps.
+	TimeFormat string
+	// Missing is written for invalid values.
+	Missing string
+}
+
+// NewCsvOptions returns csv options that write wide rows in full
+// precision with RFC3339 timestamps and empty invalid values.
+func NewCsvOptions() CsvOptions {
+	return CsvOptions{Precision: -1, TimeFormat: time.RFC3339Nano}
+}
+
+func (o CsvOptions) formatFloat(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return o.Missing
+	}
+	return strconv.FormatFloat(value, 'f', o.Precision, 64)
+}
+
+func (o CsvOptions) formatPointer(value *float64) string {
+	if value == nil {
+		return o.Missing
+	}
+	return o.formatFloat(*value)
+}
+
+// HistoryToCsvWithOptions will write the backtest history to a csv file.
+// Wide rows hold portfolio values, asset prices, then units and weights
+// for each portfolio and asset. Long rows hold the timestamp, portfolio,
+// ticker, field and value, where fields are value, price, units, weight
+// and cash, with cash balances keyed by currency.
+func (backtest *Backtest) HistoryToCsvWithOptions(filePath string, options CsvOptions) error {
+	if options.TimeFormat == "" {
+		return errors.New("csv options require a time format")
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	records := backtest.historyRecords()
+	if options.Long {
+		err = backtest.writeLongCsv(writer, records, options)
+	} else {
+		err = backtest.writeWideCsv(writer, records, options)
+	}
+	if err != nil {
+		return err
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (backtest *Backtest) writeWideCsv(writer *csv.Writer, records []historyRecord, options CsvOptions) error {
+	headers := []string{"TimeStamp"}
+	for _, p := range backtest.portfolios {
+		headers = append(headers, "PORTFOLIO_"+cleanCode(p.GetCode())+"_VALUE")
+	}
+	for _, a := range backtest.assets {
+		headers = append(headers, cleanCode(a.GetTicker())+"_PRICE")
+	}
+	for _, field := range []string{"UNITS", "WEIGHT"} {
+		for _, p := range backtest.portfolios {
+			for _, a := range backtest.assets {
+				headers = append(headers, fmt.Sprintf("%s_%s_%s", cleanCode(p.GetCode()), cleanCode(a.GetTicker()), field))
+			}
+		}
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		row := []string{backtest.snapshotTimes[i].Format(options.TimeFormat)}
+		for _, p := range backtest.portfolios {
+			row = append(row, options.formatPointer(record.Portfolios[p.GetCode()].Value))
+		}
+		for _, a := range backtest.assets {
+			row = append(row, options.formatPointer(record.Prices[a.GetTicker()]))
+		}
+		for _, weights := range []bool{false, true} {
+			for _, p := range backtest.portfolios {
+				portfolio := record.Portfolios[p.GetCode()]
+				for _, a := range backtest.assets {
+					holding, ok := portfolio.Holdings[a.GetTicker()]
+					if cash, isCash := a.(*asset.Cash); isCash {
+						holding, ok = portfolio.Cash[cash.GetCurrency()]
+					}
+					switch {
+					case !weights:
+						row = append(row, options.formatFloat(holding.Units))
+					case ok:
+						row = append(row, options.formatPointer(holding.Weight))
+					default:
+						row = append(row, options.formatFloat(0))
+					}
+				}
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (backtest *Backtest) writeLongCsv(writer *csv.Writer, records []historyRecord, options CsvOptions) error {
+	if err := writer.Write([]string{"TimeStamp", "Portfolio", "Ticker", "Field", "Value"}); err != nil {
+		return err
+	}
+	for i, record := range records {
+		timestamp := backtest.snapshotTimes[i].Format(options.TimeFormat)
+		rows := [][]string{}
+		for _, p := range backtest.portfolios {
+			code := p.GetCode()
+			portfolio, ok := record.Portfolios[code]
+			if !ok {
+				continue
+			}
+			rows = append(rows, []string{timestamp, code, "", "value", options.formatPointer(portfolio.Value)})
+			for _, ticker := range sortedKeys(portfolio.Holdings) {
+				holding := portfolio.Holdings[ticker]
+				rows = append(rows,
+					[]string{timestamp, code, ticker, "units", options.formatFloat(holding.Units)},
+					[]string{timestamp, code, ticker, "weight", options.formatPointer(holding.Weight)})
+			}
+			for _, currency := range sortedKeys(portfolio.Cash) {
+				holding := portfolio.Cash[currency]
+				rows = append(rows,
+					[]string{timestamp, code, currency, "cash", options.formatFloat(holding.Units)},
+					[]string{timestamp, code, currency, "weight", options.formatPointer(holding.Weight)})
+			}
+		}
+		for _, a := range backtest.assets {
+			ticker := a.GetTicker()
+			rows = append(rows, []string{timestamp, "", ticker, "price", options.formatPointer(record.Prices[ticker])})
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedKeys(holdings map[string]holdingRecord) []string {
+	keys := make([]string, 0, len(holdings))
+	for key := range holdings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"gobacktrader/asset"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runExportBacktest runs a backtest with an unpriced asset
+// so that exports include invalid values.
+func runExportBacktest(t *testing.T) (*Backtest, string) {
+	backtest, _, _ := buildCheckpointBacktest(t, 0)
+	unpriced, _ := asset.NewStock("ZZC AU", "AUD")
+	backtest.RegisterAsset(unpriced)
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	return backtest, dir
+}
+
+func TestHistoryToJSON(t *testing.T) {
+	backtest, dir := runExportBacktest(t)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "history.json")
+	if err := backtest.HistoryToJSON(filePath); err != nil {
+		t.Fatalf("Error in HistoryToJSON - %s", err)
+	}
+	data, _ := ioutil.ReadFile(filePath)
+	var records []historyRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		t.Fatalf("Error in Unmarshal - %s", err)
+	}
+
+	snapshotTimes := backtest.GetSnapshotTimes()
+	if len(records) != len(snapshotTimes) {
+		t.Fatalf("Unexpected number of records - wanted %d, got %d", len(snapshotTimes), len(records))
+	}
+	stock := backtest.GetAssets()[0]
+	prices := stock.GetPriceSeries().Floats()
+	values := backtest.GetPortfolios()[0].GetPortfolioSeries().Floats()
+	for i, record := range records {
+		recordTime, err := time.Parse(time.RFC3339, record.Time)
+		if err != nil || !recordTime.Equal(snapshotTimes[i]) {
+			t.Errorf("Unexpected record time - %s", record.Time)
+		}
+		if price := record.Prices["ZZB AU"]; price == nil || *price != prices[i] {
+			t.Errorf("Unexpected price in full precision at %d", i)
+		}
+		if record.Prices["ZZC AU"] != nil {
+			t.Errorf("Expected null price for unpriced asset")
+		}
+		portfolio := record.Portfolios["XXX"]
+		if portfolio.Value == nil || *portfolio.Value != values[i] {
+			t.Errorf("Unexpected portfolio value at %d", i)
+		}
+		cash, ok := portfolio.Cash["AUD"]
+		if !ok || cash.Weight == nil {
+			t.Errorf("Expected cash balance with weight at %d", i)
+		}
+		weightSum := *cash.Weight
+		for _, holding := range portfolio.Holdings {
+			weightSum += *holding.Weight
+		}
+		if weightSum < 0.999999 || weightSum > 1.000001 {
+			t.Errorf("Unexpected weights summing to %f at %d", weightSum, i)
+		}
+	}
+}
+
+func TestHistoryToJSONLines(t *testing.T) {
+	backtest, dir := runExportBacktest(t)
+	defer os.RemoveAll(dir)
+
+	filePath := filepath.Join(dir, "history.jsonl")
+	if err := backtest.HistoryToJSONLines(filePath); err != nil {
+		t.Fatalf("Error in HistoryToJSONLines - %s", err)
+	}
+	file, _ := os.Open(filePath)
+	defer file.Close()
+	lines := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record historyRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Errorf("Error in Unmarshal for line %d - %s", lines, err)
+		}
+		lines++
+	}
+	if lines != len(backtest.GetSnapshotTimes()) {
+		t.Errorf("Unexpected number of lines - wanted %d, got %d", len(backtest.GetSnapshotTimes()), lines)
+	}
+}
+
+func readCsv(t *testing.T, filePath string) [][]string {
+	file, err := os.Open(filePath)
+	if err != nil {
+		t.Fatalf("Error in Open - %s", err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Error in ReadAll - %s", err)
+	}
+	return rows
+}
+
+func TestHistoryToCsvWithOptions(t *testing.T) {
+	backtest, dir := runExportBacktest(t)
+	defer os.RemoveAll(dir)
+
+	options := NewCsvOptions()
+	options.TimeFormat = ""
+	if err := backtest.HistoryToCsvWithOptions(filepath.Join(dir, "bad.csv"), options); err == nil {
+		t.Errorf("Expected error for missing time format")
+	}
+
+	options = NewCsvOptions()
+	options.Precision, options.Missing = 4, "NA"
+	widePath := filepath.Join(dir, "wide.csv")
+	if err := backtest.HistoryToCsvWithOptions(widePath, options); err != nil {
+		t.Fatalf("Error in HistoryToCsvWithOptions - %s", err)
+	}
+	rows := readCsv(t, widePath)
+	expectedHeaders := "TimeStamp,PORTFOLIO_XXX_VALUE,ZZB_AU_PRICE,AUD_PRICE,ZZC_AU_PRICE," +
+		"XXX_ZZB_AU_UNITS,XXX_AUD_UNITS,XXX_ZZC_AU_UNITS,XXX_ZZB_AU_WEIGHT,XXX_AUD_WEIGHT,XXX_ZZC_AU_WEIGHT"
+	if strings.Join(rows[0], ",") != expectedHeaders {
+		t.Errorf("Unexpected headers - %s", strings.Join(rows[0], ","))
+	}
+	if len(rows) != len(backtest.GetSnapshotTimes())+1 {
+		t.Fatalf("Unexpected number of rows - %d", len(rows))
+	}
+	first := rows[1]
+	if first[0] != backtest.GetSnapshotTimes()[0].Format(time.RFC3339) || first[4] != "NA" || first[10] != "0.0000" {
+		t.Errorf("Unexpected first row - %v", first)
+	}
+	if parts := strings.Split(first[2], "."); len(parts) != 2 || len(parts[1]) != 4 {
+		t.Errorf("Unexpected precision - %s", first[2])
+	}
+
+	options = NewCsvOptions()
+	options.Long = true
+	longPath := filepath.Join(dir, "long.csv")
+	if err := backtest.HistoryToCsvWithOptions(longPath, options); err != nil {
+		t.Fatalf("Error in HistoryToCsvWithOptions - %s", err)
+	}
+	rows = readCsv(t, longPath)
+	if strings.Join(rows[0], ",") != "TimeStamp,Portfolio,Ticker,Field,Value" {
+		t.Errorf("Unexpected long headers - %v", rows[0])
+	}
+	fields := make(map[string]int)
+	for _, row := range rows[1:] {
+		fields[row[3]]++
+		if row[3] == "price" && row[2] == "ZZC AU" && row[4] != "" {
+			t.Errorf("Unexpected price for unpriced asset - %s", row[4])
+		}
+		if row[3] == "price" && row[2] == "ZZB AU" {
+			if _, err := strconv.ParseFloat(row[4], 64); err != nil {
+				t.Errorf("Unexpected price - %s", row[4])
+			}
+		}
+	}
+	snapshots := len(backtest.GetSnapshotTimes())
+	if fields["value"] != snapshots || fields["price"] != 3*snapshots || fields["cash"] != snapshots {
+		t.Errorf("Unexpected long fields - %v", fields)
+	}
+}
@@ -0,0 +1,77 @@
+package backtest
+
+import (
+	"gobacktrader/events"
+	"gobacktrader/trade"
+)
+
+// RejectReason describes why a trade was not executed.
+type RejectReason string
+
+const (
+	// RejectWarmUp is used for trades generated during the warm-up period.
+	RejectWarmUp RejectReason = "warm-up"
+	// RejectCompliance is used for trades that failed compliance.
+	RejectCompliance RejectReason = "compliance"
+	// RejectUnits is used for trades in units the broker would not accept,
+	// such as part lots or trades below a minimum size.
+	RejectUnits RejectReason = "units"
+)
+
+// The following optional interfaces can be implemented by a strategy
+// to be notified as the backtest runs. Within Run these are invoked
+// in the order below, visiting strategies in the order they were added.
+//
+//	OnStart
+//	for each time step:
+//		OnEvent for each event after it has been processed,
+//		following any registered listeners
+//		OnStep for registered listeners
+//		GenerateTrades (or GenerateTradesWithContext)
+//		OnFill or OnReject for each generated trade
+//	OnFinish
+
+// IOnStart is implemented by strategies that need to initialise
+// before the first event is processed.
+type IOnStart interface {
+	OnStart(ctx *Context) error
+}
+
+// IOnEvent is implemented by strategies that are notified
+// of each event once it has been processed.
+type IOnEvent interface {
+	OnEvent(ctx *Context, event events.IEvent) error
+}
+
+// IOnFill is implemented by strategies that are notified
+// when one of their trades is executed.
+type IOnFill interface {
+	OnFill(ctx *Context, t *trade.Trade) error
+}
+
+// IOnReject is implemented by strategies that are notified
+// when one of their trades is not executed.
+type IOnReject interface {
+	OnReject(ctx *Context, t *trade.Trade, reason RejectReason) error
+}
+
+// IOnFinish is implemented by strategies that need to clean up
+// or report once all events have been processed.
+type IOnFinish interface {
+	OnFinish(ctx *Context) error
+}
+
+// IWarmUp is implemented by strategies that declare the number of time
+// steps they need before trading, such as to warm up indicators.
+type IWarmUp interface {
+	GetWarmUpSteps() int
+}
+
+// hooks returns the value that may implement our optional strategy
+// interfaces, unwrapping strategies adapted from IStrategy.
+func hooks(strategy IContextStrategy) interface{} {
+	if adapter, ok := strategy.(strategyAdapter); ok {
+		return adapter.strategy
+	}
+	return strategy
+}
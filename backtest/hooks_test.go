@@ -0,0 +1,143 @@
+package backtest
+
+import (
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"reflect"
+	"testing"
+)
+
+type hookedStrategy struct {
+	portfolio *asset.Portfolio
+	stock     asset.IAssetReadOnly
+	warmUp    int
+	calls     []string
+}
+
+func (s *hookedStrategy) GetWarmUpSteps() int {
+	return s.warmUp
+}
+
+func (s *hookedStrategy) OnStart(ctx *Context) error {
+	s.calls = append(s.calls, "start")
+	return nil
+}
+
+func (s *hookedStrategy) OnEvent(ctx *Context, event events.IEvent) error {
+	s.calls = append(s.calls, fmt.Sprintf("event %d", event.GetTime().Day()))
+	return nil
+}
+
+func (s *hookedStrategy) GenerateTradesWithContext(ctx *Context) ([]*trade.Trade, error) {
+	s.calls = append(s.calls, fmt.Sprintf("trades %d", ctx.GetTime().Day()))
+	return []*trade.Trade{ctx.NewTrade(s.portfolio, s.stock, 10)}, nil
+}
+
+func (s *hookedStrategy) OnFill(ctx *Context, t *trade.Trade) error {
+	s.calls = append(s.calls, fmt.Sprintf("fill %d", ctx.GetTime().Day()))
+	return nil
+}
+
+func (s *hookedStrategy) OnReject(ctx *Context, t *trade.Trade, reason RejectReason) error {
+	s.calls = append(s.calls, fmt.Sprintf("reject %d %s", ctx.GetTime().Day(), reason))
+	return nil
+}
+
+func (s *hookedStrategy) OnFinish(ctx *Context) error {
+	s.calls = append(s.calls, fmt.Sprintf("finish %d", ctx.GetTime().Day()))
+	return nil
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+
+	strategy := &hookedStrategy{portfolio: portfolio, stock: stock, warmUp: 1}
+	backtest := NewContextBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock)
+	backtest.RegisterAsset(cash)
+	if err := backtest.SetWarmUpSteps(2); err != nil {
+		t.Fatalf("Error in SetWarmUpSteps - %s", err)
+	}
+	if steps := backtest.GetWarmUpSteps(); steps != 2 {
+		t.Errorf("Unexpected warm-up steps - wanted 2, got %d", steps)
+	}
+
+	for day := 15; day <= 17; day++ {
+		event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, day), asset.Price{Float64: 1.0, Valid: true})
+		backtest.AddEvent(&event)
+	}
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	expected := []string{
+		"start",
+		"event 15", "trades 15", "reject 15 warm-up",
+		"event 16", "trades 16", "reject 16 warm-up",
+		"event 17", "trades 17", "fill 17",
+		"finish 17",
+	}
+	if !reflect.DeepEqual(strategy.calls, expected) {
+		t.Errorf("Unexpected hook calls - wanted %v, got %v", expected, strategy.calls)
+	}
+	if units := portfolio.GetUnits(stock); units != 10 {
+		t.Errorf("Unexpected stock position after warm-up - wanted 10, got %0.2f", units)
+	}
+
+	if err := backtest.SetWarmUpSteps(-1); err == nil {
+		t.Error("Expecting error for negative warm-up steps")
+	}
+}
+
+func TestStrategyDeclaredWarmUp(t *testing.T) {
+	backtest := NewContextBacktest(&hookedStrategy{warmUp: 5})
+	if steps := backtest.GetWarmUpSteps(); steps != 5 {
+		t.Errorf("Unexpected warm-up steps - wanted 5, got %d", steps)
+	}
+}
+
+func TestUnitRulesReject(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD")
+	rules, err4 := asset.NewTradingRules(100, 0, 0, false)
+	if err := btutil.AnyValidError(err1, err2, err3, err4); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+	stock.SetTradingRules(rules)
+
+	strategy := &hookedStrategy{portfolio: portfolio, stock: stock}
+	backtest := NewContextBacktest(strategy)
+	backtest.RegisterPortfolio(portfolio)
+	backtest.RegisterAsset(stock)
+	backtest.RegisterAsset(cash)
+	event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 15), asset.Price{Float64: 1.0, Valid: true})
+	backtest.AddEvent(&event)
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	// ten units is less than a lot so the trade is rejected
+	expected := []string{"start", "event 15", "trades 15", "reject 15 units", "finish 15"}
+	if !reflect.DeepEqual(strategy.calls, expected) {
+		t.Errorf("Unexpected hook calls - wanted %v, got %v", expected, strategy.calls)
+	}
+	records := backtest.GetTradeRecords()
+	if len(records) != 1 || records[0].IsExecuted() || records[0].GetRejectReason() != RejectUnits {
+		t.Error("Unexpected trade record")
+	}
+	if units := portfolio.GetUnits(stock); units != 0 {
+		t.Errorf("Unexpected stock position - wanted 0, got %0.2f", units)
+	}
+}
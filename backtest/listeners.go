@@ -0,0 +1,56 @@
+package backtest
+
+import (
+	"errors"
+	"gobacktrader/events"
+	"time"
+)
+
+// IEventListener is implemented by listeners notified of each event
+// once it has been processed, such as indicators bound to an asset.
+type IEventListener interface {
+	OnEvent(event events.IEvent) error
+}
+
+// IStepListener is implemented by listeners notified once all
+// events for a time step have been processed.
+type IStepListener interface {
+	OnStep(currentTime time.Time) error
+}
+
+// AddListener registers a listener that implements IEventListener,
+// IStepListener or both. Listeners are notified before the strategy
+// so that values such as indicators are current when it runs.
+func (backtest *Backtest) AddListener(listener interface{}) error {
+	_, isEventListener := listener.(IEventListener)
+	_, isStepListener := listener.(IStepListener)
+	if !isEventListener && !isStepListener {
+		return errors.New("a listener must implement OnEvent or OnStep")
+	}
+	backtest.listeners = append(backtest.listeners, listener)
+	return nil
+}
+
+// notifyEvent notifies listeners of a processed event.
+func (backtest *Backtest) notifyEvent(event events.IEvent) error {
+	for _, listener := range backtest.listeners {
+		if eventListener, ok := listener.(IEventListener); ok {
+			if err := eventListener.OnEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// notifyStep notifies listeners that a time step's events have been processed.
+func (backtest *Backtest) notifyStep(currentTime time.Time) error {
+	for _, listener := range backtest.listeners {
+		if stepListener, ok := listener.(IStepListener); ok {
+			if err := stepListener.OnStep(currentTime); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
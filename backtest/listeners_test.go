@@ -0,0 +1,64 @@
+package backtest
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"testing"
+	"time"
+)
+
+type countingListener struct {
+	events int
+	steps  int
+}
+
+func (l *countingListener) OnEvent(event events.IEvent) error {
+	l.events++
+	return nil
+}
+
+func (l *countingListener) OnStep(currentTime time.Time) error {
+	l.steps++
+	return nil
+}
+
+func TestListeners(t *testing.T) {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+
+	listener := &countingListener{}
+	var stepsSeen []int
+	backtest := NewContextBacktest(NewContextStrategy(func(ctx *Context) ([]*trade.Trade, error) {
+		stepsSeen = append(stepsSeen, listener.steps)
+		return nil, nil
+	}))
+	backtest.RegisterAsset(stock)
+	if err := backtest.AddListener(listener); err != nil {
+		t.Fatalf("Error in AddListener - %s", err)
+	}
+	if err := backtest.AddListener(struct{}{}); err == nil {
+		t.Error("Expecting error for a value that is not a listener")
+	}
+
+	for i := 0; i < 2; i++ {
+		day := btutil.Date(2021, 3, 15+i)
+		e1 := events.NewAssetPriceEvent(stock, day, asset.Price{Float64: 1.0, Valid: true})
+		e2 := events.NewCallbackEvent(day, func(time.Time) error { return nil })
+		backtest.AddEvents([]events.IEvent{&e1, &e2})
+	}
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	if listener.events != 4 || listener.steps != 2 {
+		t.Errorf("Unexpected notifications - got %d events and %d steps", listener.events, listener.steps)
+	}
+	// listeners are notified of a step before the strategy runs
+	if len(stepsSeen) != 2 || stepsSeen[0] != 1 || stepsSeen[1] != 2 {
+		t.Errorf("Unexpected steps seen by the strategy - %v", stepsSeen)
+	}
+}
@@ -0,0 +1,67 @@
+package backtest
+
+import (
+	"errors"
+	"time"
+)
+
+// Progress reports how far a backtest run has got.
+type Progress struct {
+	Time            time.Time     // time of the last completed step
+	Steps           int           // number of completed steps
+	EventsProcessed int           // events processed in this run
+	EventsRemaining int           // events queued, excluding those not yet read from sources
+	Elapsed         time.Duration // wall clock time since the run started
+	Done            bool          // true for the final report of a completed run
+}
+
+// ProgressFunc receives progress reports while a backtest runs.
+type ProgressFunc func(Progress)
+
+// SetProgress sets a function to receive progress every number of
+// steps and once the run completes. Pass nil to stop reporting.
+func (backtest *Backtest) SetProgress(f ProgressFunc, everySteps int) error {
+	if f != nil && everySteps < 1 {
+		return errors.New("the progress interval must be at least one step")
+	}
+	backtest.progress = f
+	backtest.progressEvery = everySteps
+	return nil
+}
+
+// ProgressToChannel returns a ProgressFunc that sends reports to a
+// buffered channel without blocking the backtest. Reports are dropped
+// while the channel is full, except for the final report which replaces
+// the oldest buffered report. The final report is dropped only where
+// there is no room even after this, such as with an unbuffered channel
+// that has no receiver waiting.
+func ProgressToChannel(ch chan Progress) ProgressFunc {
+	return func(p Progress) {
+		select {
+		case ch <- p:
+			return
+		default:
+		}
+		if !p.Done {
+			return
+		}
+		select {
+		case <-ch:
+		default:
+		}
+		select {
+		case ch <- p:
+		default:
+		}
+	}
+}
+
+// reportProgress reports progress if a report is due.
+func (backtest *Backtest) reportProgress(p Progress) {
+	if backtest.progress == nil {
+		return
+	}
+	if p.Done || p.Steps%backtest.progressEvery == 0 {
+		backtest.progress(p)
+	}
+}
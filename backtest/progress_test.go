@@ -0,0 +1,108 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	backtest, _, _ := buildCheckpointBacktest(t, 0)
+	if err := backtest.SetProgress(func(Progress) {}, 0); err == nil {
+		t.Errorf("Expected error for a zero progress interval")
+	}
+
+	reports := []Progress{}
+	if err := backtest.SetProgress(func(p Progress) { reports = append(reports, p) }, 10); err != nil {
+		t.Fatalf("Error in SetProgress - %s", err)
+	}
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	if len(reports) != 5 {
+		t.Fatalf("Unexpected number of progress reports - wanted 5, got %d", len(reports))
+	}
+	for i, p := range reports[:4] {
+		if p.Steps != 10*(i+1) || p.EventsProcessed != 10*(i+1) || p.EventsRemaining != 40-10*(i+1) || p.Done {
+			t.Errorf("Unexpected progress report %d - %+v", i, p)
+		}
+	}
+	final := reports[4]
+	if !final.Done || final.Steps != 40 || final.EventsProcessed != 40 || final.EventsRemaining != 0 {
+		t.Errorf("Unexpected final progress report - %+v", final)
+	}
+	if !final.Time.Equal(time.Date(2021, 4, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected final progress time - %s", final.Time)
+	}
+}
+
+func TestRunContextCancel(t *testing.T) {
+	backtest, _, strategy := buildCheckpointBacktest(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	backtest.SetProgress(func(p Progress) {
+		if p.Steps == 12 {
+			cancel()
+		}
+	}, 1)
+
+	if err := backtest.RunContext(ctx); err != context.Canceled {
+		t.Errorf("Unexpected error from cancelled run - %v", err)
+	}
+	if strategy.Steps != 12 {
+		t.Errorf("Unexpected steps after cancel - wanted 12, got %d", strategy.Steps)
+	}
+
+	backtest, _, strategy = buildCheckpointBacktest(t, 0)
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if err := backtest.RunContext(expired); err != context.DeadlineExceeded {
+		t.Errorf("Unexpected error from expired run - %v", err)
+	}
+	if strategy.Steps != 0 {
+		t.Errorf("Unexpected steps after expired deadline - got %d", strategy.Steps)
+	}
+}
+
+func TestProgressToChannel(t *testing.T) {
+	backtest, _, _ := buildCheckpointBacktest(t, 0)
+	ch := make(chan Progress, 2)
+	backtest.SetProgress(ProgressToChannel(ch), 1)
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	// a full channel drops intermediate reports but keeps the final one
+	first, final := <-ch, <-ch
+	if first.Steps != 2 || first.Done {
+		t.Errorf("Unexpected buffered report - %+v", first)
+	}
+	if final.Steps != 40 || !final.Done {
+		t.Errorf("Unexpected final report - %+v", final)
+	}
+	select {
+	case p := <-ch:
+		t.Errorf("Unexpected report after a full channel - %+v", p)
+	default:
+	}
+}
+
+func TestProgressToUnbufferedChannel(t *testing.T) {
+	backtest, _, _ := buildCheckpointBacktest(t, 0)
+	backtest.SetProgress(ProgressToChannel(make(chan Progress)), 1)
+
+	// with no reader every report is dropped rather than blocking the run
+	done := make(chan error, 1)
+	go func() {
+		done <- backtest.Run()
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Error in Run - %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expecting Run to return with an unread unbuffered channel")
+	}
+}
@@ -0,0 +1,302 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"time"
+)
+
+// resultsVersion is incremented when the results format changes.
+const resultsVersion = 1
+
+// runInfo records the outcome of the last completed run.
+type runInfo struct {
+	start    time.Time
+	end      time.Time
+	steps    int
+	events   int
+	wallTime time.Duration
+}
+
+// RunMetadata describes a completed backtest run.
+type RunMetadata struct {
+	Version    int           `json:"version"`
+	Start      time.Time     `json:"start"`
+	End        time.Time     `json:"end"`
+	Steps      int           `json:"steps"`
+	Events     int           `json:"events"`
+	WallTime   time.Duration `json:"wall_time"`
+	Seed       int64         `json:"seed"`
+	Strategies []string      `json:"strategies"`
+	Portfolios []string      `json:"portfolios"`
+	Assets     []string      `json:"assets"`
+}
+
+// ResultPoint is a value at some time, where invalid values are NaN.
+type ResultPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+type resultPointJSON struct {
+	Time  time.Time `json:"time"`
+	Value *float64  `json:"value"`
+}
+
+// MarshalJSON writes NaN values as null.
+func (p ResultPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(resultPointJSON{Time: p.Time, Value: floatToPointer(p.Value)})
+}
+
+// UnmarshalJSON reads null values as NaN.
+func (p *ResultPoint) UnmarshalJSON(data []byte) error {
+	var point resultPointJSON
+	if err := json.Unmarshal(data, &point); err != nil {
+		return err
+	}
+	p.Time, p.Value = point.Time, pointerToFloat(point.Value)
+	return nil
+}
+
+// ResultSeries is a series of values ordered by time.
+type ResultSeries []ResultPoint
+
+// Times returns the times of the series.
+func (s ResultSeries) Times() []time.Time {
+	times := make([]time.Time, len(s))
+	for i, point := range s {
+		times[i] = point.Time
+	}
+	return times
+}
+
+// Values returns the values of the series.
+func (s ResultSeries) Values() []float64 {
+	values := make([]float64, len(s))
+	for i, point := range s {
+		values[i] = point.Value
+	}
+	return values
+}
+
+// HoldingsPoint records the units held by a portfolio at some time along
+// with the weight of each holding, where weights are valid.
+type HoldingsPoint struct {
+	Time    time.Time          `json:"time"`
+	Units   map[string]float64 `json:"units"`
+	Weights map[string]float64 `json:"weights"`
+}
+
+// TradeResult records a trade generated during a run.
+// Prices that were not valid are NaN.
+type TradeResult struct {
+	Time      time.Time
+	Strategy  string
+	Portfolio string
+	Ticker    string
+	Units     float64
+	Price     float64
+	Charges   float64
+	Executed  bool
+	Reason    RejectReason
+}
+
+type tradeResultJSON struct {
+	Time      time.Time    `json:"time"`
+	Strategy  string       `json:"strategy"`
+	Portfolio string       `json:"portfolio"`
+	Ticker    string       `json:"ticker"`
+	Units     float64      `json:"units"`
+	Price     *float64     `json:"price"`
+	Charges   float64      `json:"charges"`
+	Executed  bool         `json:"executed"`
+	Reason    RejectReason `json:"reason,omitempty"`
+}
+
+// MarshalJSON writes NaN prices as null.
+func (r TradeResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tradeResultJSON{
+		Time:      r.Time,
+		Strategy:  r.Strategy,
+		Portfolio: r.Portfolio,
+		Ticker:    r.Ticker,
+		Units:     r.Units,
+		Price:     floatToPointer(r.Price),
+		Charges:   r.Charges,
+		Executed:  r.Executed,
+		Reason:    r.Reason,
+	})
+}
+
+// UnmarshalJSON reads null prices as NaN.
+func (r *TradeResult) UnmarshalJSON(data []byte) error {
+	var record tradeResultJSON
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	*r = TradeResult{
+		Time:      record.Time,
+		Strategy:  record.Strategy,
+		Portfolio: record.Portfolio,
+		Ticker:    record.Ticker,
+		Units:     record.Units,
+		Price:     pointerToFloat(record.Price),
+		Charges:   record.Charges,
+		Executed:  record.Executed,
+		Reason:    record.Reason,
+	}
+	return nil
+}
+
+// Results bundles the output of a completed backtest run. Equity,
+// holdings and charges are keyed by portfolio code, prices by ticker.
+type Results struct {
+	Metadata           RunMetadata                `json:"metadata"`
+	Equity             map[string]ResultSeries    `json:"equity"`
+	Prices             map[string]ResultSeries    `json:"prices"`
+	Holdings           map[string][]HoldingsPoint `json:"holdings"`
+	Trades             []TradeResult              `json:"trades"`
+	Charges            map[string]float64         `json:"charges"`
+	ComplianceBreaches []TradeResult              `json:"compliance_breaches"`
+}
+
+// GetResults returns the results of the last completed run. Trades holds
+// executed trades, while trades rejected by compliance are reported as
+// compliance breaches.
+func (backtest *Backtest) GetResults() (*Results, error) {
+	if backtest.lastRun == nil {
+		return nil, errors.New("the backtest has not completed a run")
+	}
+
+	results := Results{
+		Metadata: RunMetadata{
+			Version:    resultsVersion,
+			Start:      backtest.lastRun.start,
+			End:        backtest.lastRun.end,
+			Steps:      backtest.lastRun.steps,
+			Events:     backtest.lastRun.events,
+			WallTime:   backtest.lastRun.wallTime,
+			Seed:       backtest.seed,
+			Strategies: backtest.GetStrategyNames(),
+			Portfolios: []string{},
+			Assets:     []string{},
+		},
+		Equity:             make(map[string]ResultSeries),
+		Prices:             make(map[string]ResultSeries),
+		Holdings:           make(map[string][]HoldingsPoint),
+		Trades:             []TradeResult{},
+		Charges:            make(map[string]float64),
+		ComplianceBreaches: []TradeResult{},
+	}
+
+	for _, a := range backtest.assets {
+		ticker := a.GetTicker()
+		results.Metadata.Assets = append(results.Metadata.Assets, ticker)
+		series := ResultSeries{}
+		for _, snap := range a.GetPriceSeries().Snapshots() {
+			series = append(series, ResultPoint{Time: snap.GetTime(), Value: priceToFloat(snap.GetPrice().Float64, snap.GetPrice().Valid)})
+		}
+		results.Prices[ticker] = series
+	}
+
+	for _, p := range backtest.portfolios {
+		code := p.GetCode()
+		results.Metadata.Portfolios = append(results.Metadata.Portfolios, code)
+		equity, holdings := ResultSeries{}, []HoldingsPoint{}
+		for _, snap := range p.GetPortfolioSeries().Snapshots() {
+			value := snap.GetValue()
+			equity = append(equity, ResultPoint{Time: snap.GetTime(), Value: priceToFloat(value.Float64, value.Valid)})
+			point := HoldingsPoint{
+				Time:    snap.GetTime(),
+				Units:   make(map[string]float64),
+				Weights: make(map[string]float64),
+			}
+			for a, units := range snap.GetHoldings() {
+				point.Units[a.GetTicker()] = units
+			}
+			for a, weight := range snap.GetWeights() {
+				if weight.Valid {
+					point.Weights[a.GetTicker()] = weight.Float64
+				}
+			}
+			holdings = append(holdings, point)
+		}
+		results.Equity[code] = equity
+		results.Holdings[code] = holdings
+		results.Charges[code] = 0
+	}
+
+	for _, record := range backtest.tradeRecords {
+		t := record.GetTrade()
+		price := record.GetPrice()
+		result := TradeResult{
+			Time:      record.GetTime(),
+			Strategy:  record.GetStrategyName(),
+			Portfolio: t.GetPortfolio().GetCode(),
+			Ticker:    t.GetAsset().GetTicker(),
+			Units:     t.GetUnits(),
+			Price:     priceToFloat(price.Float64, price.Valid),
+			Charges:   record.GetCharges(),
+			Executed:  record.IsExecuted(),
+			Reason:    record.GetRejectReason(),
+		}
+		switch {
+		case result.Executed:
+			results.Trades = append(results.Trades, result)
+			results.Charges[result.Portfolio] += result.Charges
+		case result.Reason == RejectCompliance:
+			results.ComplianceBreaches = append(results.ComplianceBreaches, result)
+		}
+	}
+
+	return &results, nil
+}
+
+// SaveJSON writes the results to a JSON file.
+func (r *Results) SaveJSON(filePath string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filePath, data, 0644)
+}
+
+// LoadResults reads results written by SaveJSON.
+func LoadResults(filePath string) (*Results, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	var results Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, err
+	}
+	if results.Metadata.Version != resultsVersion {
+		return nil, fmt.Errorf("results version %d is not supported, expecting %d", results.Metadata.Version, resultsVersion)
+	}
+	return &results, nil
+}
+
+func priceToFloat(value float64, valid bool) float64 {
+	if !valid {
+		return math.NaN()
+	}
+	return value
+}
+
+func floatToPointer(value float64) *float64 {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return nil
+	}
+	return &value
+}
+
+func pointerToFloat(value *float64) float64 {
+	if value == nil {
+		return math.NaN()
+	}
+	return *value
+}
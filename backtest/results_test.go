@@ -0,0 +1,126 @@
+package backtest
+
+import (
+	"encoding/json"
+	"gobacktrader/compliance"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResults(t *testing.T) {
+	backtest, portfolio, strategy := buildCheckpointBacktest(t, 0)
+	portfolio.AddComplianceRule(compliance.NewUnitLimit(strategy.stock, 120))
+	backtest.SetSeed(42)
+	if _, err := backtest.GetResults(); err == nil {
+		t.Errorf("Expected error for results before a run")
+	}
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+	results, err := backtest.GetResults()
+	if err != nil {
+		t.Fatalf("Error in GetResults - %s", err)
+	}
+
+	metadata := results.Metadata
+	if metadata.Steps != 40 || metadata.Events != 40 || metadata.Seed != 42 {
+		t.Errorf("Unexpected metadata - %+v", metadata)
+	}
+	if !metadata.Start.Equal(time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)) ||
+		!metadata.End.Equal(time.Date(2021, 4, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected run period - %s to %s", metadata.Start, metadata.End)
+	}
+	if len(metadata.Portfolios) != 1 || metadata.Portfolios[0] != "XXX" || len(metadata.Strategies) != 1 {
+		t.Errorf("Unexpected metadata names - %+v", metadata)
+	}
+
+	equity := results.Equity["XXX"]
+	if len(equity) != len(backtest.GetSnapshotTimes()) || len(results.Holdings["XXX"]) != len(equity) {
+		t.Fatalf("Unexpected equity length - %d", len(equity))
+	}
+	expectedValues := portfolio.GetPortfolioSeries().Floats()
+	for i, value := range equity.Values() {
+		if value != expectedValues[i] {
+			t.Errorf("Unexpected equity at %d - wanted %f, got %f", i, expectedValues[i], value)
+		}
+	}
+	if len(results.Prices["ZZB AU"]) != len(equity) {
+		t.Errorf("Unexpected price history length - %d", len(results.Prices["ZZB AU"]))
+	}
+	last := results.Holdings["XXX"][len(equity)-1]
+	if last.Units["ZZB AU"] != portfolio.GetUnits(strategy.stock) {
+		t.Errorf("Unexpected final holdings - %v", last.Units)
+	}
+	for _, point := range results.Holdings["XXX"] {
+		if _, ok := point.Weights["ZZB AU"]; ok != (point.Units["ZZB AU"] != 0) {
+			t.Errorf("Unexpected weights at %s - %v", point.Time, point.Weights)
+		}
+	}
+
+	if len(results.Trades)+len(results.ComplianceBreaches) != len(backtest.GetTradeRecords()) {
+		t.Errorf("Unexpected number of trades and breaches")
+	}
+	if len(results.ComplianceBreaches) == 0 {
+		t.Errorf("Expected compliance breaches")
+	}
+	totalCharges := 0.0
+	for _, trade := range results.Trades {
+		expected := 5 + 0.001*math.Abs(trade.Units)*trade.Price
+		if !trade.Executed || math.Abs(trade.Charges-expected) > 1e-9 {
+			t.Errorf("Unexpected charges for trade at %s - wanted %f, got %f", trade.Time, expected, trade.Charges)
+		}
+		totalCharges += trade.Charges
+	}
+	if math.Abs(results.Charges["XXX"]-totalCharges) > 1e-9 {
+		t.Errorf("Unexpected total charges - wanted %f, got %f", totalCharges, results.Charges["XXX"])
+	}
+	for _, breach := range results.ComplianceBreaches {
+		if breach.Executed || breach.Reason != RejectCompliance || breach.Charges != 0 {
+			t.Errorf("Unexpected compliance breach - %+v", breach)
+		}
+	}
+}
+
+func TestResultsJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "results")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	backtest, _, _ := buildCheckpointBacktest(t, 0)
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+	results, err := backtest.GetResults()
+	if err != nil {
+		t.Fatalf("Error in GetResults - %s", err)
+	}
+	results.Trades[0].Price = math.NaN()
+
+	filePath := filepath.Join(dir, "results.json")
+	if err := results.SaveJSON(filePath); err != nil {
+		t.Fatalf("Error in SaveJSON - %s", err)
+	}
+	loaded, err := LoadResults(filePath)
+	if err != nil {
+		t.Fatalf("Error in LoadResults - %s", err)
+	}
+	if !math.IsNaN(loaded.Trades[0].Price) {
+		t.Errorf("Expected NaN price to be reloaded")
+	}
+	expected, _ := json.Marshal(results)
+	actual, _ := json.Marshal(loaded)
+	if string(expected) != string(actual) {
+		t.Errorf("Unexpected reloaded results")
+	}
+
+	ioutil.WriteFile(filePath, []byte(`{"metadata": {"version": 99}}`), 0644)
+	if _, err := LoadResults(filePath); err == nil {
+		t.Errorf("Expected error for unsupported results version")
+	}
+}
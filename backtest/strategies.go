@@ -0,0 +1,155 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/trade"
+	"strings"
+	"time"
+)
+
+// DefaultStrategyName is the name given to the strategy passed to NewBacktest.
+const DefaultStrategyName = "default"
+
+// strategyBinding binds a named strategy to the portfolios it owns.
+// A strategy bound to no portfolios owns every portfolio not bound
+// to another strategy.
+type strategyBinding struct {
+	name       string
+	strategy   IContextStrategy
+	portfolios []*asset.Portfolio
+}
+
+// TradeRecord records the outcome of a trade generated by a strategy.
+type TradeRecord struct {
+	timestamp    time.Time
+	strategyName string
+	trade        *trade.Trade
+	executed     bool
+	reason       RejectReason
+	price        asset.Price
+	charges      float64
+}
+
+// GetTime returns the time the trade was generated.
+func (r TradeRecord) GetTime() time.Time {
+	return r.timestamp
+}
+
+// GetStrategyName returns the name of the strategy that generated the trade.
+func (r TradeRecord) GetStrategyName() string {
+	return r.strategyName
+}
+
+// GetTrade returns the trade.
+func (r TradeRecord) GetTrade() *trade.Trade {
+	return r.trade
+}
+
+// IsExecuted returns true if the trade was executed.
+func (r TradeRecord) IsExecuted() bool {
+	return r.executed
+}
+
+// GetRejectReason returns why the trade was not executed,
+// which is empty for executed trades.
+func (r TradeRecord) GetRejectReason() RejectReason {
+	return r.reason
+}
+
+// GetPrice returns the value of the traded asset in local
+// currency when the trade was generated.
+func (r TradeRecord) GetPrice() asset.Price {
+	return r.price
+}
+
+// GetCharges returns the fall in portfolio value from executing the
+// trade in the portfolio's base currency, which includes broker charges
+// and any slippage. It is zero for trades that were not executed.
+func (r TradeRecord) GetCharges() float64 {
+	return r.charges
+}
+
+// AddStrategy registers a named strategy bound to some portfolios,
+// which must already be registered. Only these portfolios may be
+// traded by the strategy. Where no portfolios are given the strategy
+// may trade any portfolio not bound to another strategy.
+func (backtest *Backtest) AddStrategy(name string, strategy IContextStrategy, portfolios ...*asset.Portfolio) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("a strategy requires a name")
+	}
+	if strategy == nil {
+		return fmt.Errorf("strategy '%s' cannot be nil", name)
+	}
+	for _, binding := range backtest.strategies {
+		if binding.name == name {
+			return fmt.Errorf("strategy name '%s' is already in use and needs to be unique", name)
+		}
+		if len(portfolios) == 0 && len(binding.portfolios) == 0 {
+			return fmt.Errorf("strategy '%s' already trades all unbound portfolios", binding.name)
+		}
+	}
+	for _, p := range portfolios {
+		if !backtest.HasPortfolio(p) {
+			return fmt.Errorf("portfolio code '%s' must be registered before binding a strategy", p.GetCode())
+		}
+		if owner := backtest.boundTo(p); owner != nil {
+			return fmt.Errorf("portfolio code '%s' is already bound to strategy '%s'", p.GetCode(), owner.name)
+		}
+	}
+
+	backtest.strategies = append(backtest.strategies, &strategyBinding{
+		name:       name,
+		strategy:   strategy,
+		portfolios: portfolios,
+	})
+	return nil
+}
+
+// GetStrategyNames returns the names of the registered strategies.
+func (backtest *Backtest) GetStrategyNames() []string {
+	var names []string
+	for _, binding := range backtest.strategies {
+		names = append(names, binding.name)
+	}
+	return names
+}
+
+// GetTradeRecords returns the trades generated in the last run
+// along with the strategy that generated each trade.
+func (backtest *Backtest) GetTradeRecords() []TradeRecord {
+	return backtest.tradeRecords
+}
+
+// boundTo returns the strategy explicitly bound to a portfolio, if any.
+func (backtest *Backtest) boundTo(p *asset.Portfolio) *strategyBinding {
+	for _, binding := range backtest.strategies {
+		for _, bound := range binding.portfolios {
+			if bound == p {
+				return binding
+			}
+		}
+	}
+	return nil
+}
+
+// owns returns true if a strategy may trade some portfolio.
+func (backtest *Backtest) owns(binding *strategyBinding, p *asset.Portfolio) bool {
+	if len(binding.portfolios) == 0 {
+		return backtest.boundTo(p) == nil
+	}
+	return backtest.boundTo(p) == binding
+}
+
+// ownedPortfolios returns the registered portfolios a strategy may trade.
+func (backtest *Backtest) ownedPortfolios(binding *strategyBinding) []*asset.Portfolio {
+	var portfolios []*asset.Portfolio
+	for _, p := range backtest.portfolios {
+		if backtest.owns(binding, p) {
+			portfolios = append(portfolios, p)
+		}
+	}
+	return portfolios
+}
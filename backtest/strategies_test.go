@@ -0,0 +1,124 @@
+package backtest
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"testing"
+)
+
+func TestMultipleStrategies(t *testing.T) {
+	momentum, err1 := asset.NewPortfolio("MOMENTUM", "AUD")
+	value, err2 := asset.NewPortfolio("VALUE", "AUD")
+	stock, err3 := asset.NewStock("ZZB AU", "AUD")
+	cash, err4 := asset.NewCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3, err4); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	momentum.Transfer(cash, 1000)
+	value.Transfer(cash, 1000)
+
+	backtest := NewBacktest(nil)
+	backtest.RegisterPortfolio(momentum)
+	backtest.RegisterPortfolio(value)
+	backtest.RegisterAsset(stock)
+	backtest.RegisterAsset(cash)
+
+	var pendingSeen int
+	buyer := func(units float64) *ContextStrategy {
+		return NewContextStrategy(func(ctx *Context) ([]*trade.Trade, error) {
+			pendingSeen += len(ctx.GetPendingTrades())
+			portfolios := ctx.GetPortfolios()
+			if len(portfolios) != 1 {
+				t.Errorf("Expecting one portfolio for strategy '%s', got %d", ctx.GetStrategyName(), len(portfolios))
+			}
+			return []*trade.Trade{ctx.NewTrade(portfolios[0], stock, units)}, nil
+		})
+	}
+	if err := backtest.AddStrategy("momentum", buyer(10), momentum); err != nil {
+		t.Fatalf("Error in AddStrategy - %s", err)
+	}
+	if err := backtest.AddStrategy("value", buyer(20), value); err != nil {
+		t.Fatalf("Error in AddStrategy - %s", err)
+	}
+
+	for day := 15; day <= 16; day++ {
+		event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, day), asset.Price{Float64: 1.0, Valid: true})
+		backtest.AddEvent(&event)
+	}
+	if err := backtest.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+
+	if units := momentum.GetUnits(stock); units != 20 {
+		t.Errorf("Unexpected momentum position - wanted 20, got %0.2f", units)
+	}
+	if units := value.GetUnits(stock); units != 40 {
+		t.Errorf("Unexpected value position - wanted 40, got %0.2f", units)
+	}
+	// the value strategy sees the momentum trade pending in each step
+	if pendingSeen != 2 {
+		t.Errorf("Unexpected pending trades seen - wanted 2, got %d", pendingSeen)
+	}
+
+	records := backtest.GetTradeRecords()
+	if len(records) != 4 {
+		t.Fatalf("Unexpected number of trade records - wanted 4, got %d", len(records))
+	}
+	if records[0].GetStrategyName() != "momentum" || records[1].GetStrategyName() != "value" {
+		t.Error("Unexpected trade attribution")
+	}
+	if !records[1].IsExecuted() || records[1].GetTrade().GetUnits() != 20 {
+		t.Error("Expecting the value trade to be executed")
+	}
+}
+
+func TestStrategyOwnership(t *testing.T) {
+	p1, err1 := asset.NewPortfolio("P1", "AUD")
+	p2, err2 := asset.NewPortfolio("P2", "AUD")
+	stock, err3 := asset.NewStock("ZZB AU", "AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+
+	// the default strategy trades a portfolio bound to another strategy
+	rogue := NewContextStrategy(func(ctx *Context) ([]*trade.Trade, error) {
+		return []*trade.Trade{ctx.NewTrade(p1, stock, 10)}, nil
+	})
+	backtest := NewContextBacktest(rogue)
+	backtest.RegisterPortfolio(p1)
+	backtest.RegisterAsset(stock)
+
+	noTrades := NewContextStrategy(func(ctx *Context) ([]*trade.Trade, error) { return nil, nil })
+	if err := backtest.AddStrategy("sleeve", noTrades, p2); err == nil {
+		t.Error("Expecting error binding an unregistered portfolio")
+	}
+	if err := backtest.AddStrategy("sleeve", noTrades, p1); err != nil {
+		t.Fatalf("Error in AddStrategy - %s", err)
+	}
+	if err := backtest.AddStrategy("sleeve", noTrades); err == nil {
+		t.Error("Expecting error for a duplicate strategy name")
+	}
+	if err := backtest.AddStrategy("other", noTrades, p1); err == nil {
+		t.Error("Expecting error binding a portfolio to two strategies")
+	}
+	if err := backtest.AddStrategy("unbound", noTrades); err == nil {
+		t.Error("Expecting error adding a second unbound strategy")
+	}
+	if err := backtest.AddStrategy(" ", noTrades); err == nil {
+		t.Error("Expecting error for an empty strategy name")
+	}
+	names := backtest.GetStrategyNames()
+	if len(names) != 2 || names[0] != DefaultStrategyName || names[1] != "sleeve" {
+		t.Errorf("Unexpected strategy names - %v", names)
+	}
+
+	event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 15), asset.Price{Float64: 1.0, Valid: true})
+	backtest.AddEvent(&event)
+	err := backtest.Run()
+	expected := "strategy 'default' cannot trade portfolio 'P1' which it does not own"
+	if errStr := btutil.GetErrorString(err); errStr != expected {
+		t.Errorf("Unexpected error string - '%s'", errStr)
+	}
+}
@@ -30,3 +30,55 @@ func (s *Strategy) SetGenerateTrades(f generateTradesFunc) {
 func (s *Strategy) GenerateTrades() ([]*trade.Trade, error) {
 	return s.generateTradesFunc()
 }
+
+// IContextStrategy defines the interface for trading strategies that
+// receive a Context describing the backtest at each time step.
+type IContextStrategy interface {
+	GenerateTradesWithContext(*Context) ([]*trade.Trade, error)
+}
+
+type generateTradesWithContextFunc func(*Context) ([]*trade.Trade, error)
+
+// ContextStrategy has a function field to generate trades from a Context.
+type ContextStrategy struct {
+	generateTradesWithContextFunc
+}
+
+// NewContextStrategy returns a new context strategy instance.
+func NewContextStrategy(f generateTradesWithContextFunc) *ContextStrategy {
+	return &ContextStrategy{generateTradesWithContextFunc: f}
+}
+
+// SetGenerateTradesWithContext sets our generate trades strategy function.
+func (s *ContextStrategy) SetGenerateTradesWithContext(f generateTradesWithContextFunc) {
+	s.generateTradesWithContextFunc = f
+}
+
+// GenerateTradesWithContext returns a slice of trades to execute.
+func (s *ContextStrategy) GenerateTradesWithContext(ctx *Context) ([]*trade.Trade, error) {
+	return s.generateTradesWithContextFunc(ctx)
+}
+
+// strategyAdapter allows an IStrategy to be used where an
+// IContextStrategy is expected by ignoring the context.
+type strategyAdapter struct {
+	strategy IStrategy
+}
+
+// GenerateTradesWithContext returns the trades from the wrapped strategy.
+func (s strategyAdapter) GenerateTradesWithContext(ctx *Context) ([]*trade.Trade, error) {
+	return s.strategy.GenerateTrades()
+}
+
+// AdaptStrategy returns a strategy as an IContextStrategy so that it
+// can be passed to AddStrategy, preferring the context method where
+// a strategy has both.
+func AdaptStrategy(strategy IStrategy) IContextStrategy {
+	if strategy == nil {
+		return nil
+	}
+	if contextStrategy, ok := strategy.(IContextStrategy); ok {
+		return contextStrategy
+	}
+	return strategyAdapter{strategy: strategy}
+}
@@ -17,8 +17,9 @@ type ExecutionStrategy interface {
 
 // Broker defines an executing broker with associated charges.
 type Broker struct {
-	charges   ChargesStrategy
-	execution ExecutionStrategy
+	charges    ChargesStrategy
+	execution  ExecutionStrategy
+	unitPolicy UnitPolicy
 }
 
 // NewBroker returns a new Broker instance.
@@ -30,8 +31,27 @@ func NewBroker(charges ChargesStrategy, execution ExecutionStrategy) *Broker {
 }
 
 // Execute will use our broker instance to execute a trade.
+// Trades are first checked against the asset's trading rules.
+// Where the trade is resized under the unit policy the units
+// executed are recorded on trades that implement
+// asset.IResizableTrade once execution succeeds.
 func (b *Broker) Execute(trade asset.ITrade) error {
-	err1 := b.execution.Execute(trade)
-	err2 := b.charges.Charge(trade)
-	return btutil.AnyValidError(err1, err2)
+	units, err := b.CheckUnits(trade)
+	if err != nil {
+		return err
+	}
+	executing := trade
+	if units != trade.GetUnits() {
+		executing = resizedTrade{ITrade: trade, units: units}
+	}
+
+	err1 := b.execution.Execute(executing)
+	err2 := b.charges.Charge(executing)
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		return err
+	}
+	if resizable, ok := trade.(asset.IResizableTrade); ok && units != trade.GetUnits() {
+		resizable.SetExecutedUnits(units)
+	}
+	return nil
 }
@@ -66,7 +66,7 @@ func (c FixedRatePlusPercentageCharges) Charge(trade asset.ITrade) error {
 	}
 
 	// we'll apply charges in the chosen currency
-	cash, err := asset.NewCash(c.currencyCode)
+	cash, err := portfolio.GetCash(c.currencyCode)
 	if err != nil {
 		return err
 	}
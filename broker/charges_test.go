@@ -202,3 +202,24 @@ func TestFixedRatePlusPercentageChargesError(t *testing.T) {
 		t.Errorf("Unexpected error string - %s", err)
 	}
 }
+
+func TestChargesCashRegistry(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in asset init - %s", err)
+	}
+	registry := asset.NewCashRegistry()
+	portfolio.SetCashRegistry(registry)
+	cash, _ := registry.GetCash("AUD")
+	portfolio.Transfer(cash, 1000.0)
+	stock.SetPrice(asset.Price{Float64: 2.50, Valid: true})
+
+	charges, _ := NewFixedRatePlusPercentageCharges(20, 0.01, "AUD")
+	if err := charges.Charge(trade.NewTrade(portfolio, stock, 100)); err != nil {
+		t.Fatalf("Error in Charge - %s", err)
+	}
+	if units := portfolio.GetUnits(cash); units != 977.5 {
+		t.Errorf("Expecting charges from the registry cash - got %0.2f", units)
+	}
+}
@@ -0,0 +1,80 @@
+package broker
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"math"
+)
+
+// UnitPolicy sets how a broker handles trades in units
+// that break an asset's trading rules.
+type UnitPolicy int
+
+const (
+	// RejectInvalidUnits rejects trades that break the trading rules.
+	RejectInvalidUnits UnitPolicy = iota
+	// RoundUnits rounds trades toward zero to a whole number of lots,
+	// or whole units, then rejects trades that still break the rules.
+	RoundUnits
+)
+
+// SetUnitPolicy sets how trades that break trading rules are handled,
+// which defaults to RejectInvalidUnits.
+func (b *Broker) SetUnitPolicy(policy UnitPolicy) {
+	b.unitPolicy = policy
+}
+
+// GetUnitPolicy returns how trades that break trading rules are handled.
+func (b *Broker) GetUnitPolicy() UnitPolicy {
+	return b.unitPolicy
+}
+
+// CheckUnits returns the units the broker will execute for a trade under
+// its unit policy, or an asset.UnitRuleError if the trade is rejected.
+// Trades that close out a position are always allowed.
+func (b *Broker) CheckUnits(trade asset.ITrade) (float64, error) {
+	a, units := trade.GetAsset(), trade.GetUnits()
+	if portfolio := trade.GetPortfolio(); portfolio != nil && units != 0 && units == -portfolio.GetUnits(a) {
+		return units, nil
+	}
+
+	rules := asset.GetTradingRules(a)
+	if b.unitPolicy == RoundUnits {
+		rounded := rules.Round(units)
+		if rounded == 0 && units != 0 {
+			return 0, &asset.UnitRuleError{Ticker: a.GetTicker(), Units: units, Reason: "they round to zero units"}
+		}
+		units = rounded
+	}
+	return units, rules.Check(a, units)
+}
+
+// resizedTrade is a trade executed in different units to those requested.
+type resizedTrade struct {
+	asset.ITrade
+	units float64
+}
+
+// GetUnits returns the units to be traded.
+func (t resizedTrade) GetUnits() float64 {
+	return t.units
+}
+
+// GetLocalCurrencyValue returns the trade value.
+func (t resizedTrade) GetLocalCurrencyValue() asset.Price {
+	assetValue := t.GetAsset().GetValue()
+	if !assetValue.Valid {
+		return asset.Price{Float64: 0.0, Valid: false}
+	}
+	return asset.Price{Float64: assetValue.Float64 * math.Abs(t.units), Valid: true}
+}
+
+// GetLocalCurrencyConsideration returns the cash transferred
+// for this trade in local currency.
+func (t resizedTrade) GetLocalCurrencyConsideration() asset.Price {
+	tradeValue := t.GetLocalCurrencyValue()
+	if !tradeValue.Valid {
+		return tradeValue
+	}
+	return asset.Price{Float64: tradeValue.Float64 * btutil.Sgn(t.units) * -1.0, Valid: true}
+}
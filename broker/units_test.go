@@ -0,0 +1,86 @@
+package broker
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+// unitTrade is a minimal trade for checking units.
+type unitTrade struct {
+	portfolio *asset.Portfolio
+	asset     asset.IAssetReadOnly
+	units     float64
+}
+
+func (t unitTrade) GetPortfolio() *asset.Portfolio { return t.portfolio }
+func (t unitTrade) GetAsset() asset.IAssetReadOnly { return t.asset }
+func (t unitTrade) GetUnits() float64              { return t.units }
+func (t unitTrade) PassesCompliance() (bool, error) {
+	return true, nil
+}
+func (t unitTrade) GetLocalCurrencyValue() asset.Price {
+	return asset.Price{Float64: t.asset.GetValue().Float64 * math.Abs(t.units), Valid: true}
+}
+func (t unitTrade) GetLocalCurrencyConsideration() asset.Price {
+	return asset.Price{Float64: -t.asset.GetValue().Float64 * t.units, Valid: true}
+}
+
+func TestBrokerUnitPolicy(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	rules, err3 := asset.NewTradingRules(100, 0, 0, false)
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset init - %s", err)
+	}
+	stock.SetPrice(asset.Price{Float64: 2, Valid: true})
+	stock.SetTradingRules(rules)
+	aud, err := portfolio.GetCash("AUD")
+	if err != nil {
+		t.Fatalf("Error in GetCash - %s", err)
+	}
+	portfolio.Transfer(aud, 1000)
+
+	b := NewBroker(NewNoCharges(), NewFillAtLast())
+	if b.GetUnitPolicy() != RejectInvalidUnits {
+		t.Error("Expecting invalid units to be rejected by default")
+	}
+
+	// part lots are rejected
+	err = b.Execute(unitTrade{portfolio, stock, 250})
+	if _, ok := err.(*asset.UnitRuleError); !ok {
+		t.Errorf("Expecting a unit rule error - %v", err)
+	}
+	if portfolio.GetUnits(stock) != 0 {
+		t.Error("Expecting no units traded")
+	}
+
+	// or rounded down to whole lots
+	b.SetUnitPolicy(RoundUnits)
+	if b.GetUnitPolicy() != RoundUnits {
+		t.Error("Unexpected unit policy")
+	}
+	units, err := b.CheckUnits(unitTrade{portfolio, stock, 250})
+	if err != nil || units != 200 {
+		t.Errorf("Unexpected units %f - %v", units, err)
+	}
+	if err := b.Execute(unitTrade{portfolio, stock, 250}); err != nil {
+		t.Fatalf("Error in Execute - %s", err)
+	}
+	if portfolio.GetUnits(stock) != 200 || portfolio.GetUnits(aud) != 600 {
+		t.Errorf("Unexpected holdings %f, %f", portfolio.GetUnits(stock), portfolio.GetUnits(aud))
+	}
+
+	// trades that round to zero are rejected
+	_, err = b.CheckUnits(unitTrade{portfolio, stock, 50})
+	if err == nil || err.Error() != "'ZZB AU' cannot trade 50 units as they round to zero units" {
+		t.Errorf("Unexpected error in CheckUnits - %v", err)
+	}
+
+	// closing out a position is always allowed
+	portfolio.Transfer(stock, 30)
+	if units, err := b.CheckUnits(unitTrade{portfolio, stock, -230}); err != nil || units != -230 {
+		t.Errorf("Unexpected units %f - %v", units, err)
+	}
+}
@@ -11,6 +11,19 @@ func CleanString(s string) string {
 	return strings.ToUpper(strings.TrimSpace(s))
 }
 
+// CleanFileName replaces characters in a name that are
+// not safe in file names with underscores.
+func CleanFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
 // Round2dp rounds a number to two decimal places.
 func Round2dp(x float64) float64 {
 	return math.Round(x*100) / 100
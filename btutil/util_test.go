@@ -13,6 +13,14 @@ func TestCleanString(t *testing.T) {
 	}
 }
 
+func TestCleanFileName(t *testing.T) {
+	result := CleanFileName("ZZB AU/x-1_2.csv")
+	expectedResult := "ZZB_AU_x-1_2_csv"
+	if result != expectedResult {
+		t.Fatalf("Bad formatting in CleanFileName: wanted '%s', got '%s'", expectedResult, result)
+	}
+}
+
 func TestRound2dp(t *testing.T) {
 	if Round2dp(1.222) != 1.22 {
 		t.Error("Rounding down error.")
@@ -0,0 +1,104 @@
+// Package calendar determines which days are trading days.
+package calendar
+
+import "time"
+
+// ITradingCalendar defines the interface for trading calendars.
+type ITradingCalendar interface {
+	IsTradingDay(time.Time) bool
+}
+
+// WeekdayCalendar treats every Monday to Friday as a trading day.
+type WeekdayCalendar struct{}
+
+// NewWeekdayCalendar returns a new instance of WeekdayCalendar.
+func NewWeekdayCalendar() WeekdayCalendar {
+	return WeekdayCalendar{}
+}
+
+// IsTradingDay returns true if the day is a weekday, false otherwise.
+func (c WeekdayCalendar) IsTradingDay(t time.Time) bool {
+	return !IsWeekend(t)
+}
+
+// IsWeekend returns true if the day is a Saturday or Sunday.
+func IsWeekend(t time.Time) bool {
+	weekday := t.Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
+
+// Day returns the start of the day for some time in its own location.
+func Day(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// TradingDays returns the trading days between the dates
+// of start and end inclusive.
+func TradingDays(cal ITradingCalendar, start time.Time, end time.Time) []time.Time {
+	var days []time.Time
+	for day := Day(start); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if cal.IsTradingDay(day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// MissingTradingDays returns the trading days between the first and last
+// observation that have no observation, which is useful for detecting
+// gaps in price data. Observations are matched on calendar date.
+func MissingTradingDays(cal ITradingCalendar, times []time.Time) []time.Time {
+	var missing []time.Time
+	if len(times) == 0 {
+		return missing
+	}
+
+	observed := make(map[time.Time]bool)
+	first, last := times[0], times[0]
+	for _, t := range times {
+		observed[dateKey(t)] = true
+		if t.Before(first) {
+			first = t
+		}
+		if t.After(last) {
+			last = t
+		}
+	}
+
+	for _, day := range TradingDays(cal, first, last) {
+		if !observed[dateKey(day)] {
+			missing = append(missing, day)
+		}
+	}
+	return missing
+}
+
+// dateKey returns the calendar date of some time at midnight UTC
+// so that dates can be compared regardless of location.
+func dateKey(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// TradingDaysInYear returns the number of trading days in some year.
+func TradingDaysInYear(cal ITradingCalendar, year int) int {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	return len(TradingDays(cal, start, end))
+}
+
+// AnnualisationFactor returns the average number of trading days per year
+// for the years spanned by start and end. Daily return statistics can be
+// annualised with this factor so they agree with the calendar.
+func AnnualisationFactor(cal ITradingCalendar, start time.Time, end time.Time) float64 {
+	total, years := 0, 0
+	for year := start.Year(); year <= end.Year(); year++ {
+		total += TradingDaysInYear(cal, year)
+		years++
+	}
+	if years == 0 {
+		return 0.0
+	}
+	return float64(total) / float64(years)
+}
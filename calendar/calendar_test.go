@@ -0,0 +1,90 @@
+package calendar
+
+import (
+	"gobacktrader/btutil"
+	"testing"
+	"time"
+)
+
+func TestWeekdayCalendar(t *testing.T) {
+	cal := NewWeekdayCalendar()
+	friday := btutil.Date(2021, 3, 5)
+	saturday := btutil.Date(2021, 3, 6)
+	sunday := btutil.Date(2021, 3, 7)
+	if !cal.IsTradingDay(friday) {
+		t.Error("Expecting Friday to be a trading day")
+	}
+	if cal.IsTradingDay(saturday) || cal.IsTradingDay(sunday) {
+		t.Error("Expecting weekends not to be trading days")
+	}
+}
+
+func TestDay(t *testing.T) {
+	day := Day(time.Date(2021, time.March, 5, 15, 30, 0, 0, time.UTC))
+	if !day.Equal(btutil.Date(2021, 3, 5)) {
+		t.Errorf("Unexpected day %s", day)
+	}
+}
+
+func TestTradingDays(t *testing.T) {
+	start := time.Date(2021, time.March, 4, 12, 0, 0, 0, time.UTC)
+	end := btutil.Date(2021, 3, 9)
+	days := TradingDays(NewWeekdayCalendar(), start, end)
+	expected := []time.Time{
+		btutil.Date(2021, 3, 4),
+		btutil.Date(2021, 3, 5),
+		btutil.Date(2021, 3, 8),
+		btutil.Date(2021, 3, 9),
+	}
+	if len(days) != len(expected) {
+		t.Fatalf("Expecting %d trading days, got %d", len(expected), len(days))
+	}
+	for i := range expected {
+		if !days[i].Equal(expected[i]) {
+			t.Errorf("Unexpected trading day %s", days[i])
+		}
+	}
+}
+
+func TestMissingTradingDays(t *testing.T) {
+	cal := NewWeekdayCalendar()
+	if len(MissingTradingDays(cal, nil)) != 0 {
+		t.Error("Expecting no missing days for no observations")
+	}
+
+	times := []time.Time{
+		btutil.Date(2021, 3, 9),
+		time.Date(2021, time.March, 1, 16, 0, 0, 0, time.UTC),
+		btutil.Date(2021, 3, 2),
+		btutil.Date(2021, 3, 5),
+		btutil.Date(2021, 3, 6), // a Saturday observation is ignored
+	}
+	missing := MissingTradingDays(cal, times)
+	expected := []time.Time{
+		btutil.Date(2021, 3, 3),
+		btutil.Date(2021, 3, 4),
+		btutil.Date(2021, 3, 8),
+	}
+	if len(missing) != len(expected) {
+		t.Fatalf("Expecting %d missing days, got %d - %v", len(expected), len(missing), missing)
+	}
+	for i := range expected {
+		if !missing[i].Equal(expected[i]) {
+			t.Errorf("Unexpected missing day %s", missing[i])
+		}
+	}
+}
+
+func TestAnnualisationFactor(t *testing.T) {
+	cal := NewWeekdayCalendar()
+	if days := TradingDaysInYear(cal, 2021); days != 261 {
+		t.Errorf("Expecting 261 weekdays in 2021, got %d", days)
+	}
+	factor := AnnualisationFactor(cal, btutil.Date(2020, 6, 1), btutil.Date(2021, 6, 1))
+	if factor != 261.5 { // 262 weekdays in 2020
+		t.Errorf("Unexpected annualisation factor %0.2f", factor)
+	}
+	if AnnualisationFactor(cal, btutil.Date(2021, 6, 1), btutil.Date(2020, 6, 1)) != 0 {
+		t.Error("Expecting a zero factor for an empty range")
+	}
+}
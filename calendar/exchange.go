@@ -0,0 +1,227 @@
+package calendar
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TimeOfDay is a time within a trading day.
+type TimeOfDay struct {
+	Hour   int
+	Minute int
+}
+
+// NewTimeOfDay returns a new instance of TimeOfDay.
+func NewTimeOfDay(hour int, minute int) (TimeOfDay, error) {
+	if hour < 0 || hour > 24 || minute < 0 || minute > 59 || (hour == 24 && minute != 0) {
+		return TimeOfDay{}, fmt.Errorf("'%02d:%02d' is not a valid time of day", hour, minute)
+	}
+	return TimeOfDay{Hour: hour, Minute: minute}, nil
+}
+
+// on returns this time of day for some date in a given location.
+func (t TimeOfDay) on(day time.Time, location *time.Location) time.Time {
+	year, month, date := day.Date()
+	return time.Date(year, month, date, t.Hour, t.Minute, 0, 0, location)
+}
+
+// earlyClose pairs a holiday rule with the time the session closes early.
+type earlyClose struct {
+	rule  IHolidayRule
+	close TimeOfDay
+}
+
+// Calendar defines the trading days and sessions for an exchange.
+// Trading days are determined from the calendar date of the times
+// passed in, whereas sessions are resolved in the exchange time zone.
+type Calendar struct {
+	name        string
+	location    *time.Location
+	weekend     map[time.Weekday]bool
+	open        TimeOfDay
+	close       TimeOfDay
+	holidays    []IHolidayRule
+	earlyCloses []earlyClose
+
+	mutex sync.Mutex
+	cache map[int]map[time.Time]bool
+}
+
+// NewCalendar returns a new exchange calendar with a Saturday and
+// Sunday weekend and a session running for the whole day.
+func NewCalendar(name string, location *time.Location) (*Calendar, error) {
+	if location == nil {
+		return nil, errors.New("an exchange calendar requires a location")
+	}
+	cal := Calendar{
+		name:     name,
+		location: location,
+		weekend:  map[time.Weekday]bool{time.Saturday: true, time.Sunday: true},
+		open:     TimeOfDay{Hour: 0, Minute: 0},
+		close:    TimeOfDay{Hour: 24, Minute: 0},
+	}
+	return &cal, nil
+}
+
+// GetName returns the calendar name.
+func (c *Calendar) GetName() string {
+	return c.name
+}
+
+// GetLocation returns the exchange time zone.
+func (c *Calendar) GetLocation() *time.Location {
+	return c.location
+}
+
+// SetWeekend sets the days of the week on which the exchange is closed.
+func (c *Calendar) SetWeekend(days ...time.Weekday) {
+	c.weekend = make(map[time.Weekday]bool)
+	for _, day := range days {
+		c.weekend[day] = true
+	}
+}
+
+// SetSession sets the regular session open and close times.
+func (c *Calendar) SetSession(open TimeOfDay, close TimeOfDay) error {
+	if open.Hour*60+open.Minute >= close.Hour*60+close.Minute {
+		return errors.New("the session must open before it closes")
+	}
+	c.open, c.close = open, close
+	return nil
+}
+
+// AddHoliday adds a holiday rule to the calendar.
+func (c *Calendar) AddHoliday(rule IHolidayRule) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.holidays = append(c.holidays, rule)
+	c.cache = nil
+}
+
+// AddEarlyClose adds a half day on which the session closes early.
+func (c *Calendar) AddEarlyClose(rule IHolidayRule, close TimeOfDay) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.earlyCloses = append(c.earlyCloses, earlyClose{rule: rule, close: close})
+}
+
+// ruleApplies returns true if some rule falls on the given day. Rules are
+// checked for adjacent years as observed dates can cross the year end.
+func ruleApplies(rule IHolidayRule, day time.Time) bool {
+	for year := day.Year() - 1; year <= day.Year()+1; year++ {
+		date, ok := rule.Date(year)
+		if ok && date.Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// holidaysForYear returns the set of holidays in some year.
+func (c *Calendar) holidaysForYear(year int) map[time.Time]bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.cache == nil {
+		c.cache = make(map[int]map[time.Time]bool)
+	}
+	holidays, ok := c.cache[year]
+	if ok {
+		return holidays
+	}
+
+	holidays = make(map[time.Time]bool)
+	for _, rule := range c.holidays {
+		for y := year - 1; y <= year+1; y++ {
+			date, ok := rule.Date(y)
+			if ok && date.Year() == year {
+				holidays[date] = true
+			}
+		}
+	}
+	c.cache[year] = holidays
+	return holidays
+}
+
+// IsWeekend returns true if the exchange is closed for the weekend.
+func (c *Calendar) IsWeekend(t time.Time) bool {
+	return c.weekend[t.Weekday()]
+}
+
+// IsHoliday returns true if the day is an exchange holiday.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	day := dateKey(t)
+	return c.holidaysForYear(day.Year())[day]
+}
+
+// IsTradingDay returns true if the exchange is open on this day.
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	return !c.IsWeekend(t) && !c.IsHoliday(t)
+}
+
+// IsEarlyClose returns true if the day is a half day.
+func (c *Calendar) IsEarlyClose(t time.Time) bool {
+	_, ok := c.earlyCloseTime(t)
+	return ok && c.IsTradingDay(t)
+}
+
+func (c *Calendar) earlyCloseTime(t time.Time) (TimeOfDay, bool) {
+	day := dateKey(t)
+	c.mutex.Lock()
+	earlyCloses := c.earlyCloses
+	c.mutex.Unlock()
+	for _, early := range earlyCloses {
+		if ruleApplies(early.rule, day) {
+			return early.close, true
+		}
+	}
+	return TimeOfDay{}, false
+}
+
+// GetSession returns the session open and close times for some day
+// in the exchange time zone, along with false if it is not a trading day.
+func (c *Calendar) GetSession(t time.Time) (time.Time, time.Time, bool) {
+	if !c.IsTradingDay(t) {
+		return time.Time{}, time.Time{}, false
+	}
+
+	close := c.close
+	if earlyClose, ok := c.earlyCloseTime(t); ok {
+		close = earlyClose
+	}
+	return c.open.on(t, c.location), close.on(t, c.location), true
+}
+
+// IsOpen returns true if the exchange is open at some instant.
+func (c *Calendar) IsOpen(t time.Time) bool {
+	local := t.In(c.location)
+	open, close, ok := c.GetSession(local)
+	if !ok {
+		return false
+	}
+	return !local.Before(open) && local.Before(close)
+}
+
+// NextTradingDay returns the first trading day after some day.
+func (c *Calendar) NextTradingDay(t time.Time) (time.Time, error) {
+	return c.findTradingDay(t, 1)
+}
+
+// PreviousTradingDay returns the last trading day before some day.
+func (c *Calendar) PreviousTradingDay(t time.Time) (time.Time, error) {
+	return c.findTradingDay(t, -1)
+}
+
+// findTradingDay steps through days in some direction until a trading
+// day is found, giving up after ten years of closed days.
+func (c *Calendar) findTradingDay(t time.Time, step int) (time.Time, error) {
+	day := Day(t)
+	for i := 0; i < 3660; i++ {
+		day = day.AddDate(0, 0, step)
+		if c.IsTradingDay(day) {
+			return day, nil
+		}
+	}
+	return day, fmt.Errorf("'%s' has no trading days near %s", c.name, t.Format("2006-01-02"))
+}
@@ -0,0 +1,188 @@
+package calendar
+
+import (
+	"gobacktrader/btutil"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestCalendar(t *testing.T) *Calendar {
+	location, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatalf("Error in time.LoadLocation - %s", err)
+	}
+	cal, err := NewCalendar("ASX", location)
+	if err != nil {
+		t.Fatalf("Error in NewCalendar - %s", err)
+	}
+
+	open, _ := NewTimeOfDay(10, 0)
+	close, _ := NewTimeOfDay(16, 0)
+	if err := cal.SetSession(open, close); err != nil {
+		t.Fatalf("Error in SetSession - %s", err)
+	}
+	cal.AddHoliday(NewFixedDate(time.January, 1, NextWeekday))
+	cal.AddHoliday(NewFixedDate(time.January, 26, NextWeekday))
+	cal.AddHoliday(NewEasterOffset(-2))
+	cal.AddHoliday(NewEasterOffset(1))
+	cal.AddHoliday(NewFixedDate(time.December, 25, NextWeekday))
+	earlyClose, _ := NewTimeOfDay(14, 10)
+	cal.AddEarlyClose(NewFixedDate(time.December, 24, NoObservance), earlyClose)
+	return cal
+}
+
+func TestNewCalendar(t *testing.T) {
+	_, err := NewCalendar("XXX", nil)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "an exchange calendar requires a location" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	cal := newTestCalendar(t)
+	if cal.GetName() != "ASX" {
+		t.Error("Unexpected calendar name")
+	}
+	if cal.GetLocation().String() != "Australia/Sydney" {
+		t.Error("Unexpected calendar location")
+	}
+
+	_, err = NewTimeOfDay(25, 0)
+	errStr = btutil.GetErrorString(err)
+	if errStr != "'25:00' is not a valid time of day" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	open, _ := NewTimeOfDay(16, 0)
+	close, _ := NewTimeOfDay(10, 0)
+	errStr = btutil.GetErrorString(cal.SetSession(open, close))
+	if errStr != "the session must open before it closes" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+}
+
+func TestCalendarTradingDays(t *testing.T) {
+	cal := newTestCalendar(t)
+	tests := []struct {
+		day     time.Time
+		trading bool
+	}{
+		{btutil.Date(2021, 1, 1), false},  // New Year's Day
+		{btutil.Date(2021, 1, 4), true},   // a regular Monday
+		{btutil.Date(2021, 1, 26), false}, // Australia Day
+		{btutil.Date(2021, 4, 2), false},  // Good Friday
+		{btutil.Date(2021, 4, 5), false},  // Easter Monday
+		{btutil.Date(2021, 12, 25), false},
+		{btutil.Date(2021, 12, 27), false}, // Christmas observed
+		{btutil.Date(2021, 12, 24), true},  // a half day
+	}
+	for _, test := range tests {
+		if cal.IsTradingDay(test.day) != test.trading {
+			t.Errorf("Unexpected IsTradingDay for %s", test.day.Format("2006-01-02"))
+		}
+	}
+
+	if !cal.IsHoliday(btutil.Date(2021, 12, 27)) {
+		t.Error("Expecting an observed holiday")
+	}
+	if !cal.IsWeekend(btutil.Date(2021, 12, 25)) {
+		t.Error("Expecting a weekend")
+	}
+
+	// a Friday and Saturday weekend
+	cal.SetWeekend(time.Friday, time.Saturday)
+	if cal.IsTradingDay(btutil.Date(2021, 3, 5)) {
+		t.Error("Expecting Friday to be a weekend")
+	}
+	if !cal.IsTradingDay(btutil.Date(2021, 3, 7)) {
+		t.Error("Expecting Sunday to be a trading day")
+	}
+}
+
+func TestCalendarSessions(t *testing.T) {
+	cal := newTestCalendar(t)
+	sydney := cal.GetLocation()
+
+	open, close, ok := cal.GetSession(btutil.Date(2021, 3, 1))
+	if !ok {
+		t.Fatal("Expecting a session")
+	}
+	if !open.Equal(time.Date(2021, time.March, 1, 10, 0, 0, 0, sydney)) {
+		t.Errorf("Unexpected session open %s", open)
+	}
+	if !close.Equal(time.Date(2021, time.March, 1, 16, 0, 0, 0, sydney)) {
+		t.Errorf("Unexpected session close %s", close)
+	}
+
+	// half days close early
+	if !cal.IsEarlyClose(btutil.Date(2021, 12, 24)) {
+		t.Error("Expecting an early close")
+	}
+	_, close, _ = cal.GetSession(btutil.Date(2021, 12, 24))
+	if !close.Equal(time.Date(2021, time.December, 24, 14, 10, 0, 0, sydney)) {
+		t.Errorf("Unexpected early close %s", close)
+	}
+
+	if _, _, ok := cal.GetSession(btutil.Date(2021, 1, 1)); ok {
+		t.Error("Expecting no session on a holiday")
+	}
+
+	// sessions are resolved in the exchange time zone,
+	// 23:30 UTC on the 1st is 10:30 on the 2nd in Sydney
+	if !cal.IsOpen(time.Date(2021, time.March, 1, 23, 30, 0, 0, time.UTC)) {
+		t.Error("Expecting the exchange to be open")
+	}
+	if cal.IsOpen(time.Date(2021, time.March, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expecting the exchange to be closed overnight")
+	}
+	if cal.IsOpen(time.Date(2021, time.March, 2, 16, 0, 0, 0, sydney)) {
+		t.Error("Expecting the exchange to be closed at the session close")
+	}
+}
+
+func TestCalendarConcurrentEarlyClose(t *testing.T) {
+	cal := newTestCalendar(t)
+	earlyClose, _ := NewTimeOfDay(12, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(day int) {
+			defer wg.Done()
+			cal.AddEarlyClose(NewFixedDate(time.December, day, NoObservance), earlyClose)
+		}(28 + i)
+		go func() {
+			defer wg.Done()
+			cal.IsEarlyClose(btutil.Date(2021, 12, 24))
+		}()
+	}
+	wg.Wait()
+
+	if !cal.IsEarlyClose(btutil.Date(2021, 12, 29)) {
+		t.Error("Expecting an early close added concurrently")
+	}
+}
+
+func TestNextPreviousTradingDay(t *testing.T) {
+	cal := newTestCalendar(t)
+	next, err := cal.NextTradingDay(btutil.Date(2021, 12, 24))
+	if err != nil {
+		t.Fatalf("Error in NextTradingDay - %s", err)
+	}
+	if !next.Equal(btutil.Date(2021, 12, 28)) { // Christmas observed on the 27th
+		t.Errorf("Unexpected next trading day %s", next)
+	}
+
+	previous, err := cal.PreviousTradingDay(btutil.Date(2021, 4, 6))
+	if err != nil {
+		t.Fatalf("Error in PreviousTradingDay - %s", err)
+	}
+	if !previous.Equal(btutil.Date(2021, 4, 1)) {
+		t.Errorf("Unexpected previous trading day %s", previous)
+	}
+
+	cal.SetWeekend(time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday)
+	if _, err := cal.NextTradingDay(btutil.Date(2021, 1, 1)); err == nil {
+		t.Error("Expecting an error where there are no trading days")
+	}
+}
@@ -0,0 +1,179 @@
+package calendar
+
+import (
+	"errors"
+	"time"
+)
+
+// IHolidayRule defines the interface for holiday rules.
+// Date returns the holiday for some year along with false
+// if the holiday does not occur that year.
+type IHolidayRule interface {
+	Date(year int) (time.Time, bool)
+}
+
+// Observance determines how a holiday falling on a weekend is observed.
+type Observance int
+
+// The supported observance rules.
+const (
+	// NoObservance leaves weekend holidays unobserved.
+	NoObservance Observance = iota
+	// NearestWeekday moves Saturday holidays to Friday and Sunday holidays to Monday.
+	NearestWeekday
+	// NextWeekday moves weekend holidays to the following Monday.
+	NextWeekday
+	// SundayToMonday moves Sunday holidays to Monday and leaves Saturday holidays unobserved.
+	SundayToMonday
+)
+
+// observe shifts a holiday date according to some observance rule.
+func observe(date time.Time, observance Observance) time.Time {
+	switch observance {
+	case NearestWeekday:
+		switch date.Weekday() {
+		case time.Saturday:
+			return date.AddDate(0, 0, -1)
+		case time.Sunday:
+			return date.AddDate(0, 0, 1)
+		}
+	case NextWeekday:
+		switch date.Weekday() {
+		case time.Saturday:
+			return date.AddDate(0, 0, 2)
+		case time.Sunday:
+			return date.AddDate(0, 0, 1)
+		}
+	case SundayToMonday:
+		if date.Weekday() == time.Sunday {
+			return date.AddDate(0, 0, 1)
+		}
+	}
+	return date
+}
+
+// FixedDate is a holiday on the same day each year, e.g. 25 December.
+type FixedDate struct {
+	month      time.Month
+	day        int
+	observance Observance
+}
+
+// NewFixedDate returns a new instance of FixedDate.
+func NewFixedDate(month time.Month, day int, observance Observance) FixedDate {
+	return FixedDate{month: month, day: day, observance: observance}
+}
+
+// Date returns the observed holiday for some year.
+func (r FixedDate) Date(year int) (time.Time, bool) {
+	date := time.Date(year, r.month, r.day, 0, 0, 0, 0, time.UTC)
+	return observe(date, r.observance), true
+}
+
+// NthWeekday is a holiday on the nth weekday of some month,
+// e.g. the third Monday of January. An n of -1 gives the last
+// weekday of the month, e.g. the last Monday of May.
+type NthWeekday struct {
+	month   time.Month
+	weekday time.Weekday
+	n       int
+}
+
+// NewNthWeekday returns a new instance of NthWeekday.
+func NewNthWeekday(month time.Month, weekday time.Weekday, n int) (NthWeekday, error) {
+	if n == 0 || n < -1 || n > 5 {
+		return NthWeekday{}, errors.New("n must be between 1 and 5, or -1 for the last weekday")
+	}
+	return NthWeekday{month: month, weekday: weekday, n: n}, nil
+}
+
+// Date returns the holiday for some year. There is no holiday
+// where the month does not have an nth weekday.
+func (r NthWeekday) Date(year int) (time.Time, bool) {
+	if r.n == -1 {
+		lastDay := time.Date(year, r.month+1, 0, 0, 0, 0, 0, time.UTC)
+		offset := (int(lastDay.Weekday()) - int(r.weekday) + 7) % 7
+		return lastDay.AddDate(0, 0, -offset), true
+	}
+
+	firstDay := time.Date(year, r.month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(r.weekday) - int(firstDay.Weekday()) + 7) % 7
+	date := firstDay.AddDate(0, 0, offset+7*(r.n-1))
+	if date.Month() != r.month {
+		return date, false
+	}
+	return date, true
+}
+
+// EasterOffset is a holiday some number of days from Easter Sunday,
+// e.g. Good Friday is two days before.
+type EasterOffset struct {
+	days int
+}
+
+// NewEasterOffset returns a new instance of EasterOffset.
+func NewEasterOffset(days int) EasterOffset {
+	return EasterOffset{days: days}
+}
+
+// Date returns the holiday for some year.
+func (r EasterOffset) Date(year int) (time.Time, bool) {
+	return Easter(year).AddDate(0, 0, r.days), true
+}
+
+// Easter returns the date of Easter Sunday for some year
+// using the anonymous Gregorian algorithm.
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// SpecificDate is a one off holiday, such as an unscheduled market closure.
+type SpecificDate struct {
+	date time.Time
+}
+
+// NewSpecificDate returns a new instance of SpecificDate.
+func NewSpecificDate(date time.Time) SpecificDate {
+	year, month, day := date.Date()
+	return SpecificDate{date: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Date returns the holiday if it falls in some year.
+func (r SpecificDate) Date(year int) (time.Time, bool) {
+	return r.date, r.date.Year() == year
+}
+
+// YearRange limits some holiday rule to a range of years.
+type YearRange struct {
+	rule     IHolidayRule
+	fromYear int
+	toYear   int
+}
+
+// NewYearRange returns a new instance of YearRange. A zero
+// fromYear or toYear leaves that end of the range open.
+func NewYearRange(rule IHolidayRule, fromYear int, toYear int) YearRange {
+	return YearRange{rule: rule, fromYear: fromYear, toYear: toYear}
+}
+
+// Date returns the holiday where the year is within range.
+func (r YearRange) Date(year int) (time.Time, bool) {
+	if (r.fromYear != 0 && year < r.fromYear) || (r.toYear != 0 && year > r.toYear) {
+		return time.Time{}, false
+	}
+	return r.rule.Date(year)
+}
@@ -0,0 +1,95 @@
+package calendar
+
+import (
+	"gobacktrader/btutil"
+	"testing"
+	"time"
+)
+
+func checkRuleDate(t *testing.T, rule IHolidayRule, year int, expected time.Time) {
+	t.Helper()
+	date, ok := rule.Date(year)
+	if !ok {
+		t.Fatalf("Expecting a holiday in %d", year)
+	}
+	if !date.Equal(expected) {
+		t.Errorf("Unexpected holiday - wanted %s, got %s", expected, date)
+	}
+}
+
+func TestFixedDate(t *testing.T) {
+	// Christmas 2021 fell on a Saturday and 2022 on a Sunday
+	checkRuleDate(t, NewFixedDate(time.December, 25, NoObservance), 2021, btutil.Date(2021, 12, 25))
+	checkRuleDate(t, NewFixedDate(time.December, 25, NearestWeekday), 2021, btutil.Date(2021, 12, 24))
+	checkRuleDate(t, NewFixedDate(time.December, 25, NearestWeekday), 2022, btutil.Date(2022, 12, 26))
+	checkRuleDate(t, NewFixedDate(time.December, 25, NextWeekday), 2021, btutil.Date(2021, 12, 27))
+	checkRuleDate(t, NewFixedDate(time.December, 25, NextWeekday), 2022, btutil.Date(2022, 12, 26))
+	checkRuleDate(t, NewFixedDate(time.December, 25, SundayToMonday), 2021, btutil.Date(2021, 12, 25))
+	checkRuleDate(t, NewFixedDate(time.December, 25, SundayToMonday), 2022, btutil.Date(2022, 12, 26))
+}
+
+func TestNthWeekday(t *testing.T) {
+	_, err := NewNthWeekday(time.January, time.Monday, 0)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "n must be between 1 and 5, or -1 for the last weekday" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	// the third Monday of January 2021
+	mlk, _ := NewNthWeekday(time.January, time.Monday, 3)
+	checkRuleDate(t, mlk, 2021, btutil.Date(2021, 1, 18))
+
+	// the last Monday of May 2021
+	memorial, _ := NewNthWeekday(time.May, time.Monday, -1)
+	checkRuleDate(t, memorial, 2021, btutil.Date(2021, 5, 31))
+
+	// the first Monday of September 2021
+	labor, _ := NewNthWeekday(time.September, time.Monday, 1)
+	checkRuleDate(t, labor, 2021, btutil.Date(2021, 9, 6))
+
+	// February 2021 has no fifth Monday
+	fifth, _ := NewNthWeekday(time.February, time.Monday, 5)
+	if _, ok := fifth.Date(2021); ok {
+		t.Error("Expecting no fifth Monday in February 2021")
+	}
+}
+
+func TestEaster(t *testing.T) {
+	expected := map[int]time.Time{
+		2019: btutil.Date(2019, 4, 21),
+		2020: btutil.Date(2020, 4, 12),
+		2021: btutil.Date(2021, 4, 4),
+		2022: btutil.Date(2022, 4, 17),
+		2038: btutil.Date(2038, 4, 25),
+	}
+	for year, easter := range expected {
+		if !Easter(year).Equal(easter) {
+			t.Errorf("Unexpected Easter for %d - wanted %s, got %s", year, easter, Easter(year))
+		}
+	}
+
+	goodFriday := NewEasterOffset(-2)
+	checkRuleDate(t, goodFriday, 2021, btutil.Date(2021, 4, 2))
+	easterMonday := NewEasterOffset(1)
+	checkRuleDate(t, easterMonday, 2021, btutil.Date(2021, 4, 5))
+}
+
+func TestSpecificDateAndYearRange(t *testing.T) {
+	rule := NewSpecificDate(time.Date(2012, time.October, 29, 9, 30, 0, 0, time.UTC))
+	checkRuleDate(t, rule, 2012, btutil.Date(2012, 10, 29))
+	if _, ok := rule.Date(2013); ok {
+		t.Error("Expecting no holiday in 2013")
+	}
+
+	juneteenth := NewYearRange(NewFixedDate(time.June, 19, NearestWeekday), 2022, 0)
+	if _, ok := juneteenth.Date(2021); ok {
+		t.Error("Expecting no holiday before the range starts")
+	}
+	checkRuleDate(t, juneteenth, 2022, btutil.Date(2022, 6, 20))
+
+	bounded := NewYearRange(NewFixedDate(time.June, 1, NoObservance), 0, 2020)
+	if _, ok := bounded.Date(2021); ok {
+		t.Error("Expecting no holiday after the range ends")
+	}
+	checkRuleDate(t, bounded, 1999, btutil.Date(1999, 6, 1))
+}
@@ -0,0 +1,217 @@
+package calendar
+
+import (
+	"encoding/json"
+	"fmt"
+	"gobacktrader/btutil"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Definition describes an exchange calendar in a form that can be
+// loaded from a local json file. For example:
+//
+//	{
+//	  "name": "NYSE",
+//	  "timezone": "America/New_York",
+//	  "open": "09:30",
+//	  "close": "16:00",
+//	  "holidays": [
+//	    {"name": "New Year's Day", "type": "fixed", "month": 1, "day": 1, "observance": "sunday_to_monday"},
+//	    {"name": "Martin Luther King Jr. Day", "type": "nth_weekday", "month": 1, "weekday": "Monday", "n": 3},
+//	    {"name": "Good Friday", "type": "easter", "offset": -2},
+//	    {"name": "Juneteenth", "type": "fixed", "month": 6, "day": 19, "observance": "nearest_weekday", "from_year": 2022}
+//	  ],
+//	  "early_closes": [
+//	    {"name": "Christmas Eve", "type": "fixed", "month": 12, "day": 24, "close": "13:00"}
+//	  ]
+//	}
+//
+// The weekend defaults to Saturday and Sunday and the session to the whole day.
+type Definition struct {
+	Name        string           `json:"name"`
+	Timezone    string           `json:"timezone"`
+	Weekend     []string         `json:"weekend"`
+	Open        string           `json:"open"`
+	Close       string           `json:"close"`
+	Holidays    []RuleDefinition `json:"holidays"`
+	EarlyCloses []RuleDefinition `json:"early_closes"`
+}
+
+// RuleDefinition describes a single holiday or early close rule.
+// Type is one of 'fixed', 'nth_weekday', 'easter' or 'date'.
+type RuleDefinition struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Month      int    `json:"month"`
+	Day        int    `json:"day"`
+	Weekday    string `json:"weekday"`
+	N          int    `json:"n"`
+	Offset     int    `json:"offset"`
+	Date       string `json:"date"`
+	Observance string `json:"observance"`
+	FromYear   int    `json:"from_year"`
+	ToYear     int    `json:"to_year"`
+	Close      string `json:"close"`
+}
+
+// LoadCalendar reads an exchange calendar definition from a json file.
+func LoadCalendar(filePath string) (*Calendar, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var definition Definition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid calendar definition - %s", filePath, err)
+	}
+	return definition.Build()
+}
+
+// Build returns the exchange calendar for this definition.
+func (d Definition) Build() (*Calendar, error) {
+	location := time.UTC
+	if d.Timezone != "" {
+		var err error
+		location, err = time.LoadLocation(d.Timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cal, err := NewCalendar(d.Name, location)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Weekend != nil {
+		var weekend []time.Weekday
+		for _, name := range d.Weekend {
+			weekday, err := parseWeekday(name)
+			if err != nil {
+				return nil, err
+			}
+			weekend = append(weekend, weekday)
+		}
+		cal.SetWeekend(weekend...)
+	}
+
+	if d.Open != "" || d.Close != "" {
+		open, err1 := parseTimeOfDay(d.Open)
+		close, err2 := parseTimeOfDay(d.Close)
+		if err := btutil.AnyValidError(err1, err2); err != nil {
+			return nil, err
+		}
+		if err := cal.SetSession(open, close); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ruleDefinition := range d.Holidays {
+		rule, err := ruleDefinition.Build()
+		if err != nil {
+			return nil, err
+		}
+		cal.AddHoliday(rule)
+	}
+
+	for _, ruleDefinition := range d.EarlyCloses {
+		rule, err := ruleDefinition.Build()
+		if err != nil {
+			return nil, err
+		}
+		close, err := parseTimeOfDay(ruleDefinition.Close)
+		if err != nil {
+			return nil, err
+		}
+		cal.AddEarlyClose(rule, close)
+	}
+
+	return cal, nil
+}
+
+// Build returns the holiday rule for this definition.
+func (d RuleDefinition) Build() (IHolidayRule, error) {
+	var rule IHolidayRule
+	switch strings.ToLower(strings.TrimSpace(d.Type)) {
+	case "fixed":
+		if err := validateFixedDate(d); err != nil {
+			return nil, err
+		}
+		observance, err := parseObservance(d.Observance)
+		if err != nil {
+			return nil, err
+		}
+		rule = NewFixedDate(time.Month(d.Month), d.Day, observance)
+	case "nth_weekday":
+		weekday, err := parseWeekday(d.Weekday)
+		if err != nil {
+			return nil, err
+		}
+		rule, err = NewNthWeekday(time.Month(d.Month), weekday, d.N)
+		if err != nil {
+			return nil, err
+		}
+	case "easter":
+		rule = NewEasterOffset(d.Offset)
+	case "date":
+		date, err := time.Parse("2006-01-02", d.Date)
+		if err != nil {
+			return nil, err
+		}
+		rule = NewSpecificDate(date)
+	default:
+		return nil, fmt.Errorf("'%s' is not a valid holiday rule type", d.Type)
+	}
+
+	if d.FromYear != 0 || d.ToYear != 0 {
+		rule = NewYearRange(rule, d.FromYear, d.ToYear)
+	}
+	return rule, nil
+}
+
+// validateFixedDate checks that a fixed holiday rule has a month and a
+// day in that month. The 29th of February is allowed for leap years.
+func validateFixedDate(d RuleDefinition) error {
+	if d.Month < 1 || d.Month > 12 {
+		return fmt.Errorf("fixed holiday rule '%s' has an invalid month %d", d.Name, d.Month)
+	}
+	daysInMonth := time.Date(2000, time.Month(d.Month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if d.Day < 1 || d.Day > daysInMonth {
+		return fmt.Errorf("fixed holiday rule '%s' has an invalid day %d for month %d", d.Name, d.Day, d.Month)
+	}
+	return nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(strings.TrimSpace(name), day.String()) {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("'%s' is not a valid weekday", name)
+}
+
+func parseObservance(name string) (Observance, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "none":
+		return NoObservance, nil
+	case "nearest_weekday":
+		return NearestWeekday, nil
+	case "next_weekday":
+		return NextWeekday, nil
+	case "sunday_to_monday":
+		return SundayToMonday, nil
+	}
+	return NoObservance, fmt.Errorf("'%s' is not a valid observance", name)
+}
+
+func parseTimeOfDay(s string) (TimeOfDay, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil {
+		return TimeOfDay{}, fmt.Errorf("'%s' is not a valid time of day", s)
+	}
+	return NewTimeOfDay(hour, minute)
+}
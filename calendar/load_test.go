@@ -0,0 +1,102 @@
+package calendar
+
+import (
+	"gobacktrader/btutil"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadCalendar(t *testing.T) {
+	cal, err := LoadCalendar(filepath.Join("testdata", "nyse.json"))
+	if err != nil {
+		t.Fatalf("Error in LoadCalendar - %s", err)
+	}
+	if cal.GetName() != "NYSE" {
+		t.Error("Unexpected calendar name")
+	}
+
+	// NYSE had 252 trading days in 2021 and 251 in 2022
+	if days := TradingDaysInYear(cal, 2021); days != 252 {
+		t.Errorf("Expecting 252 trading days in 2021, got %d", days)
+	}
+	if days := TradingDaysInYear(cal, 2022); days != 251 {
+		t.Errorf("Expecting 251 trading days in 2022, got %d", days)
+	}
+
+	// New Year's Day 2022 fell on a Saturday and was not observed
+	if !cal.IsTradingDay(btutil.Date(2021, 12, 31)) {
+		t.Error("Expecting 31 December 2021 to be a trading day")
+	}
+	if cal.IsTradingDay(btutil.Date(2012, 10, 29)) {
+		t.Error("Expecting a closure for Hurricane Sandy")
+	}
+
+	open, close, ok := cal.GetSession(btutil.Date(2021, 12, 23))
+	if !ok {
+		t.Fatal("Expecting a session")
+	}
+	newYork := cal.GetLocation()
+	if !open.Equal(time.Date(2021, time.December, 23, 9, 30, 0, 0, newYork)) {
+		t.Errorf("Unexpected session open %s", open)
+	}
+	if !close.Equal(time.Date(2021, time.December, 23, 16, 0, 0, 0, newYork)) {
+		t.Errorf("Unexpected session close %s", close)
+	}
+	if !cal.IsEarlyClose(btutil.Date(2020, 12, 24)) {
+		t.Error("Expecting an early close on Christmas Eve 2020")
+	}
+}
+
+func TestLoadCalendarErrors(t *testing.T) {
+	if _, err := LoadCalendar("does_not_exist.json"); err == nil {
+		t.Error("Expecting an error for a missing file")
+	}
+
+	dir, err := ioutil.TempDir("", "calendar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		contents string
+		errStr   string
+	}{
+		{`{"holidays": [{"type": "lunar"}]}`, "'lunar' is not a valid holiday rule type"},
+		{`{"weekend": ["Funday"]}`, "'Funday' is not a valid weekday"},
+		{`{"holidays": [{"type": "fixed", "month": 1, "day": 1, "observance": "never"}]}`, "'never' is not a valid observance"},
+		{`{"holidays": [{"name": "Missing", "type": "fixed", "day": 1}]}`, "fixed holiday rule 'Missing' has an invalid month 0"},
+		{`{"holidays": [{"name": "Smarch", "type": "fixed", "month": 13, "day": 1}]}`, "fixed holiday rule 'Smarch' has an invalid month 13"},
+		{`{"holidays": [{"name": "No Day", "type": "fixed", "month": 1}]}`, "fixed holiday rule 'No Day' has an invalid day 0 for month 1"},
+		{`{"early_closes": [{"name": "Leap", "type": "fixed", "month": 2, "day": 30, "close": "13:00"}]}`, "fixed holiday rule 'Leap' has an invalid day 30 for month 2"},
+		{`{"open": "nine", "close": "16:00"}`, "'nine' is not a valid time of day"},
+		{`{"early_closes": [{"type": "easter", "offset": -3, "close": "25:00"}]}`, "'25:00' is not a valid time of day"},
+	}
+	for i, test := range tests {
+		filePath := filepath.Join(dir, "calendar.json")
+		if err := ioutil.WriteFile(filePath, []byte(test.contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadCalendar(filePath)
+		if btutil.GetErrorString(err) != test.errStr {
+			t.Errorf("Test %d: unexpected error string '%s'", i, btutil.GetErrorString(err))
+		}
+	}
+}
+
+func TestDefinitionWeekend(t *testing.T) {
+	definition := Definition{Name: "XXX", Weekend: []string{"friday", "Saturday"}}
+	cal, err := definition.Build()
+	if err != nil {
+		t.Fatalf("Error in Build - %s", err)
+	}
+	if cal.GetLocation() != time.UTC {
+		t.Error("Expecting UTC by default")
+	}
+	if cal.IsTradingDay(btutil.Date(2021, 3, 5)) || !cal.IsTradingDay(btutil.Date(2021, 3, 7)) {
+		t.Error("Expecting a Friday and Saturday weekend")
+	}
+}
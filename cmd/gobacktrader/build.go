@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/broker"
+	"gobacktrader/btutil"
+	"gobacktrader/compliance"
+	"gobacktrader/datasources"
+	"gobacktrader/resample"
+)
+
+// environment holds what has been built from a config
+// so that strategies can look up assets and portfolios.
+type environment struct {
+	backtest   *backtest.Backtest
+	assets     map[string]asset.IAssetReadOnly
+	portfolios map[string]*asset.Portfolio
+	sources    []*datasources.CsvSource
+}
+
+// getAsset returns an asset from the config by ticker.
+func (env *environment) getAsset(ticker string) (asset.IAssetReadOnly, error) {
+	a, ok := env.assets[btutil.CleanString(ticker)]
+	if !ok {
+		return nil, fmt.Errorf("asset ticker '%s' is not in the config", ticker)
+	}
+	return a, nil
+}
+
+// getPortfolio returns a portfolio from the config by code.
+func (env *environment) getPortfolio(code string) (*asset.Portfolio, error) {
+	p, ok := env.portfolios[code]
+	if !ok {
+		return nil, fmt.Errorf("portfolio code '%s' is not in the config", code)
+	}
+	return p, nil
+}
+
+// close closes any csv files that are still open.
+func (env *environment) close() {
+	for _, source := range env.sources {
+		source.Close()
+	}
+}
+
+// parseFrequency returns the frequency named in a config,
+// where daily returns nil for every time step.
+func parseFrequency(name string) (resample.Frequency, error) {
+	switch name {
+	case "", "daily":
+		return nil, nil
+	case "weekly":
+		return resample.NewWeekly(), nil
+	case "month_end":
+		return resample.NewMonthEnd(), nil
+	case "quarter_end":
+		return resample.NewQuarterEnd(), nil
+	}
+	return nil, fmt.Errorf("frequency '%s' must be one of daily, weekly, month_end or quarter_end", name)
+}
+
+// buildBacktest builds a backtest from a config, opening
+// the csv files that hold asset prices and FX rates.
+func buildBacktest(config Config) (*environment, error) {
+	bt := backtest.NewBacktest(nil)
+	bt.SetSeed(config.Seed)
+	env := &environment{
+		backtest:   &bt,
+		assets:     make(map[string]asset.IAssetReadOnly),
+		portfolios: make(map[string]*asset.Portfolio),
+	}
+
+	freq, err := parseFrequency(config.Snapshots)
+	if err != nil {
+		return nil, err
+	}
+	if freq != nil {
+		bt.SetSnapshotFrequency(freq)
+	}
+
+	if err := env.addAssets(config.Assets); err != nil {
+		env.close()
+		return nil, err
+	}
+	fxRates, err := env.addFxRates(config.Fx)
+	if err != nil {
+		env.close()
+		return nil, err
+	}
+	for _, portfolioConfig := range config.Portfolios {
+		if err := env.addPortfolio(portfolioConfig, fxRates); err != nil {
+			env.close()
+			return nil, err
+		}
+	}
+	if err := env.addStrategy(config.Strategy); err != nil {
+		env.close()
+		return nil, err
+	}
+	return env, nil
+}
+
+func (env *environment) addAssets(configs []AssetConfig) error {
+	for _, assetConfig := range configs {
+		multiplier := assetConfig.Multiplier
+		if multiplier == 0 {
+			multiplier = 1
+		}
+		a, err := asset.NewAssetWithMultiplier(assetConfig.Ticker, assetConfig.Currency, multiplier)
+		if err != nil {
+			return err
+		}
+		fractional := assetConfig.Fractional == nil || *assetConfig.Fractional
+		rules, err := asset.NewTradingRules(assetConfig.LotSize, assetConfig.MinUnits, assetConfig.MinNotional, fractional)
+		if err != nil {
+			return fmt.Errorf("'%s' %s", a.GetTicker(), err)
+		}
+		a.SetTradingRules(rules)
+		if err := env.backtest.RegisterAsset(a); err != nil {
+			return err
+		}
+		source, err := datasources.NewCsvSource(a, assetConfig.Csv)
+		if err != nil {
+			return err
+		}
+		env.sources = append(env.sources, source)
+		env.backtest.AddEventSource(source)
+		env.assets[a.GetTicker()] = a
+	}
+	return nil
+}
+
+func (env *environment) addFxRates(configs []FxConfig) (*asset.FxRates, error) {
+	fxRates := asset.NewFxRates()
+	for _, fxConfig := range configs {
+		var price asset.Price
+		if fxConfig.Rate != nil {
+			price = asset.Price{Float64: *fxConfig.Rate, Valid: true}
+		}
+		rate, err := asset.NewFxRate(fxConfig.Pair, price)
+		if err != nil {
+			return nil, err
+		}
+		if err := fxRates.Register(rate); err != nil {
+			return nil, err
+		}
+		if fxConfig.Csv != "" {
+			source, err := datasources.NewCsvFxSource(rate, fxConfig.Csv)
+			if err != nil {
+				return nil, err
+			}
+			env.sources = append(env.sources, source)
+			env.backtest.AddEventSource(source)
+		}
+	}
+	return fxRates, nil
+}
+
+func (env *environment) addPortfolio(config PortfolioConfig, fxRates *asset.FxRates) error {
+	p, err := env.backtest.NewPortfolio(config.Code, config.Currency)
+	if err != nil {
+		return err
+	}
+	p.SetFxRates(fxRates)
+
+	executingBroker, err := newBroker(config.Broker)
+	if err != nil {
+		return err
+	}
+	p.SetBroker(executingBroker)
+
+	for currency, amount := range config.Cash {
+		cash, err := p.GetCash(currency)
+		if err != nil {
+			return err
+		}
+		p.Transfer(cash, amount)
+	}
+	for ticker, units := range config.Positions {
+		a, err := env.getAsset(ticker)
+		if err != nil {
+			return err
+		}
+		p.Transfer(a, units)
+	}
+
+	for _, ruleConfig := range config.Compliance {
+		a, err := env.getAsset(ruleConfig.Ticker)
+		if err != nil {
+			return err
+		}
+		var rule asset.IComplianceRule
+		switch ruleConfig.Type {
+		case "unit_limit":
+			rule = compliance.NewUnitLimit(a, ruleConfig.Limit)
+		case "weight_limit":
+			rule = compliance.NewWeightLimit(a, ruleConfig.Limit)
+		default:
+			return fmt.Errorf("compliance rule type '%s' must be one of unit_limit or weight_limit", ruleConfig.Type)
+		}
+		if err := p.AddComplianceRule(rule); err != nil {
+			return err
+		}
+	}
+
+	env.portfolios[config.Code] = p
+	return nil
+}
+
+// newBroker returns a broker as described in a config.
+func newBroker(config BrokerConfig) (*broker.Broker, error) {
+	var execution broker.ExecutionStrategy
+	switch config.Execution.Type {
+	case "", "fill_at_last":
+		execution = broker.NewFillAtLast()
+	case "fill_at_last_with_slippage":
+		execution = broker.NewFillAtLastWithSlippage(config.Execution.Slippage)
+	default:
+		return nil, fmt.Errorf("execution type '%s' must be one of fill_at_last or fill_at_last_with_slippage", config.Execution.Type)
+	}
+
+	var charges broker.ChargesStrategy
+	switch config.Charges.Type {
+	case "", "none":
+		charges = broker.NewNoCharges()
+	case "fixed_plus_percentage":
+		fixedPlusPercentage, err := broker.NewFixedRatePlusPercentageCharges(
+			config.Charges.Fixed, config.Charges.Percentage, config.Charges.Currency)
+		if err != nil {
+			return nil, err
+		}
+		charges = fixedPlusPercentage
+	default:
+		return nil, fmt.Errorf("charges type '%s' must be one of none or fixed_plus_percentage", config.Charges.Type)
+	}
+	executingBroker := broker.NewBroker(charges, execution)
+	switch config.UnitPolicy {
+	case "", "reject":
+	case "round":
+		executingBroker.SetUnitPolicy(broker.RoundUnits)
+	default:
+		return nil, fmt.Errorf("unit policy '%s' must be one of reject or round", config.UnitPolicy)
+	}
+	return executingBroker, nil
+}
+
+func (env *environment) addStrategy(config StrategyConfig) error {
+	if config.Name == "" {
+		return nil // positions are held as they are
+	}
+	builder, ok := builtinStrategies[config.Name]
+	if !ok {
+		return fmt.Errorf("strategy '%s' is not a built-in strategy", config.Name)
+	}
+
+	var portfolios []*asset.Portfolio
+	for _, code := range config.Portfolios {
+		p, err := env.getPortfolio(code)
+		if err != nil {
+			return err
+		}
+		portfolios = append(portfolios, p)
+	}
+	if len(portfolios) == 0 {
+		for _, p := range env.backtest.GetPortfolios() {
+			portfolios = append(portfolios, p)
+		}
+	}
+
+	strategy, err := builder(env, config.Params, portfolios)
+	if err != nil {
+		return fmt.Errorf("strategy '%s' %s", config.Name, err)
+	}
+	return env.backtest.AddStrategy(config.Name, strategy, portfolios...)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestData writes daily csv prices for the assets and FX rate in
+// testYamlConfig to a temporary directory along with the config.
+func writeTestData(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "gobacktrader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]func(i int) float64{
+		"zzb.csv":      func(i int) float64 { return 10 + 0.1*float64(i) },
+		"data/zzc.csv": func(i int) float64 { return 50 + 2*math.Sin(float64(i)) },
+		"audusd.csv":   func(i int) float64 { return 0.75 + 0.001*float64(i) },
+	}
+	for name, price := range files {
+		var builder strings.Builder
+		builder.WriteString("Date,Close\n")
+		for i := 0; i < 40; i++ {
+			date := btutil.Date(2021, 3, 1).AddDate(0, 0, i)
+			fmt.Fprintf(&builder, "%s,%.4f\n", date.Format("2006-01-02"), price(i))
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(builder.String()), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "backtest.yaml"), []byte(testYamlConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestBuildBacktest(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(filepath.Join(dir, "backtest.yaml"))
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+	env, err := buildBacktest(config)
+	if err != nil {
+		t.Fatalf("Error in buildBacktest - %s", err)
+	}
+	defer env.close()
+
+	if env.backtest.GetSeed() != 7 || len(env.backtest.GetAssets()) != 2 || len(env.sources) != 3 {
+		t.Error("Unexpected backtest setup")
+	}
+	if names := env.backtest.GetStrategyNames(); len(names) != 1 || names[0] != "buy_and_hold" {
+		t.Error("Unexpected strategies")
+	}
+	if err := env.backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	p, err := env.getPortfolio("XXX")
+	if err != nil {
+		t.Fatalf("Error in getPortfolio - %s", err)
+	}
+	value, weights, err := p.GetValueWeights()
+	if err != nil || !value.Valid {
+		t.Fatalf("Error in GetValueWeights - %s", err)
+	}
+	for ticker, expected := range map[string]float64{"ZZB AU": 0.6, "ZZC US": 0.3} {
+		a, _ := env.getAsset(ticker)
+		if p.GetUnits(a) <= 0 {
+			t.Errorf("Unexpected units of %s", ticker)
+		}
+		if math.Abs(weights[a].Float64-expected) > 0.1 {
+			t.Errorf("Unexpected weight of %s %f", ticker, weights[a].Float64)
+		}
+	}
+	if records := env.backtest.GetTradeRecords(); len(records) != 2 {
+		t.Errorf("Unexpected trade records %d", len(records))
+	}
+}
+
+func TestBuildBacktestErrors(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(filepath.Join(dir, "backtest.yaml"))
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+	tests := []struct {
+		modify func(c *Config)
+		errStr string
+	}{
+		{func(c *Config) { c.Snapshots = "hourly" },
+			"frequency 'hourly' must be one of daily, weekly, month_end or quarter_end"},
+		{func(c *Config) { c.Portfolios[0].Broker.Execution.Type = "fill_at_open" },
+			"execution type 'fill_at_open' must be one of fill_at_last or fill_at_last_with_slippage"},
+		{func(c *Config) { c.Portfolios[0].Broker.Charges.Type = "free" },
+			"charges type 'free' must be one of none or fixed_plus_percentage"},
+		{func(c *Config) { c.Portfolios[0].Compliance[0].Type = "value_limit" },
+			"compliance rule type 'value_limit' must be one of unit_limit or weight_limit"},
+		{func(c *Config) { c.Portfolios[0].Broker.UnitPolicy = "truncate" },
+			"unit policy 'truncate' must be one of reject or round"},
+		{func(c *Config) {
+			c.Assets = []AssetConfig{{Ticker: "ZZB AU", Currency: "AUD", Csv: c.Assets[0].Csv, LotSize: -1}}
+		},
+			"'ZZB AU' lot size, minimum units and minimum notional must not be negative"},
+		{func(c *Config) { c.Strategy.Name = "momentum" },
+			"strategy 'momentum' is not a built-in strategy"},
+		{func(c *Config) { c.Strategy.Params = []byte(`{"weight": {}}`) },
+			"strategy 'buy_and_hold' json: unknown field \"weight\""},
+	}
+	for _, test := range tests {
+		modified := config
+		modified.Portfolios = []PortfolioConfig{config.Portfolios[0]}
+		modified.Portfolios[0].Compliance = []ComplianceConfig{config.Portfolios[0].Compliance[0]}
+		test.modify(&modified)
+		env, err := buildBacktest(modified)
+		if err == nil {
+			env.close()
+			t.Errorf("Expected error in buildBacktest for '%s'", test.errStr)
+		} else if err.Error() != test.errStr {
+			t.Errorf("Unexpected error in buildBacktest - %s", err)
+		}
+	}
+
+	config.Assets[0].Csv = filepath.Join(dir, "missing.csv")
+	if _, err := buildBacktest(config); err == nil {
+		t.Error("Expected error in buildBacktest for a missing csv file")
+	}
+}
+
+func TestBuildTradingRules(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(filepath.Join(dir, "backtest.yaml"))
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+	fractional := false
+	config.Assets[0].LotSize = 100
+	config.Assets[1].Fractional = &fractional
+	config.Portfolios[0].Broker.UnitPolicy = "round"
+	env, err := buildBacktest(config)
+	if err != nil {
+		t.Fatalf("Error in buildBacktest - %s", err)
+	}
+	defer env.close()
+
+	for i, a := range config.Assets {
+		built, _ := env.getAsset(a.Ticker)
+		rules := built.(*asset.Asset).GetTradingRules()
+		if rules.GetLotSize() != a.LotSize || rules.AllowsFractional() != (i == 0) {
+			t.Errorf("Unexpected trading rules for %s", a.Ticker)
+		}
+	}
+	if err := env.backtest.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	p, _ := env.getPortfolio("XXX")
+	for ticker, lot := range map[string]float64{"ZZB AU": 100, "ZZC US": 1} {
+		a, _ := env.getAsset(ticker)
+		units := p.GetUnits(a)
+		if units <= 0 || math.Mod(units, lot) != 0 {
+			t.Errorf("Unexpected units of %s %f", ticker, units)
+		}
+	}
+}
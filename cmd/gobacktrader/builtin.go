@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/strategies"
+	"gobacktrader/trade"
+	"sort"
+)
+
+// strategyBuilder returns a strategy trading some portfolios from
+// the parameters given in a config.
+type strategyBuilder func(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error)
+
+// builtinStrategies are the strategies that can be named in a config.
+var builtinStrategies = map[string]strategyBuilder{
+	"buy_and_hold":       newBuyAndHold,
+	"equal_weight":       newEqualWeight,
+	"fixed_weights":      newFixedWeights,
+	"inverse_volatility": newInverseVolatility,
+	"risk_parity":        newRiskParity,
+}
+
+// defaultLookback is the number of returns used to estimate
+// volatility where a config does not give a lookback.
+const defaultLookback = 20
+
+// decodeParams decodes strategy parameters, where unknown fields are an error.
+func decodeParams(params json.RawMessage, target interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(target)
+}
+
+// buyAndHold invests each portfolio in fixed weights of its value at
+// the first time step where all assets are priced, then holds.
+type buyAndHold struct {
+	targets  []target
+	invested bool
+}
+
+// target is the weight of some asset in a portfolio.
+type target struct {
+	asset  asset.IAssetReadOnly
+	weight float64
+}
+
+// newTargets returns targets sorted by ticker for weights keyed by ticker.
+func newTargets(env *environment, weights map[string]float64) ([]target, error) {
+	var targets []target
+	for ticker, weight := range weights {
+		a, err := env.getAsset(ticker)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target{asset: a, weight: weight})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].asset.GetTicker() < targets[j].asset.GetTicker()
+	})
+	return targets, nil
+}
+
+func newBuyAndHold(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error) {
+	var config struct {
+		Weights map[string]float64 `json:"weights"`
+	}
+	if err := decodeParams(params, &config); err != nil {
+		return nil, err
+	}
+	if len(config.Weights) == 0 {
+		return nil, errors.New("requires weights")
+	}
+
+	targets, err := newTargets(env, config.Weights)
+	if err != nil {
+		return nil, err
+	}
+	return &buyAndHold{targets: targets}, nil
+}
+
+// GenerateTradesWithContext buys the target weights once.
+func (s *buyAndHold) GenerateTradesWithContext(ctx *backtest.Context) ([]*trade.Trade, error) {
+	if s.invested {
+		return nil, nil
+	}
+	for _, target := range s.targets {
+		if !target.asset.GetValue().Valid {
+			return nil, nil // wait until every asset is priced
+		}
+	}
+
+	var trades []*trade.Trade
+	for _, p := range ctx.GetPortfolios() {
+		value, err := p.GetValue()
+		if err != nil || !value.Valid {
+			return nil, nil
+		}
+		weights := make(map[asset.IAssetReadOnly]float64)
+		for _, target := range s.targets {
+			a := target.asset
+			_, ok, err := p.GetFxRates().GetRate(a.GetBaseCurrency() + p.GetBaseCurrency())
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return nil, nil // wait for an fx rate
+			}
+			weights[a] = target.weight
+		}
+		builder, err := trade.NewTargetBuilder(p)
+		if err != nil {
+			return nil, err
+		}
+		portfolioTrades, err := builder.ToWeights(weights)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, portfolioTrades...)
+	}
+	s.invested = true
+	return trades, nil
+}
+
+// rebalanceParams are the parameters shared by rebalancing strategies,
+// where frequency is one of daily (the default), weekly, month_end or
+// quarter_end and tolerance is the band either side of target weights.
+type rebalanceParams struct {
+	Frequency string  `json:"frequency"`
+	Tolerance float64 `json:"tolerance"`
+}
+
+// newRebalancer returns a rebalancer for the assets with some tickers,
+// which default to all assets.
+func newRebalancer(env *environment, scheme strategies.IWeightScheme, tickers []string, params rebalanceParams) (backtest.IContextStrategy, error) {
+	var assets []asset.IAssetReadOnly
+	for _, ticker := range tickers {
+		a, err := env.getAsset(ticker)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+	if len(tickers) == 0 {
+		for _, a := range env.assets {
+			assets = append(assets, a)
+		}
+	}
+
+	rebalancer, err := strategies.NewRebalancer(scheme, assets...)
+	if err != nil {
+		return nil, err
+	}
+	freq, err := parseFrequency(params.Frequency)
+	if err != nil {
+		return nil, err
+	}
+	rebalancer.SetFrequency(freq)
+	if err := rebalancer.SetTolerance(params.Tolerance); err != nil {
+		return nil, err
+	}
+	return rebalancer, nil
+}
+
+func newEqualWeight(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error) {
+	var config struct {
+		rebalanceParams
+		Tickers []string `json:"tickers"`
+	}
+	if err := decodeParams(params, &config); err != nil {
+		return nil, err
+	}
+	return newRebalancer(env, strategies.NewEqualWeight(), config.Tickers, config.rebalanceParams)
+}
+
+func newFixedWeights(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error) {
+	var config struct {
+		rebalanceParams
+		Weights map[string]float64 `json:"weights"`
+	}
+	if err := decodeParams(params, &config); err != nil {
+		return nil, err
+	}
+
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for ticker, weight := range config.Weights {
+		a, err := env.getAsset(ticker)
+		if err != nil {
+			return nil, err
+		}
+		weights[a] = weight
+	}
+	scheme, err := strategies.NewFixedWeights(weights)
+	if err != nil {
+		return nil, err
+	}
+	var tickers []string
+	for _, a := range scheme.GetAssets() {
+		tickers = append(tickers, a.GetTicker())
+	}
+	return newRebalancer(env, scheme, tickers, config.rebalanceParams)
+}
+
+// volatilityParams are the parameters of strategies weighting by volatility.
+type volatilityParams struct {
+	rebalanceParams
+	Tickers  []string `json:"tickers"`
+	Lookback int      `json:"lookback"`
+}
+
+// decodeVolatilityParams decodes parameters with a default lookback.
+func decodeVolatilityParams(params json.RawMessage) (volatilityParams, error) {
+	config := volatilityParams{Lookback: defaultLookback}
+	err := decodeParams(params, &config)
+	return config, err
+}
+
+func newInverseVolatility(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error) {
+	config, err := decodeVolatilityParams(params)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := strategies.NewInverseVolatility(config.Lookback)
+	if err != nil {
+		return nil, err
+	}
+	return newRebalancer(env, scheme, config.Tickers, config.rebalanceParams)
+}
+
+func newRiskParity(env *environment, params json.RawMessage, portfolios []*asset.Portfolio) (backtest.IContextStrategy, error) {
+	config, err := decodeVolatilityParams(params)
+	if err != nil {
+		return nil, err
+	}
+	scheme, err := strategies.NewRiskParity(config.Lookback)
+	if err != nil {
+		return nil, err
+	}
+	return newRebalancer(env, scheme, config.Tickers, config.rebalanceParams)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinStrategies(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(filepath.Join(dir, "backtest.yaml"))
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+	config.Portfolios[0].Compliance = nil
+	config.Snapshots = "daily" // volatility is estimated from price snapshots
+
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{"equal_weight", `{"frequency": "weekly", "tolerance": 0.02}`},
+		{"equal_weight", `{"tickers": ["ZZB AU"]}`},
+		{"fixed_weights", `{"weights": {"ZZB AU": 0.5, "ZZC US": 0.4}, "frequency": "month_end"}`},
+		{"inverse_volatility", `{"lookback": 5, "frequency": "weekly"}`},
+		{"risk_parity", `{"lookback": 5}`},
+	}
+	for _, test := range tests {
+		config.Strategy.Name = test.name
+		config.Strategy.Params = []byte(test.params)
+		env, err := buildBacktest(config)
+		if err != nil {
+			t.Fatalf("Error in buildBacktest for %s - %s", test.name, err)
+		}
+		if err := env.backtest.Run(); err != nil {
+			t.Errorf("Error in Run for %s - %s", test.name, err)
+		}
+		env.close()
+		if len(env.backtest.GetTradeRecords()) == 0 {
+			t.Errorf("Unexpected no trades for %s %s", test.name, test.params)
+		}
+	}
+}
+
+func TestBuiltinStrategyErrors(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	config, err := LoadConfig(filepath.Join(dir, "backtest.yaml"))
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+
+	tests := []struct {
+		name   string
+		params string
+		errStr string
+	}{
+		{"buy_and_hold", `{}`, "strategy 'buy_and_hold' requires weights"},
+		{"equal_weight", `{"weights": {}}`, "strategy 'equal_weight' json: unknown field \"weights\""},
+		{"equal_weight", `{"frequency": "hourly"}`,
+			"strategy 'equal_weight' frequency 'hourly' must be one of daily, weekly, month_end or quarter_end"},
+		{"equal_weight", `{"tolerance": 1.5}`,
+			"strategy 'equal_weight' tolerance 1.500000 must be at least zero and less than one"},
+		{"fixed_weights", `{"weights": {"ZZB AU": 0.8, "ZZC US": 0.4}}`,
+			"strategy 'fixed_weights' fixed weights sum to 1.200000 which is more than one"},
+		{"inverse_volatility", `{"lookback": 1}`,
+			"strategy 'inverse_volatility' the volatility lookback must be at least two returns"},
+		{"risk_parity", `{"lookback": 1}`,
+			"strategy 'risk_parity' the covariance lookback must be at least two returns"},
+	}
+	for _, test := range tests {
+		config.Strategy.Name = test.name
+		config.Strategy.Params = []byte(test.params)
+		env, err := buildBacktest(config)
+		if err == nil {
+			env.close()
+			t.Errorf("Expected error in buildBacktest for %s %s", test.name, test.params)
+		} else if err.Error() != test.errStr {
+			t.Errorf("Unexpected error in buildBacktest - %s", err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a backtest to run from a yaml or json file.
+type Config struct {
+	Name       string            `json:"name"`
+	Seed       int64             `json:"seed"`
+	Snapshots  string            `json:"snapshots"`
+	Assets     []AssetConfig     `json:"assets"`
+	Fx         []FxConfig        `json:"fx"`
+	Portfolios []PortfolioConfig `json:"portfolios"`
+	Strategy   StrategyConfig    `json:"strategy"`
+	Output     OutputConfig      `json:"output"`
+}
+
+// AssetConfig describes an asset, the csv file holding its prices and
+// the units in which it trades, where fractional units are allowed
+// unless fractional is false.
+type AssetConfig struct {
+	Ticker      string  `json:"ticker"`
+	Currency    string  `json:"currency"`
+	Multiplier  float64 `json:"multiplier"`
+	Csv         string  `json:"csv"`
+	LotSize     float64 `json:"lot_size"`
+	MinUnits    float64 `json:"min_units"`
+	MinNotional float64 `json:"min_notional"`
+	Fractional  *bool   `json:"fractional"`
+}
+
+// FxConfig describes an FX pair with an optional initial rate
+// and the csv file holding its rates.
+type FxConfig struct {
+	Pair string   `json:"pair"`
+	Rate *float64 `json:"rate"`
+	Csv  string   `json:"csv"`
+}
+
+// PortfolioConfig describes a portfolio with its initial cash and
+// positions, executing broker and compliance rules.
+type PortfolioConfig struct {
+	Code       string             `json:"code"`
+	Currency   string             `json:"currency"`
+	Cash       map[string]float64 `json:"cash"`
+	Positions  map[string]float64 `json:"positions"`
+	Broker     BrokerConfig       `json:"broker"`
+	Compliance []ComplianceConfig `json:"compliance"`
+}
+
+// BrokerConfig describes broker execution and charges, along with how
+// trades that break an asset's trading rules are handled, where the
+// unit policy is one of reject (the default) or round.
+type BrokerConfig struct {
+	Execution  ExecutionConfig `json:"execution"`
+	Charges    ChargesConfig   `json:"charges"`
+	UnitPolicy string          `json:"unit_policy"`
+}
+
+// ExecutionConfig describes how trades are filled, where type is one of
+// fill_at_last (the default) or fill_at_last_with_slippage.
+type ExecutionConfig struct {
+	Type     string  `json:"type"`
+	Slippage float64 `json:"slippage"`
+}
+
+// ChargesConfig describes broker charges, where type is one of
+// none (the default) or fixed_plus_percentage.
+type ChargesConfig struct {
+	Type       string  `json:"type"`
+	Fixed      float64 `json:"fixed"`
+	Percentage float64 `json:"percentage"`
+	Currency   string  `json:"currency"`
+}
+
+// ComplianceConfig describes a compliance rule for some asset,
+// where type is one of unit_limit or weight_limit.
+type ComplianceConfig struct {
+	Type   string  `json:"type"`
+	Ticker string  `json:"ticker"`
+	Limit  float64 `json:"limit"`
+}
+
+// StrategyConfig names a built-in strategy along with its parameters
+// and the portfolios it trades, which default to all portfolios.
+type StrategyConfig struct {
+	Name       string          `json:"name"`
+	Portfolios []string        `json:"portfolios"`
+	Params     json.RawMessage `json:"params"`
+}
+
+// OutputConfig sets where results are written and in which formats,
+// from csv, json, html and png, where all but png are written by default.
+type OutputConfig struct {
+	Dir     string   `json:"dir"`
+	Formats []string `json:"formats"`
+}
+
+// LoadConfig reads a config from a yaml or json file, where the format
+// is chosen by the file extension. Unknown fields are an error. Relative
+// csv paths are taken relative to the config file.
+func LoadConfig(filePath string) (Config, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return Config{}, err
+	}
+	config, err := ParseConfig(data, filepath.Ext(filePath))
+	if err != nil {
+		return Config{}, fmt.Errorf("'%s' %s", filePath, err)
+	}
+
+	dir := filepath.Dir(filePath)
+	for i := range config.Assets {
+		config.Assets[i].Csv = relativeTo(dir, config.Assets[i].Csv)
+	}
+	for i := range config.Fx {
+		config.Fx[i].Csv = relativeTo(dir, config.Fx[i].Csv)
+	}
+	return config, nil
+}
+
+// ParseConfig parses a config in the format given by a file extension.
+func ParseConfig(data []byte, extension string) (Config, error) {
+	switch strings.ToLower(extension) {
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return Config{}, err
+		}
+		var err error
+		if data, err = json.Marshal(document); err != nil {
+			return Config{}, err
+		}
+	case ".json":
+	default:
+		return Config{}, fmt.Errorf("config extension '%s' must be .yaml, .yml or .json", extension)
+	}
+
+	var config Config
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return Config{}, err
+	}
+	return config, config.validate()
+}
+
+func relativeTo(dir string, filePath string) string {
+	if filePath == "" || filepath.IsAbs(filePath) {
+		return filePath
+	}
+	return filepath.Join(dir, filePath)
+}
+
+// validate checks the config has what is needed to run a backtest.
+func (c Config) validate() error {
+	if len(c.Assets) == 0 {
+		return errors.New("config requires at least one asset")
+	}
+	if len(c.Portfolios) == 0 {
+		return errors.New("config requires at least one portfolio")
+	}
+	for _, a := range c.Assets {
+		if a.Ticker == "" || a.Csv == "" {
+			return errors.New("assets require a ticker and csv file")
+		}
+	}
+	for _, fx := range c.Fx {
+		if fx.Pair == "" {
+			return errors.New("fx rates require a pair")
+		}
+	}
+	for _, p := range c.Portfolios {
+		if p.Code == "" || p.Currency == "" {
+			return errors.New("portfolios require a code and currency")
+		}
+	}
+	for _, format := range c.Output.Formats {
+		switch format {
+		case "csv", "json", "html", "png":
+		default:
+			return fmt.Errorf("output format '%s' must be one of csv, json, html or png", format)
+		}
+	}
+	return nil
+}
+
+// outputFormats returns the formats to write.
+func (c Config) outputFormats() map[string]bool {
+	formats := c.Output.Formats
+	if len(formats) == 0 {
+		formats = []string{"csv", "json", "html"}
+	}
+	selected := make(map[string]bool)
+	for _, format := range formats {
+		selected[format] = true
+	}
+	return selected
+}
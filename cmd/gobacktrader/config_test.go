@@ -0,0 +1,141 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testYamlConfig = `name: Test backtest
+seed: 7
+snapshots: weekly
+assets:
+  - ticker: ZZB AU
+    currency: AUD
+    csv: zzb.csv
+  - ticker: ZZC US
+    currency: USD
+    csv: data/zzc.csv
+fx:
+  - pair: AUDUSD
+    csv: audusd.csv
+portfolios:
+  - code: XXX
+    currency: AUD
+    cash:
+      AUD: 10000
+    broker:
+      charges:
+        type: fixed_plus_percentage
+        fixed: 5
+        percentage: 0.001
+        currency: AUD
+    compliance:
+      - type: weight_limit
+        ticker: ZZB AU
+        limit: 0.8
+strategy:
+  name: buy_and_hold
+  params:
+    weights:
+      ZZB AU: 0.6
+      ZZC US: 0.3
+output:
+  formats: [csv, json, html, png]
+`
+
+func TestParseConfig(t *testing.T) {
+	config, err := ParseConfig([]byte(testYamlConfig), ".yaml")
+	if err != nil {
+		t.Fatalf("Error in ParseConfig - %s", err)
+	}
+	if config.Name != "Test backtest" || config.Seed != 7 || config.Snapshots != "weekly" {
+		t.Error("Unexpected config settings")
+	}
+	if len(config.Assets) != 2 || config.Assets[1].Ticker != "ZZC US" || config.Assets[1].Csv != "data/zzc.csv" {
+		t.Error("Unexpected assets")
+	}
+	if len(config.Fx) != 1 || config.Fx[0].Pair != "AUDUSD" || config.Fx[0].Rate != nil {
+		t.Error("Unexpected fx rates")
+	}
+	if len(config.Portfolios) != 1 {
+		t.Fatal("Unexpected portfolios")
+	}
+	p := config.Portfolios[0]
+	if p.Cash["AUD"] != 10000 || p.Broker.Charges.Fixed != 5 || p.Broker.Charges.Percentage != 0.001 {
+		t.Error("Unexpected portfolio settings")
+	}
+	if len(p.Compliance) != 1 || p.Compliance[0].Type != "weight_limit" || p.Compliance[0].Limit != 0.8 {
+		t.Error("Unexpected compliance rules")
+	}
+	if config.Strategy.Name != "buy_and_hold" || len(config.Strategy.Params) == 0 {
+		t.Error("Unexpected strategy")
+	}
+	formats := config.outputFormats()
+	if len(formats) != 4 || !formats["png"] {
+		t.Error("Unexpected output formats")
+	}
+
+	jsonConfig := `{"assets": [{"ticker": "ZZB AU", "currency": "AUD", "csv": "zzb.csv"}],
+		"portfolios": [{"code": "XXX", "currency": "AUD", "cash": {"AUD": 100}}]}`
+	config, err = ParseConfig([]byte(jsonConfig), ".JSON")
+	if err != nil {
+		t.Fatalf("Error in ParseConfig - %s", err)
+	}
+	formats = config.outputFormats()
+	if len(formats) != 3 || !formats["csv"] || !formats["json"] || !formats["html"] {
+		t.Error("Unexpected default output formats")
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	tests := []struct {
+		data      string
+		extension string
+		errStr    string
+	}{
+		{"{}", ".toml", "config extension '.toml' must be .yaml, .yml or .json"},
+		{"{}", ".json", "config requires at least one asset"},
+		{"assets: [{ticker: A, csv: a.csv}]", ".yml", "config requires at least one portfolio"},
+		{"assets: [{ticker: A}]\nportfolios: [{code: X, currency: AUD}]", ".yml", "assets require a ticker and csv file"},
+		{"assets: [{ticker: A, csv: a.csv}]\nportfolios: [{code: X}]", ".yml", "portfolios require a code and currency"},
+		{"assets: [{ticker: A, csv: a.csv}]\nportfolios: [{code: X, currency: AUD}]\noutput: {formats: [pdf]}",
+			".yml", "output format 'pdf' must be one of csv, json, html or png"},
+		{"assets: [{ticker: A, csv: a.csv, price: 1}]", ".yml", "json: unknown field \"price\""},
+	}
+	for _, test := range tests {
+		_, err := ParseConfig([]byte(test.data), test.extension)
+		if err == nil {
+			t.Errorf("Expected error in ParseConfig for %s", test.data)
+		} else if err.Error() != test.errStr {
+			t.Errorf("Unexpected error in ParseConfig - %s", err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gobacktrader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	configPath := filepath.Join(dir, "backtest.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(testYamlConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Error in LoadConfig - %s", err)
+	}
+	if config.Assets[0].Csv != filepath.Join(dir, "zzb.csv") ||
+		config.Assets[1].Csv != filepath.Join(dir, "data", "zzc.csv") ||
+		config.Fx[0].Csv != filepath.Join(dir, "audusd.csv") {
+		t.Error("Unexpected csv paths")
+	}
+
+	if _, err := LoadConfig(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("Expected error in LoadConfig for a missing file")
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/datasources"
+	"gobacktrader/events"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fetchFunc queries a data source for the price events of an asset,
+// where the query ticker may differ from the asset ticker.
+type fetchFunc func(a asset.IAssetReadOnly, queryTicker string, apiKey string, start time.Time, end time.Time) ([]events.IEvent, error)
+
+// fetchSource is a data source along with the environment
+// variable that holds its api key.
+type fetchSource struct {
+	apiKeyEnv string
+	fetch     fetchFunc
+}
+
+// fetchSources are the data sources that can be named on the command line.
+var fetchSources = map[string]fetchSource{
+	"fmpcloud":     {apiKeyEnv: "FMPCLOUD_API_KEY", fetch: fetchFmpCloud},
+	"alphavantage": {apiKeyEnv: "ALPHAVANTAGE_API_KEY", fetch: fetchAlphaVantage},
+}
+
+func fetchFmpCloud(a asset.IAssetReadOnly, queryTicker string, apiKey string, start time.Time, end time.Time) ([]events.IEvent, error) {
+	query := datasources.NewFmpCloudQuery(a, start, end)
+	query.SetTicker(queryTicker).SetAPIKey(apiKey)
+	return query.GenerateEvents()
+}
+
+func fetchAlphaVantage(a asset.IAssetReadOnly, queryTicker string, apiKey string, start time.Time, end time.Time) ([]events.IEvent, error) {
+	query := datasources.NewAlphaVantageQuery(a, start, end)
+	query.SetTicker(queryTicker).SetAPIKey(apiKey)
+	return query.GenerateEvents()
+}
+
+// storedPrice is a daily closing price held in the local store.
+type storedPrice struct {
+	Date  string  `json:"date"`
+	Close float64 `json:"close"`
+}
+
+// storedPrices is the json layout of the local store for one ticker.
+type storedPrices struct {
+	Ticker string        `json:"ticker"`
+	Source string        `json:"source"`
+	Prices []storedPrice `json:"prices"`
+}
+
+// fetchRequest is a ticker to fetch, where the query
+// ticker defaults to the ticker itself.
+type fetchRequest struct {
+	ticker      string
+	queryTicker string
+}
+
+// parseFetchRequest parses a ticker argument of the form
+// TICKER or TICKER=QUERY_TICKER.
+func parseFetchRequest(arg string) (fetchRequest, error) {
+	ticker, queryTicker := arg, ""
+	if i := strings.Index(arg, "="); i >= 0 {
+		ticker, queryTicker = arg[:i], arg[i+1:]
+	}
+	ticker, queryTicker = strings.TrimSpace(ticker), strings.TrimSpace(queryTicker)
+	if ticker == "" {
+		return fetchRequest{}, fmt.Errorf("ticker '%s' must be of the form TICKER or TICKER=QUERY_TICKER", arg)
+	}
+	if queryTicker == "" {
+		queryTicker = ticker
+	}
+	return fetchRequest{ticker: ticker, queryTicker: queryTicker}, nil
+}
+
+// fetchCommand downloads prices for a list of tickers and merges them
+// into a local store of csv and json files, one file per ticker,
+// that can be read back with a csv source.
+func fetchCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("fetch", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	sourceName := flags.String("source", "fmpcloud", "data source, one of fmpcloud or alphavantage")
+	apiKey := flags.String("apikey", "", "api key, defaulting to FMPCLOUD_API_KEY or ALPHAVANTAGE_API_KEY")
+	startDate := flags.String("start", "", "first date to fetch as 2006-01-02")
+	endDate := flags.String("end", "", "last date to fetch as 2006-01-02, defaulting to today")
+	currency := flags.String("currency", "USD", "base currency of the tickers")
+	outDir := flags.String("out", "data", "directory for the local store")
+	formats := flags.String("format", "csv", "comma separated store formats from csv and json")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gobacktrader fetch -start 2006-01-02 [flags] TICKER[=QUERY_TICKER] ...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+
+	usageError := func(message string) error {
+		fmt.Fprintln(stderr, message)
+		flags.Usage()
+		return errUsage
+	}
+	source, ok := fetchSources[*sourceName]
+	if !ok {
+		return usageError(fmt.Sprintf("source '%s' must be one of fmpcloud or alphavantage", *sourceName))
+	}
+	start, err := time.Parse("2006-01-02", *startDate)
+	if err != nil {
+		return usageError("fetch requires a -start date as 2006-01-02")
+	}
+	end := time.Now().UTC().Truncate(24 * time.Hour)
+	if *endDate != "" {
+		if end, err = time.Parse("2006-01-02", *endDate); err != nil {
+			return usageError("the -end date must be 2006-01-02")
+		}
+	}
+	if end.Before(start) {
+		return usageError("the -end date must not be before the -start date")
+	}
+	selected := make(map[string]bool)
+	for _, format := range strings.Split(*formats, ",") {
+		format = strings.TrimSpace(format)
+		if format != "csv" && format != "json" {
+			return usageError(fmt.Sprintf("format '%s' must be one of csv or json", format))
+		}
+		selected[format] = true
+	}
+	if flags.NArg() == 0 {
+		return usageError("fetch requires at least one ticker")
+	}
+	var requests []fetchRequest
+	for _, arg := range flags.Args() {
+		request, err := parseFetchRequest(arg)
+		if err != nil {
+			return usageError(err.Error())
+		}
+		requests = append(requests, request)
+	}
+
+	key := *apiKey
+	if key == "" {
+		key = os.Getenv(source.apiKeyEnv)
+	}
+	if key == "" {
+		return usageError(fmt.Sprintf("fetch requires an api key with -apikey or %s", source.apiKeyEnv))
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, request := range requests {
+		count, paths, err := fetchTicker(source, *sourceName, request, key, *currency, start, end, *outDir, selected)
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "FAIL %s: %s\n", request.ticker, err)
+			continue
+		}
+		fmt.Fprintf(stdout, "OK   %s: %d prices -> %s\n", request.ticker, count, strings.Join(paths, ", "))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tickers failed to fetch", failed, len(requests))
+	}
+	return nil
+}
+
+// fetchTicker fetches prices for a ticker, merges them with any prices
+// already in the csv or json stores and writes the store in the selected
+// formats. Where both stores exist the json prices take precedence.
+func fetchTicker(source fetchSource, sourceName string, request fetchRequest, apiKey string, currency string,
+	start time.Time, end time.Time, dir string, formats map[string]bool) (int, []string, error) {
+	a, err := asset.NewStock(request.ticker, currency)
+	if err != nil {
+		return 0, nil, err
+	}
+	fetched, err := source.fetch(a, request.queryTicker, apiKey, start, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	prices, err := normalisePrices(fetched, start, end)
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(prices) == 0 {
+		return 0, nil, fmt.Errorf("no prices returned for '%s'", request.queryTicker)
+	}
+	count := len(prices)
+
+	csvPath := filepath.Join(dir, btutil.CleanFileName(request.ticker)+".csv")
+	jsonPath := filepath.Join(dir, btutil.CleanFileName(request.ticker)+".json")
+	storedCsv, err := readStoredPrices(a, csvPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	storedJSON, err := readStoredJSON(jsonPath)
+	if err != nil {
+		return 0, nil, err
+	}
+	prices = mergePrices(storedCsv, storedJSON, prices)
+
+	var paths []string
+	if formats["csv"] {
+		if err := writePricesCsv(csvPath, prices); err != nil {
+			return 0, nil, err
+		}
+		paths = append(paths, csvPath)
+	}
+	if formats["json"] {
+		store := storedPrices{Ticker: a.GetTicker(), Source: sourceName, Prices: prices}
+		data, err := json.MarshalIndent(store, "", "  ")
+		if err != nil {
+			return 0, nil, err
+		}
+		if err := ioutil.WriteFile(jsonPath, data, 0644); err != nil {
+			return 0, nil, err
+		}
+		paths = append(paths, jsonPath)
+	}
+	return count, paths, nil
+}
+
+// normalisePrices returns valid daily prices between two dates in date
+// order, where the last price is kept for any repeated date.
+func normalisePrices(fetched []events.IEvent, start time.Time, end time.Time) ([]storedPrice, error) {
+	byDate := make(map[string]float64)
+	for _, event := range fetched {
+		priced, ok := event.(datasources.IEventHasPrice)
+		if !ok {
+			return nil, errors.New("fetched events must have a price")
+		}
+		price := priced.GetPrice()
+		eventTime := event.GetTime()
+		if !price.Valid || eventTime.Before(start) || eventTime.After(end) {
+			continue
+		}
+		byDate[eventTime.Format("2006-01-02")] = price.Float64
+	}
+	return sortedPrices(byDate), nil
+}
+
+// mergePrices merges sets of prices in order, so that prices
+// in later sets replace those on the same date in earlier sets.
+func mergePrices(sets ...[]storedPrice) []storedPrice {
+	byDate := make(map[string]float64)
+	for _, prices := range sets {
+		for _, price := range prices {
+			byDate[price.Date] = price.Close
+		}
+	}
+	return sortedPrices(byDate)
+}
+
+func sortedPrices(byDate map[string]float64) []storedPrice {
+	prices := make([]storedPrice, 0, len(byDate))
+	for date, close := range byDate {
+		prices = append(prices, storedPrice{Date: date, Close: close})
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Date < prices[j].Date
+	})
+	return prices
+}
+
+// readStoredPrices reads prices from a csv store with a csv source,
+// returning no prices if the file does not yet exist.
+func readStoredPrices(a asset.IAssetReadOnly, filePath string) ([]storedPrice, error) {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	source, err := datasources.NewCsvSource(a, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	var prices []storedPrice
+	for {
+		event, ok, err := source.Next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return prices, nil
+		}
+		price := event.(datasources.IEventHasPrice).GetPrice()
+		if price.Valid {
+			prices = append(prices, storedPrice{Date: event.GetTime().Format("2006-01-02"), Close: price.Float64})
+		}
+	}
+}
+
+// readStoredJSON reads prices from a json store,
+// returning no prices if the file does not yet exist.
+func readStoredJSON(filePath string) ([]storedPrice, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var store storedPrices
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("cannot read the json store '%s' - %s", filePath, err)
+	}
+	return store.Prices, nil
+}
+
+// writePricesCsv writes prices with Date and Close columns.
+func writePricesCsv(filePath string, prices []storedPrice) error {
+	var buffer bytes.Buffer
+	buffer.WriteString("Date,Close\n")
+	for _, price := range prices {
+		buffer.WriteString(price.Date + "," + strconv.FormatFloat(price.Close, 'f', -1, 64) + "\n")
+	}
+	return ioutil.WriteFile(filePath, buffer.Bytes(), 0644)
+}
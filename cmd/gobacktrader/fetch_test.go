@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/datasources"
+	"gobacktrader/events"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeFetch returns unordered prices with a repeated date, a missing
+// price and a date out of range, failing for the query ticker BAD.
+func fakeFetch(a asset.IAssetReadOnly, queryTicker string, apiKey string, start time.Time, end time.Time) ([]events.IEvent, error) {
+	if queryTicker == "BAD" {
+		return nil, errors.New("unknown symbol")
+	}
+	if apiKey != "secret" {
+		return nil, errors.New("invalid api key")
+	}
+	writable := a.(asset.IAssetWriteOnly)
+	var fetched []events.IEvent
+	for _, p := range []struct {
+		day   int
+		price asset.Price
+	}{
+		{5, asset.Price{Float64: 1.3, Valid: true}},
+		{1, asset.Price{Float64: 1.0, Valid: true}},
+		{2, asset.Price{Float64: 0.0, Valid: false}},
+		{1, asset.Price{Float64: 1.1, Valid: true}},
+		{30, asset.Price{Float64: 2.0, Valid: true}},
+	} {
+		event := events.NewAssetPriceEvent(writable, btutil.Date(2021, 4, p.day), p.price)
+		fetched = append(fetched, &event)
+	}
+	return fetched, nil
+}
+
+func TestParseFetchRequest(t *testing.T) {
+	request, err := parseFetchRequest("ZZB AU=ZZB.AX")
+	if err != nil {
+		t.Fatalf("Error in parseFetchRequest - %s", err)
+	}
+	if request.ticker != "ZZB AU" || request.queryTicker != "ZZB.AX" {
+		t.Error("Unexpected fetch request")
+	}
+	request, err = parseFetchRequest("AAPL")
+	if err != nil || request.ticker != "AAPL" || request.queryTicker != "AAPL" {
+		t.Error("Unexpected fetch request")
+	}
+	if _, err := parseFetchRequest("=AAPL"); err == nil {
+		t.Error("Expected error in parseFetchRequest")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	fetchSources["fake"] = fetchSource{apiKeyEnv: "FAKE_API_KEY", fetch: fakeFetch}
+	defer delete(fetchSources, "fake")
+	os.Setenv("FAKE_API_KEY", "secret")
+	defer os.Unsetenv("FAKE_API_KEY")
+
+	dir, err := ioutil.TempDir("", "gobacktrader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// an existing store is merged with the fetched prices
+	csvPath := filepath.Join(dir, "ZZB_AU.csv")
+	if err := ioutil.WriteFile(csvPath, []byte("Date,Close\n2021-03-31,0.9\n2021-04-01,0.95\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"fetch", "-source", "fake", "-start", "2021-04-01", "-end", "2021-04-10",
+		"-out", dir, "-format", "csv,json", "ZZB AU=ZZB.AX", "ZZC=BAD"}
+	if code := run(args, &stdout, &stderr); code != 1 {
+		t.Errorf("Unexpected exit code %d", code)
+	}
+	output := stdout.String()
+	if !strings.Contains(output, "OK   ZZB AU: 2 prices") || !strings.Contains(output, "FAIL ZZC: unknown symbol") {
+		t.Errorf("Unexpected output %s", output)
+	}
+	if !strings.Contains(stderr.String(), "1 of 2 tickers failed to fetch") {
+		t.Errorf("Unexpected error output %s", stderr.String())
+	}
+
+	data, err := ioutil.ReadFile(csvPath)
+	if err != nil {
+		t.Fatalf("Error in ReadFile - %s", err)
+	}
+	if string(data) != "Date,Close\n2021-03-31,0.9\n2021-04-01,1.1\n2021-04-05,1.3\n" {
+		t.Errorf("Unexpected csv store %s", data)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, "ZZB_AU.json"))
+	if err != nil {
+		t.Fatalf("Error in ReadFile - %s", err)
+	}
+	var store storedPrices
+	if err := json.Unmarshal(data, &store); err != nil {
+		t.Fatalf("Error in json.Unmarshal - %s", err)
+	}
+	if store.Ticker != "ZZB AU" || store.Source != "fake" || len(store.Prices) != 3 || store.Prices[2].Close != 1.3 {
+		t.Error("Unexpected json store")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ZZC.csv")); !os.IsNotExist(err) {
+		t.Error("Unexpected store for a failed ticker")
+	}
+
+	// the csv store reads back into a backtest
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+	csvSource, err := datasources.NewCsvSource(stock, csvPath)
+	if err != nil {
+		t.Fatalf("Error in NewCsvSource - %s", err)
+	}
+	defer csvSource.Close()
+	bt := backtest.NewBacktest(nil)
+	bt.RegisterAsset(stock)
+	bt.AddEventSource(csvSource)
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+	series := stock.GetPriceSeries()
+	if series.Len() != 3 || series.At(2).GetPrice().Float64 != 1.3 {
+		t.Errorf("Unexpected backtest prices from the csv store - %d snapshots", series.Len())
+	}
+
+	// a json only store is merged with the fetched prices
+	stdout.Reset()
+	os.Remove(csvPath)
+	args = []string{"fetch", "-source", "fake", "-apikey", "secret", "-start", "2021-04-01", "-end", "2021-04-01",
+		"-out", dir, "-format", "json", "ZZB AU"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Errorf("Unexpected exit code %d", code)
+	}
+	prices, err := readStoredJSON(filepath.Join(dir, "ZZB_AU.json"))
+	if err != nil || len(prices) != 3 || prices[0].Date != "2021-03-31" || prices[2].Close != 1.3 {
+		t.Errorf("Unexpected merged json store %v", prices)
+	}
+}
+
+func TestFetchUsage(t *testing.T) {
+	fetchSources["fake"] = fetchSource{apiKeyEnv: "FAKE_API_KEY", fetch: fakeFetch}
+	defer delete(fetchSources, "fake")
+
+	tests := [][]string{
+		{"fetch", "-source", "fake", "-start", "2021-04-01", "AAPL"},
+		{"fetch", "AAPL"},
+		{"fetch", "-start", "2021-04-01"},
+		{"fetch", "-start", "01/04/2021", "AAPL"},
+		{"fetch", "-start", "2021-04-01", "-end", "2021-03-01", "AAPL"},
+		{"fetch", "-start", "2021-04-01", "-source", "yahoo", "AAPL"},
+		{"fetch", "-start", "2021-04-01", "-format", "xml", "AAPL"},
+		{"fetch", "-start", "2021-04-01", "=AAPL"},
+	}
+	for _, args := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Errorf("Unexpected exit code %d for %v", code, args)
+		}
+	}
+}
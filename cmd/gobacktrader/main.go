@@ -0,0 +1,142 @@
+// Command gobacktrader runs backtests described by yaml or json config files
+// and fetches the market data they read.
+//
+// Usage:
+//
+//	gobacktrader run -config backtest.yaml [-out results] [-progress 100]
+//	gobacktrader fetch -start 2020-01-01 [-source fmpcloud] [-out data] AAPL "ZZB AU=ZZB.AX"
+//
+// The exit code is 0 on success, 1 if the backtest fails
+// and 2 if the command line is not valid.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gobacktrader/backtest"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+const usage = `usage: gobacktrader <command> [flags]
+
+commands:
+  run    run a backtest from a yaml or json config file
+  fetch  download daily prices into a local csv or json store
+
+Run 'gobacktrader <command> -h' for the flags of a command.
+`
+
+// errUsage is returned for command lines that are not valid.
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs a command, returning the exit code.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "run":
+		err = runCommand(args[1:], stdout, stderr)
+	case "fetch":
+		err = fetchCommand(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command '%s'\n\n%s", args[0], usage)
+		return 2
+	}
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	}
+	fmt.Fprintf(stderr, "error: %s\n", err)
+	return 1
+}
+
+// runCommand runs a backtest from a config file and writes its outputs.
+func runCommand(args []string, stdout io.Writer, stderr io.Writer) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", "", "path to a yaml or json config file")
+	outDir := flags.String("out", "", "directory for outputs, overriding the config")
+	progressSteps := flags.Int("progress", 0, "report progress every number of steps")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if *configPath == "" || flags.NArg() > 0 {
+		fmt.Fprintln(stderr, "run requires a -config file and no other arguments")
+		flags.Usage()
+		return errUsage
+	}
+
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	dir := config.Output.Dir
+	if *outDir != "" {
+		dir = *outDir
+	}
+	if dir == "" {
+		dir = "results"
+	}
+
+	env, err := buildBacktest(config)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+	if *progressSteps > 0 {
+		err := env.backtest.SetProgress(func(p backtest.Progress) {
+			fmt.Fprintf(stderr, "%s: %d steps, %d events in %s\n",
+				p.Time.Format("2006-01-02"), p.Steps, p.EventsProcessed, p.Elapsed.Round(time.Millisecond))
+		}, *progressSteps)
+		if err != nil {
+			return err
+		}
+	}
+
+	// stop cleanly between steps on interrupt
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+	go func() {
+		select {
+		case <-interrupts:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := env.backtest.RunContext(ctx); err != nil {
+		return err
+	}
+	paths, err := writeOutputs(env.backtest, config, dir)
+	for _, path := range paths {
+		fmt.Fprintln(stdout, path)
+	}
+	return err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"gobacktrader/backtest"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	outDir := filepath.Join(dir, "out")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"run", "-config", filepath.Join(dir, "backtest.yaml"), "-out", outDir, "-progress", "10"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("Unexpected exit code %d - %s", code, stderr.String())
+	}
+
+	expected := []string{"results.json", "history.json", "history.csv", "tearsheet_XXX.html",
+		"equity.png", "drawdown.png", "weights_XXX.png"}
+	for _, name := range expected {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("Error in os.Stat - %s", err)
+		}
+		if !strings.Contains(stdout.String(), filepath.Join(outDir, name)) {
+			t.Errorf("Unexpected output missing %s", name)
+		}
+	}
+	if !strings.Contains(stderr.String(), "2021-03-10: 10 steps") {
+		t.Errorf("Unexpected progress %s", stderr.String())
+	}
+
+	results, err := backtest.LoadResults(filepath.Join(outDir, "results.json"))
+	if err != nil {
+		t.Fatalf("Error in LoadResults - %s", err)
+	}
+	if results.Metadata.Seed != 7 || len(results.Trades) != 2 || len(results.Equity["XXX"]) == 0 {
+		t.Error("Unexpected results")
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	dir := writeTestData(t)
+	defer os.RemoveAll(dir)
+
+	badConfig := filepath.Join(dir, "bad.json")
+	if err := ioutil.WriteFile(badConfig, []byte(`{"assets": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{nil, 2},
+		{[]string{"help"}, 0},
+		{[]string{"fly"}, 2},
+		{[]string{"run"}, 2},
+		{[]string{"run", "-config"}, 2},
+		{[]string{"run", "-h"}, 0},
+		{[]string{"run", "-config", filepath.Join(dir, "missing.yaml")}, 1},
+		{[]string{"run", "-config", badConfig}, 1},
+	}
+	for _, test := range tests {
+		var stdout, stderr bytes.Buffer
+		if code := run(test.args, &stdout, &stderr); code != test.code {
+			t.Errorf("Unexpected exit code %d for %v", code, test.args)
+		}
+	}
+}
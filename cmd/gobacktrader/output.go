@@ -0,0 +1,67 @@
+package main
+
+import (
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/report"
+	"os"
+	"path/filepath"
+)
+
+// writeOutputs writes the results of a completed backtest to a
+// directory in the formats selected by the config, returning the
+// paths of the files written.
+func writeOutputs(bt *backtest.Backtest, config Config, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	results, err := bt.GetResults()
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	formats := config.outputFormats()
+	if formats["json"] {
+		resultsPath := filepath.Join(dir, "results.json")
+		if err := results.SaveJSON(resultsPath); err != nil {
+			return paths, err
+		}
+		historyPath := filepath.Join(dir, "history.json")
+		if err := bt.HistoryToJSON(historyPath); err != nil {
+			return paths, err
+		}
+		paths = append(paths, resultsPath, historyPath)
+	}
+	if formats["csv"] {
+		historyPath := filepath.Join(dir, "history.csv")
+		if err := bt.HistoryToCsvWithOptions(historyPath, backtest.NewCsvOptions()); err != nil {
+			return paths, err
+		}
+		paths = append(paths, historyPath)
+	}
+	if formats["html"] {
+		for _, code := range results.Metadata.Portfolios {
+			tearsheet, err := report.NewTearsheet(bt, code)
+			if err != nil {
+				return paths, err
+			}
+			if config.Name != "" {
+				tearsheet.SetTitle(config.Name + " - " + code)
+			}
+			tearsheetPath := filepath.Join(dir, "tearsheet_"+btutil.CleanFileName(code)+".html")
+			if err := tearsheet.Save(tearsheetPath); err != nil {
+				return paths, err
+			}
+			paths = append(paths, tearsheetPath)
+		}
+	}
+	if formats["png"] {
+		chartPaths, err := report.SavePNGCharts(bt, dir, report.NewPNGOptions())
+		paths = append(paths, chartPaths...)
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
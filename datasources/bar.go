@@ -0,0 +1,30 @@
+package datasources
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/events"
+	"time"
+)
+
+// Bar holds the open, high, low and close prices along
+// with traded volume for a single period.
+type Bar struct {
+	Time   time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume float64
+}
+
+// BarsToEvents returns an asset price event for the close
+// of each bar.
+func BarsToEvents(targetAsset asset.IAssetWriteOnly, bars []Bar) []events.IEvent {
+	var priceEvents []events.IEvent
+	for _, bar := range bars {
+		price := asset.Price{Float64: bar.Close, Valid: true}
+		assetPriceEvent := events.NewAssetPriceEvent(targetAsset, bar.Time, price)
+		priceEvents = append(priceEvents, &assetPriceEvent)
+	}
+	return priceEvents
+}
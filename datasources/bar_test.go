@@ -0,0 +1,33 @@
+package datasources
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBarsToEvents(t *testing.T) {
+	t1 := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, time.April, 2, 0, 0, 0, 0, time.UTC)
+	bars := []Bar{
+		{Time: t1, Open: 1.0, High: 1.5, Low: 0.9, Close: 1.2, Volume: 100},
+		{Time: t2, Open: 1.2, High: 1.3, Low: 1.1, Close: 1.25, Volume: 200},
+	}
+
+	priceEvents := BarsToEvents(testAsset, bars)
+	if len(priceEvents) != 2 {
+		t.Fatalf("Expecting 2 events, got %d", len(priceEvents))
+	}
+
+	for i, event := range priceEvents {
+		if !event.GetTime().Equal(bars[i].Time) {
+			t.Error("Unexpected event time")
+		}
+		priceEvent, ok := event.(IEventHasPrice)
+		if !ok {
+			t.Fatal("Cannot cast to interface IEventHasPrice")
+		}
+		if priceEvent.GetPrice().Float64 != bars[i].Close {
+			t.Errorf("Unexpected event price - wanted %0.2f, got %0.2f", bars[i].Close, priceEvent.GetPrice().Float64)
+		}
+	}
+}
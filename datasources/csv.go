@@ -0,0 +1,157 @@
+package datasources
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+var csvDateLayouts = []string{"2006-01-02", time.RFC3339}
+
+// CsvSource streams asset price events from a csv file one row at a time.
+// The file must have a header row with a 'Date' column and either a 'Close'
+// or 'Price' column, with rows in ascending date order. Dates may be in
+// the form 2006-01-02 or RFC3339.
+type CsvSource struct {
+	newEvent   func(time.Time, asset.Price) events.IEvent
+	filePath   string
+	file       *os.File
+	reader     *csv.Reader
+	dateIndex  int
+	priceIndex int
+	lastTime   time.Time
+}
+
+// NewCsvSource opens a csv file and returns a new instance of CsvSource.
+func NewCsvSource(targetAsset asset.IAssetReadOnly, filePath string) (*CsvSource, error) {
+	writableAsset, ok := targetAsset.(asset.IAssetWriteOnly)
+	if !ok {
+		return nil, errors.New("Unable to cast to IAssetWriteOnly")
+	}
+
+	return openCsvSource(filePath, func(eventTime time.Time, price asset.Price) events.IEvent {
+		assetPriceEvent := events.NewAssetPriceEvent(writableAsset, eventTime, price)
+		return &assetPriceEvent
+	})
+}
+
+// NewCsvFxSource opens a csv file of FX rates in the same format
+// and returns a new instance of CsvSource that streams FX rate events.
+func NewCsvFxSource(rate *asset.FxRate, filePath string) (*CsvSource, error) {
+	if rate == nil {
+		return nil, errors.New("a csv fx source requires an fx rate")
+	}
+
+	return openCsvSource(filePath, func(eventTime time.Time, price asset.Price) events.IEvent {
+		fxRateEvent := events.NewFxRateEvent(rate, eventTime, price)
+		return &fxRateEvent
+	})
+}
+
+// openCsvSource opens a csv file and finds the date and price columns.
+func openCsvSource(filePath string, newEvent func(time.Time, asset.Price) events.IEvent) (*CsvSource, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(file)
+	headers, err := reader.Read()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("'%s' cannot read csv headers - %s", filePath, err)
+	}
+
+	source := CsvSource{
+		newEvent:   newEvent,
+		filePath:   filePath,
+		file:       file,
+		reader:     reader,
+		dateIndex:  -1,
+		priceIndex: -1,
+	}
+	for i, header := range headers {
+		switch btutil.CleanString(header) {
+		case "DATE":
+			source.dateIndex = i
+		case "CLOSE", "PRICE":
+			source.priceIndex = i
+		}
+	}
+	if source.dateIndex == -1 || source.priceIndex == -1 {
+		file.Close()
+		return nil, fmt.Errorf("'%s' requires 'Date' and 'Close' columns", filePath)
+	}
+
+	return &source, nil
+}
+
+// GetFilePath returns the csv file path.
+func (s *CsvSource) GetFilePath() string {
+	return s.filePath
+}
+
+// Next reads the next row and returns it as an asset price event.
+// The file is closed once all rows have been read.
+func (s *CsvSource) Next() (events.IEvent, bool, error) {
+	if s.file == nil {
+		return nil, false, nil
+	}
+
+	row, err := s.reader.Read()
+	if err == io.EOF {
+		return nil, false, s.Close()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	eventTime, err := parseCsvDate(row[s.dateIndex])
+	if err != nil {
+		return nil, false, err
+	}
+	if eventTime.Before(s.lastTime) {
+		return nil, false, fmt.Errorf("'%s' rows are not in date order at %s", s.filePath, row[s.dateIndex])
+	}
+	s.lastTime = eventTime
+
+	price := asset.Price{Float64: 0.0, Valid: false}
+	if row[s.priceIndex] != "" {
+		close, err := strconv.ParseFloat(row[s.priceIndex], 64)
+		if err != nil {
+			return nil, false, err
+		}
+		price = asset.Price{Float64: close, Valid: true}
+	}
+
+	return s.newEvent(eventTime, price), true, nil
+}
+
+// Close closes the underlying csv file.
+func (s *CsvSource) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func parseCsvDate(datestr string) (time.Time, error) {
+	var err error
+	for _, layout := range csvDateLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, datestr)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
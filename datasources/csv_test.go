@@ -0,0 +1,143 @@
+package datasources
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestCsv(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "csvsource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(dir, "prices.csv")
+	if err := ioutil.WriteFile(filePath, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestCsvSource(t *testing.T) {
+	filePath := writeTestCsv(t, "Date,Open,Close\n2021-04-01,1.0,1.5\n2021-04-02,1.5,\n2021-04-05T00:00:00Z,1.6,1.7\n")
+	defer os.RemoveAll(filepath.Dir(filePath))
+
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+
+	source, err := NewCsvSource(stock, filePath)
+	if err != nil {
+		t.Fatalf("Error in NewCsvSource - %s", err)
+	}
+	if source.GetFilePath() != filePath {
+		t.Error("Unexpected file path")
+	}
+
+	var list []events.IEvent
+	for {
+		event, ok, err := source.Next()
+		if err != nil {
+			t.Fatalf("Error in source.Next() - %s", err)
+		}
+		if !ok {
+			break
+		}
+		list = append(list, event)
+	}
+
+	if len(list) != 3 {
+		t.Fatalf("Expecting 3 events, got %d", len(list))
+	}
+	expectedTimes := []int{1, 2, 5}
+	for i, day := range expectedTimes {
+		if !list[i].GetTime().Equal(btutil.Date(2021, 4, day)) {
+			t.Errorf("Unexpected time for event %d", i)
+		}
+	}
+
+	price := list[0].(IEventHasPrice).GetPrice()
+	if !price.Valid || price.Float64 != 1.5 {
+		t.Errorf("Unexpected first price - %v", price)
+	}
+	if list[1].(IEventHasPrice).GetPrice().Valid {
+		t.Error("Expecting a blank close to give an invalid price")
+	}
+
+	// further calls once exhausted return nothing
+	_, ok, err := source.Next()
+	if ok || err != nil {
+		t.Error("Expecting an exhausted source")
+	}
+}
+
+func TestCsvSourceErrors(t *testing.T) {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+
+	_, err = NewCsvSource(stock, "does_not_exist.csv")
+	if err == nil {
+		t.Error("Expecting an error for a missing file")
+	}
+
+	filePath := writeTestCsv(t, "Date,Volume\n2021-04-01,100\n")
+	defer os.RemoveAll(filepath.Dir(filePath))
+	_, err = NewCsvSource(stock, filePath)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "'"+filePath+"' requires 'Date' and 'Close' columns" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	filePath = writeTestCsv(t, "Date,Price\n2021-04-02,1.0\n2021-04-01,1.0\n")
+	defer os.RemoveAll(filepath.Dir(filePath))
+	source, err := NewCsvSource(stock, filePath)
+	if err != nil {
+		t.Fatalf("Error in NewCsvSource - %s", err)
+	}
+	defer source.Close()
+	if _, _, err := source.Next(); err != nil {
+		t.Fatalf("Error in source.Next() - %s", err)
+	}
+	if _, _, err := source.Next(); err == nil {
+		t.Error("Expecting an error for rows out of date order")
+	}
+}
+
+func TestCsvFxSource(t *testing.T) {
+	filePath := writeTestCsv(t, "Date,Close\n2021-04-01,0.75\n2021-04-02,0.76\n")
+	defer os.RemoveAll(filepath.Dir(filePath))
+
+	if _, err := NewCsvFxSource(nil, filePath); err == nil {
+		t.Error("Expecting an error for a nil fx rate")
+	}
+	rate, err := asset.NewFxRate("AUDUSD", asset.Price{})
+	if err != nil {
+		t.Fatalf("Error in asset.NewFxRate - %s", err)
+	}
+	source, err := NewCsvFxSource(rate, filePath)
+	if err != nil {
+		t.Fatalf("Error in NewCsvFxSource - %s", err)
+	}
+
+	event, ok, err := source.Next()
+	if !ok || err != nil {
+		t.Fatalf("Error in source.Next() - %v", err)
+	}
+	if events.GetPhase(event) != events.PhaseFxRate {
+		t.Errorf("Unexpected phase for fx rate event - %d", events.GetPhase(event))
+	}
+	if err := event.Process(); err != nil {
+		t.Fatalf("Error in Process - %s", err)
+	}
+	if price := rate.GetRate(); !price.Valid || price.Float64 != 0.75 {
+		t.Errorf("Unexpected fx rate - %v", price)
+	}
+	source.Close()
+}
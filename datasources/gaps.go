@@ -0,0 +1,26 @@
+package datasources
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/calendar"
+	"gobacktrader/events"
+	"time"
+)
+
+// FindGaps returns the trading days with no event between the first and
+// last event, using the asset's trading calendar where one has been set
+// and a weekday calendar otherwise.
+func FindGaps(targetAsset asset.IAssetReadOnly, eventList []events.IEvent) []time.Time {
+	var cal calendar.ITradingCalendar = calendar.NewWeekdayCalendar()
+	if hasCalendar, ok := targetAsset.(asset.IHasCalendar); ok {
+		if assetCalendar, ok := hasCalendar.GetCalendar(); ok {
+			cal = assetCalendar
+		}
+	}
+
+	times := make([]time.Time, len(eventList))
+	for i, event := range eventList {
+		times[i] = event.GetTime()
+	}
+	return calendar.MissingTradingDays(cal, times)
+}
@@ -0,0 +1,55 @@
+package datasources
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/calendar"
+	"testing"
+	"time"
+)
+
+func TestFindGaps(t *testing.T) {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+
+	bars := []Bar{
+		{Time: btutil.Date(2021, 3, 31), Close: 1.0},
+		{Time: btutil.Date(2021, 4, 6), Close: 1.1},
+		{Time: btutil.Date(2021, 4, 8), Close: 1.2},
+	}
+	eventList := BarsToEvents(stock, bars)
+
+	// with a weekday calendar Easter is a gap in the data
+	gaps := FindGaps(stock, eventList)
+	expected := []time.Time{
+		btutil.Date(2021, 4, 1),
+		btutil.Date(2021, 4, 2),
+		btutil.Date(2021, 4, 5),
+		btutil.Date(2021, 4, 7),
+	}
+	if len(gaps) != len(expected) {
+		t.Fatalf("Expecting %d gaps, got %d - %v", len(expected), len(gaps), gaps)
+	}
+
+	// but not once the exchange calendar is attached
+	cal, err := calendar.NewCalendar("ASX", time.UTC)
+	if err != nil {
+		t.Fatalf("Error in NewCalendar - %s", err)
+	}
+	cal.AddHoliday(calendar.NewEasterOffset(-2))
+	cal.AddHoliday(calendar.NewEasterOffset(1))
+	stock.SetCalendar(cal)
+
+	gaps = FindGaps(stock, eventList)
+	expected = []time.Time{btutil.Date(2021, 4, 1), btutil.Date(2021, 4, 7)}
+	if len(gaps) != len(expected) {
+		t.Fatalf("Expecting %d gaps, got %d - %v", len(expected), len(gaps), gaps)
+	}
+	for i := range expected {
+		if !gaps[i].Equal(expected[i]) {
+			t.Errorf("Unexpected gap %s", gaps[i])
+		}
+	}
+}
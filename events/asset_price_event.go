@@ -29,7 +29,17 @@ func (e *AssetPriceEvent) Process() error {
 	return nil
 }
 
+// GetAsset returns the asset whose price is set by the event.
+func (e AssetPriceEvent) GetAsset() asset.IAssetWriteOnly {
+	return e.targetAsset
+}
+
 // GetPrice returns the event price.
 func (e AssetPriceEvent) GetPrice() asset.Price {
 	return e.price
 }
+
+// GetPhase returns the event phase.
+func (e AssetPriceEvent) GetPhase() Phase {
+	return PhasePrice
+}
@@ -22,6 +22,9 @@ func TestAssetPriceEvent(t *testing.T) {
 	if assetPriceEvent.IsProcessed() == true {
 		t.Error("This event should be new and unprocessed.")
 	}
+	if assetPriceEvent.GetAsset() != stock {
+		t.Error("Unexpected event asset.")
+	}
 
 	// process this event
 	assetPriceEvent.Process()
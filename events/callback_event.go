@@ -0,0 +1,35 @@
+package events
+
+import "time"
+
+// Callback defines a function that is called at some event time.
+type Callback func(time.Time) error
+
+// CallbackEvent calls a function when processed.
+type CallbackEvent struct {
+	BaseEvent
+	callback Callback
+}
+
+// NewCallbackEvent returns a new instance of CallbackEvent.
+func NewCallbackEvent(eventTime time.Time, callback Callback) CallbackEvent {
+	return CallbackEvent{
+		BaseEvent: BaseEvent{eventTime: eventTime, processed: false},
+		callback:  callback,
+	}
+}
+
+// GetPhase returns the event phase. Callbacks run once all
+// other events for the time step have been processed.
+func (e *CallbackEvent) GetPhase() Phase {
+	return PhaseEndOfStep
+}
+
+// Process calls the callback with the event time.
+func (e *CallbackEvent) Process() error {
+	if err := e.callback(e.eventTime); err != nil {
+		return err
+	}
+	e.processed = true
+	return nil
+}
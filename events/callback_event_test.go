@@ -0,0 +1,48 @@
+package events
+
+import (
+	"errors"
+	"gobacktrader/btutil"
+	"testing"
+	"time"
+)
+
+func TestCallbackEvent(t *testing.T) {
+	eventTime := time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC)
+	var calledAt time.Time
+	event := NewCallbackEvent(eventTime, func(t time.Time) error {
+		calledAt = t
+		return nil
+	})
+
+	if !event.GetTime().Equal(eventTime) {
+		t.Error("Unexpected event time.")
+	}
+	if event.IsProcessed() {
+		t.Error("This event should be new and unprocessed.")
+	}
+	if GetPhase(&event) != PhaseEndOfStep {
+		t.Error("Expecting callbacks to run at the end of the step")
+	}
+
+	if err := event.Process(); err != nil {
+		t.Fatalf("Error in event.Process() - %s", err)
+	}
+	if !calledAt.Equal(eventTime) {
+		t.Error("Expecting the callback to receive the event time")
+	}
+	if !event.IsProcessed() {
+		t.Error("Expecting this event to be processed.")
+	}
+
+	failing := NewCallbackEvent(eventTime, func(t time.Time) error {
+		return errors.New("this is a test error")
+	})
+	err := failing.Process()
+	if btutil.GetErrorString(err) != "this is a test error" {
+		t.Errorf("Unexpected error string - %s", btutil.GetErrorString(err))
+	}
+	if failing.IsProcessed() {
+		t.Error("A failed callback should not be processed")
+	}
+}
@@ -1,8 +1,8 @@
 package events
 
 import (
+	"container/heap"
 	"errors"
-	"sort"
 	"time"
 )
 
@@ -29,27 +29,76 @@ func (e BaseEvent) IsProcessed() bool {
 	return e.processed
 }
 
-// Events represents a collection of events.
+// queueItem pairs an event with its phase and the order in which it
+// was added so that events sharing a timestamp have a deterministic order.
+// The event time and phase are cached to avoid repeated interface calls.
+type queueItem struct {
+	event     IEvent
+	eventTime time.Time
+	phase     Phase
+	seq       uint64
+}
+
+// eventHeap implements heap.Interface ordered by event time,
+// then by phase and then by sequence number.
+type eventHeap []queueItem
+
+func (h eventHeap) Len() int {
+	return len(h)
+}
+
+func (h eventHeap) Less(i, j int) bool {
+	ti, tj := h[i].eventTime, h[j].eventTime
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	if h[i].phase != h[j].phase {
+		return h[i].phase < h[j].phase
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h eventHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *eventHeap) Push(x interface{}) {
+	*h = append(*h, x.(queueItem))
+}
+
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = queueItem{} // release the event for garbage collection
+	*h = old[:n-1]
+	return item
+}
+
+// Events represents a collection of events held in a priority
+// queue ordered by event time. Events with equal timestamps are
+// returned by phase and then in the order in which they were added.
+// Events must be used through the pointer returned by NewEvents, as a
+// copy would share its membership with the original.
 type Events struct {
-	list []IEvent
+	queue   eventHeap
+	members map[IEvent]struct{}
+	seq     uint64
 }
 
 // NewEvents returns a new empty events collection.
-func NewEvents() Events {
-	return Events{}
+func NewEvents() *Events {
+	return &Events{}
 }
 
-// Len returns the number of event dates.
-func (e Events) Len() int {
-	return len(e.list)
+// Len returns the number of events.
+func (e *Events) Len() int {
+	return len(e.queue)
 }
 
 // IsEmpty returns true if the events list is empty.
-func (e Events) IsEmpty() bool {
-	if len(e.list) == 0 {
-		return true
-	}
-	return false
+func (e *Events) IsEmpty() bool {
+	return len(e.queue) == 0
 }
 
 // Add will add an event to the events collection.
@@ -57,7 +106,18 @@ func (e *Events) Add(event IEvent) {
 	if e.Contains(event) {
 		return // event is already in the list
 	}
-	e.list = insertSort(e.list, event)
+	if e.members == nil {
+		e.members = make(map[IEvent]struct{})
+	}
+	e.members[event] = struct{}{}
+	item := queueItem{
+		event:     event,
+		eventTime: event.GetTime(),
+		phase:     GetPhase(event),
+		seq:       e.seq,
+	}
+	heap.Push(&e.queue, item)
+	e.seq++
 }
 
 // Get will return the most recent event.
@@ -65,9 +125,17 @@ func (e *Events) Get() (IEvent, error) {
 	if e.IsEmpty() {
 		return nil, errors.New("the events list is empty")
 	}
-	event := e.list[0]
-	e.list = e.list[1:]
-	return event, nil
+	item := heap.Pop(&e.queue).(queueItem)
+	delete(e.members, item.event)
+	return item.event, nil
+}
+
+// Peek returns the most recent event without removing it.
+func (e *Events) Peek() (IEvent, error) {
+	if e.IsEmpty() {
+		return nil, errors.New("the events list is empty")
+	}
+	return e.queue[0].event, nil
 }
 
 // FetchOne returns the most recent event.
@@ -75,16 +143,20 @@ func (e *Events) FetchOne() (IEvent, error) {
 	return e.Get()
 }
 
-// FetchAll returns all events from the list.
+// FetchAll returns all events from the list in time order.
 func (e *Events) FetchAll() []IEvent {
-	list := e.list
-	e.list = nil
+	var list []IEvent
+	for !e.IsEmpty() {
+		event, _ := e.Get()
+		list = append(list, event)
+	}
 	return list
 }
 
 // FetchNextGroup returns all events that have the most recent time stamp.
 // For example, the next event time may be 11am for a stock price event,
 // but several FX rate events are also occurring at this time.
+// The group is ordered by phase, so here the FX rate events come first.
 func (e *Events) FetchNextGroup() ([]IEvent, error) {
 	var events []IEvent
 	nextEvent, err := e.FetchOne()
@@ -94,37 +166,16 @@ func (e *Events) FetchNextGroup() ([]IEvent, error) {
 
 	events = append(events, nextEvent)
 	nextEventTime := nextEvent.GetTime()
-	counter := 0
-	for _, event := range e.list {
-		if event.GetTime().Equal(nextEventTime) {
-			events = append(events, event)
-			counter++
-		} else {
-			break
-		}
+	for !e.IsEmpty() && e.queue[0].eventTime.Equal(nextEventTime) {
+		event, _ := e.Get()
+		events = append(events, event)
 	}
 
-	if counter != 0 {
-		e.list = e.list[counter:]
-	}
 	return events, nil
 }
 
 // Contains returns true if some event is in the list, false otherwise
-func (e Events) Contains(event IEvent) bool {
-	for _, listEvent := range e.list {
-		if listEvent == event {
-			return true
-		}
-	}
-	return false
-}
-
-func insertSort(events []IEvent, event IEvent) []IEvent {
-	eventTime := event.GetTime()
-	index := sort.Search(len(events), func(i int) bool { return events[i].GetTime().After(eventTime) })
-	events = append(events, event)
-	copy(events[index+1:], events[index:])
-	events[index] = event
-	return events
+func (e *Events) Contains(event IEvent) bool {
+	_, ok := e.members[event]
+	return ok
 }
@@ -2,6 +2,7 @@ package events
 
 import (
 	"gobacktrader/btutil"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -207,3 +208,140 @@ func TestEventsFetchNextGroup(t *testing.T) {
 		t.Errorf("Unexpected error string, got '%s'", errStr)
 	}
 }
+
+func TestEventsPeek(t *testing.T) {
+	events := NewEvents()
+	_, err := events.Peek()
+	errStr := btutil.GetErrorString(err)
+	if errStr != "the events list is empty" {
+		t.Errorf("Unexpected error string, got '%s'", errStr)
+	}
+
+	time1 := time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC)
+	time2 := time.Date(2020, time.December, 15, 0, 0, 0, 0, time.UTC)
+	event1 := newTestEvent(time1)
+	event2 := newTestEvent(time2)
+	events.Add(&event2)
+	events.Add(&event1)
+
+	event, err := events.Peek()
+	if err != nil {
+		t.Fatalf("Error in events.Peek - %s", err)
+	}
+	if event != &event1 {
+		t.Error("Expecting event1 to be returned")
+	}
+	if events.Len() != 2 {
+		t.Errorf("Expecting Peek to leave 2 events, got %d", events.Len())
+	}
+}
+
+func TestEventsStableOrder(t *testing.T) {
+	events := NewEvents()
+	time1 := time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC)
+	time2 := time.Date(2020, time.December, 15, 0, 0, 0, 0, time.UTC)
+
+	// events sharing a timestamp should come back in the order they were added
+	var added []IEvent
+	for i := 0; i < 50; i++ {
+		eventTime := time1
+		if i%2 == 0 {
+			eventTime = time2
+		}
+		event := newTestEvent(eventTime)
+		events.Add(&event)
+		added = append(added, &event)
+	}
+
+	group1, err := events.FetchNextGroup()
+	if err != nil {
+		t.Fatalf("Error in FetchNextGroup - %s", err)
+	}
+	group2, err := events.FetchNextGroup()
+	if err != nil {
+		t.Fatalf("Error in FetchNextGroup - %s", err)
+	}
+	if len(group1) != 25 || len(group2) != 25 {
+		t.Fatalf("Expecting two groups of 25 events, got %d and %d", len(group1), len(group2))
+	}
+
+	for i := 0; i < 25; i++ {
+		if group1[i] != added[2*i+1] {
+			t.Errorf("Unexpected event at index %d of group1", i)
+		}
+		if group2[i] != added[2*i] {
+			t.Errorf("Unexpected event at index %d of group2", i)
+		}
+	}
+}
+
+func TestEventsReAdd(t *testing.T) {
+	events := NewEvents()
+	event := newTestEvent(time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC))
+	events.Add(&event)
+	if !events.Contains(&event) {
+		t.Error("Expecting the event to be contained")
+	}
+
+	events.Get()
+	if events.Contains(&event) {
+		t.Error("Expecting the event to be removed")
+	}
+
+	// once removed the same event can be added again
+	events.Add(&event)
+	if events.Len() != 1 {
+		t.Errorf("Expecting an events.Len of 1, got %d", events.Len())
+	}
+}
+
+func newBenchmarkEvents(n int) []IEvent {
+	start := time.Date(2010, time.January, 1, 0, 0, 0, 0, time.UTC)
+	rng := rand.New(rand.NewSource(1))
+	list := make([]IEvent, n)
+	for i := range list {
+		// spread events over ten years of days in random order
+		event := newTestEvent(start.AddDate(0, 0, rng.Intn(3650)))
+		list[i] = &event
+	}
+	return list
+}
+
+func BenchmarkEventsAdd(b *testing.B) {
+	list := newBenchmarkEvents(b.N)
+	events := NewEvents()
+	b.ResetTimer()
+	for _, event := range list {
+		events.Add(event)
+	}
+}
+
+func BenchmarkEventsContains(b *testing.B) {
+	list := newBenchmarkEvents(100000)
+	events := NewEvents()
+	for _, event := range list {
+		events.Add(event)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		events.Contains(list[i%len(list)])
+	}
+}
+
+func benchmarkEventsAddDrain(b *testing.B, n int) {
+	list := newBenchmarkEvents(n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		events := NewEvents()
+		for _, event := range list {
+			events.Add(event)
+		}
+		for !events.IsEmpty() {
+			events.FetchNextGroup()
+		}
+	}
+}
+
+func BenchmarkEventsAddDrain1K(b *testing.B)   { benchmarkEventsAddDrain(b, 1000) }
+func BenchmarkEventsAddDrain100K(b *testing.B) { benchmarkEventsAddDrain(b, 100000) }
+func BenchmarkEventsAddDrain1M(b *testing.B)   { benchmarkEventsAddDrain(b, 1000000) }
@@ -11,6 +11,12 @@ type FxRateEvent struct {
 }
 
 // NewFxRateEvent returns a new instance of FxRateEvent.
-func NewFxRateEvent(rate *asset.FxRate, eventTime time.Time, price asset.Price) AssetPriceEvent {
-	return NewAssetPriceEvent(rate, eventTime, price)
+func NewFxRateEvent(rate *asset.FxRate, eventTime time.Time, price asset.Price) FxRateEvent {
+	return FxRateEvent{AssetPriceEvent: NewAssetPriceEvent(rate, eventTime, price)}
+}
+
+// GetPhase returns the event phase. FX rates are updated
+// before prices sharing the same time.
+func (e FxRateEvent) GetPhase() Phase {
+	return PhaseFxRate
 }
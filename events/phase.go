@@ -0,0 +1,41 @@
+package events
+
+// Phase determines the order in which events sharing a timestamp are
+// processed, with lower phases processed first. Within a time step the
+// built in phases run in the following order:
+//
+//	PhaseCorporateAction - splits, dividends and other corporate actions
+//	PhaseFxRate          - FX rate updates
+//	PhasePrice           - asset price updates
+//	PhaseCashFlow        - scheduled cash flows such as deposits and fees
+//	PhaseTrade           - trades scheduled for this time
+//	PhaseEndOfStep       - callbacks that should see the final state of the step
+//
+// Events with the same time and phase are processed in the order they were
+// added. The built in phases are spaced apart so custom event types can
+// declare a phase that falls between them, e.g. PhasePrice + 1.
+type Phase int
+
+// The built in event phases.
+const (
+	PhaseCorporateAction Phase = 100
+	PhaseFxRate          Phase = 200
+	PhasePrice           Phase = 300
+	PhaseCashFlow        Phase = 400
+	PhaseTrade           Phase = 500
+	PhaseEndOfStep       Phase = 600
+)
+
+// IHasPhase defines the interface for events that declare their phase.
+type IHasPhase interface {
+	GetPhase() Phase
+}
+
+// GetPhase returns the phase for some event. Events that
+// do not declare a phase are processed in PhasePrice.
+func GetPhase(event IEvent) Phase {
+	if hasPhase, ok := event.(IHasPhase); ok {
+		return hasPhase.GetPhase()
+	}
+	return PhasePrice
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/trade"
+	"testing"
+	"time"
+)
+
+// phasedTestEvent is a custom event type that declares its phase.
+type phasedTestEvent struct {
+	testEvent
+	phase Phase
+}
+
+func (e *phasedTestEvent) GetPhase() Phase {
+	return e.phase
+}
+
+func TestPhaseOrder(t *testing.T) {
+	phases := []Phase{
+		PhaseCorporateAction,
+		PhaseFxRate,
+		PhasePrice,
+		PhaseCashFlow,
+		PhaseTrade,
+		PhaseEndOfStep,
+	}
+	for i := 1; i < len(phases); i++ {
+		if phases[i] <= phases[i-1] {
+			t.Errorf("Phase %d should come after phase %d", phases[i], phases[i-1])
+		}
+	}
+}
+
+func TestGetPhase(t *testing.T) {
+	eventTime := time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC)
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+	audusd, err := asset.NewFxRate("AUDUSD", asset.Price{Float64: 0.75, Valid: true})
+	if err != nil {
+		t.Fatalf("Error in asset.NewFxRate - %s", err)
+	}
+	portfolio, err := asset.NewPortfolio("XXX", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewPortfolio - %s", err)
+	}
+
+	priceEvent := NewAssetPriceEvent(stock, eventTime, asset.Price{Float64: 1.0, Valid: true})
+	fxRateEvent := NewFxRateEvent(audusd, eventTime, asset.Price{Float64: 0.8, Valid: true})
+	tradeEvent := NewTradeEvent(trade.NewTrade(portfolio, stock, 100), eventTime)
+	plainEvent := newTestEvent(eventTime)
+
+	tests := []struct {
+		event IEvent
+		phase Phase
+	}{
+		{&priceEvent, PhasePrice},
+		{&fxRateEvent, PhaseFxRate},
+		{&tradeEvent, PhaseTrade},
+		{&plainEvent, PhasePrice}, // no declared phase
+	}
+	for _, test := range tests {
+		if phase := GetPhase(test.event); phase != test.phase {
+			t.Errorf("Unexpected phase - wanted %d, got %d", test.phase, phase)
+		}
+	}
+}
+
+func TestEventsHonourPhase(t *testing.T) {
+	time1 := time.Date(2021, time.March, 13, 0, 0, 0, 0, time.UTC)
+	time2 := time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	endOfStep := &phasedTestEvent{newTestEvent(time1), PhaseEndOfStep}
+	trade1 := &phasedTestEvent{newTestEvent(time1), PhaseTrade}
+	trade2 := &phasedTestEvent{newTestEvent(time1), PhaseTrade}
+	price := &phasedTestEvent{newTestEvent(time1), PhasePrice}
+	afterPrice := &phasedTestEvent{newTestEvent(time1), PhasePrice + 1}
+	fxRate := &phasedTestEvent{newTestEvent(time1), PhaseFxRate}
+	corporateAction := &phasedTestEvent{newTestEvent(time1), PhaseCorporateAction}
+	earlierPhaseLaterTime := &phasedTestEvent{newTestEvent(time2), PhaseCorporateAction}
+
+	events := NewEvents()
+	for _, event := range []IEvent{earlierPhaseLaterTime, endOfStep, trade1, afterPrice, price, trade2, fxRate, corporateAction} {
+		events.Add(event)
+	}
+
+	group, err := events.FetchNextGroup()
+	if err != nil {
+		t.Fatalf("Error in FetchNextGroup - %s", err)
+	}
+	expected := []IEvent{corporateAction, fxRate, price, afterPrice, trade1, trade2, endOfStep}
+	if len(group) != len(expected) {
+		t.Fatalf("Expecting %d events, got %d", len(expected), len(group))
+	}
+	for i := range expected {
+		if group[i] != expected[i] {
+			t.Errorf("Unexpected event at index %d", i)
+		}
+	}
+
+	// time takes precedence over phase
+	group, err = events.FetchNextGroup()
+	if err != nil {
+		t.Fatalf("Error in FetchNextGroup - %s", err)
+	}
+	if len(group) != 1 || group[0] != earlierPhaseLaterTime {
+		t.Error("Expecting the later event in its own group")
+	}
+}
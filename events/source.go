@@ -0,0 +1,189 @@
+package events
+
+import (
+	"container/heap"
+	"fmt"
+	"sort"
+)
+
+// IEventSource defines the interface for sources that yield events
+// lazily in time order. Next returns false once the source is exhausted.
+type IEventSource interface {
+	Next() (IEvent, bool, error)
+}
+
+// SliceSource yields events from a slice in time order.
+type SliceSource struct {
+	list []IEvent
+}
+
+// NewSliceSource returns a new instance of SliceSource.
+// The events are sorted by time, with events sharing a
+// timestamp keeping their original order.
+func NewSliceSource(list []IEvent) *SliceSource {
+	sorted := make([]IEvent, len(list))
+	copy(sorted, list)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetTime().Before(sorted[j].GetTime())
+	})
+	return &SliceSource{list: sorted}
+}
+
+// Next returns the next event from the slice.
+func (s *SliceSource) Next() (IEvent, bool, error) {
+	if len(s.list) == 0 {
+		return nil, false, nil
+	}
+	event := s.list[0]
+	s.list[0] = nil
+	s.list = s.list[1:]
+	return event, true, nil
+}
+
+// ChannelSource yields events received on a channel.
+// Events must be sent in time order and the channel
+// closed once there are no more events.
+type ChannelSource struct {
+	ch <-chan IEvent
+}
+
+// NewChannelSource returns a new instance of ChannelSource.
+func NewChannelSource(ch <-chan IEvent) *ChannelSource {
+	return &ChannelSource{ch: ch}
+}
+
+// Next returns the next event received on the channel.
+func (s *ChannelSource) Next() (IEvent, bool, error) {
+	event, ok := <-s.ch
+	return event, ok, nil
+}
+
+// sourceHead holds the next event for some source.
+type sourceHead struct {
+	source IEventSource
+	event  IEvent
+	index  int
+}
+
+// sourceHeap implements heap.Interface ordered by the time of
+// each source's next event and then by the order sources were added.
+type sourceHeap []*sourceHead
+
+func (h sourceHeap) Len() int {
+	return len(h)
+}
+
+func (h sourceHeap) Less(i, j int) bool {
+	ti, tj := h[i].event.GetTime(), h[j].event.GetTime()
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return h[i].index < h[j].index
+}
+
+func (h sourceHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *sourceHeap) Push(x interface{}) {
+	*h = append(*h, x.(*sourceHead))
+}
+
+func (h *sourceHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	head := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return head
+}
+
+// MergedSource performs a k-way merge across many event sources,
+// yielding their events in time order while only holding the next
+// event from each source in memory.
+type MergedSource struct {
+	heads   sourceHeap
+	pending []IEventSource
+	count   int
+}
+
+// NewMergedSource returns a new instance of MergedSource.
+func NewMergedSource(sources ...IEventSource) *MergedSource {
+	merged := &MergedSource{}
+	for _, source := range sources {
+		merged.Add(source)
+	}
+	return merged
+}
+
+// Add adds another source to the merge.
+func (m *MergedSource) Add(source IEventSource) {
+	m.pending = append(m.pending, source)
+}
+
+// NumSources returns the number of sources that may still yield events.
+func (m *MergedSource) NumSources() int {
+	return len(m.heads) + len(m.pending)
+}
+
+// advance reads the next event from a source and pushes it onto the heap,
+// dropping the source once it is exhausted.
+func (m *MergedSource) advance(head *sourceHead) error {
+	previous := head.event
+	event, ok, err := head.source.Next()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	if previous != nil && event.GetTime().Before(previous.GetTime()) {
+		return fmt.Errorf("event source returned an event at %s after one at %s", event.GetTime(), previous.GetTime())
+	}
+
+	head.event = event
+	heap.Push(&m.heads, head)
+	return nil
+}
+
+// prime reads the first event from any newly added sources. A source
+// stays pending until it has been read, so a source that fails is tried
+// again, along with those added after it, on the next call.
+func (m *MergedSource) prime() error {
+	for len(m.pending) > 0 {
+		head := &sourceHead{source: m.pending[0], index: m.count}
+		if err := m.advance(head); err != nil {
+			return err
+		}
+		m.count++
+		m.pending = m.pending[1:]
+	}
+	return nil
+}
+
+// Peek returns the next event without consuming it.
+func (m *MergedSource) Peek() (IEvent, bool, error) {
+	if err := m.prime(); err != nil {
+		return nil, false, err
+	}
+	if len(m.heads) == 0 {
+		return nil, false, nil
+	}
+	return m.heads[0].event, true, nil
+}
+
+// Next returns the next event across all sources. Where the source of
+// that event fails to read its following event, the event is returned
+// along with the error and the failing source is dropped.
+func (m *MergedSource) Next() (IEvent, bool, error) {
+	if err := m.prime(); err != nil {
+		return nil, false, err
+	}
+	if len(m.heads) == 0 {
+		return nil, false, nil
+	}
+
+	head := heap.Pop(&m.heads).(*sourceHead)
+	event := head.event
+	return event, true, m.advance(head)
+}
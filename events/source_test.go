@@ -0,0 +1,170 @@
+package events
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type errorSource struct{}
+
+func (s errorSource) Next() (IEvent, bool, error) {
+	return nil, false, errors.New("this is a test error")
+}
+
+// flakySource fails on its first read then yields one event.
+type flakySource struct {
+	event  IEvent
+	failed bool
+	done   bool
+}
+
+func (s *flakySource) Next() (IEvent, bool, error) {
+	if !s.failed {
+		s.failed = true
+		return nil, false, errors.New("this is a test error")
+	}
+	if s.done {
+		return nil, false, nil
+	}
+	s.done = true
+	return s.event, true, nil
+}
+
+func drainSource(t *testing.T, source IEventSource) []IEvent {
+	var list []IEvent
+	for {
+		event, ok, err := source.Next()
+		if err != nil {
+			t.Fatalf("Error in source.Next() - %s", err)
+		}
+		if !ok {
+			return list
+		}
+		list = append(list, event)
+	}
+}
+
+func TestSliceSource(t *testing.T) {
+	time1 := time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC)
+	time2 := time.Date(2020, time.December, 15, 0, 0, 0, 0, time.UTC)
+	e1 := newTestEvent(time2)
+	e2 := newTestEvent(time1)
+	e3 := newTestEvent(time2)
+
+	source := NewSliceSource([]IEvent{&e1, &e2, &e3})
+	list := drainSource(t, source)
+	expected := []IEvent{&e2, &e1, &e3}
+	if len(list) != len(expected) {
+		t.Fatalf("Expecting %d events, got %d", len(expected), len(list))
+	}
+	for i := range expected {
+		if list[i] != expected[i] {
+			t.Errorf("Unexpected event at index %d", i)
+		}
+	}
+}
+
+func TestChannelSource(t *testing.T) {
+	ch := make(chan IEvent)
+	start := time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC)
+	go func() {
+		for i := 0; i < 5; i++ {
+			event := newTestEvent(start.AddDate(0, 0, i))
+			ch <- &event
+		}
+		close(ch)
+	}()
+
+	list := drainSource(t, NewChannelSource(ch))
+	if len(list) != 5 {
+		t.Fatalf("Expecting 5 events, got %d", len(list))
+	}
+	if !list[4].GetTime().Equal(start.AddDate(0, 0, 4)) {
+		t.Error("Unexpected time for the last event")
+	}
+}
+
+func TestMergedSource(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2020, time.December, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	a1, a2, a3 := newTestEvent(day(1)), newTestEvent(day(3)), newTestEvent(day(5))
+	b1, b2 := newTestEvent(day(2)), newTestEvent(day(3))
+	c1 := newTestEvent(day(4))
+
+	merged := NewMergedSource(
+		NewSliceSource([]IEvent{&a1, &a2, &a3}),
+		NewSliceSource([]IEvent{&b1, &b2}),
+		NewSliceSource(nil),
+	)
+	merged.Add(NewSliceSource([]IEvent{&c1}))
+	if merged.NumSources() != 4 {
+		t.Errorf("Expecting 4 sources, got %d", merged.NumSources())
+	}
+
+	event, ok, err := merged.Peek()
+	if err != nil || !ok {
+		t.Fatalf("Expecting an event from merged.Peek() - %v", err)
+	}
+	if event != &a1 {
+		t.Error("Expecting Peek to return a1")
+	}
+
+	// events sharing a time come back in the order the sources were added
+	list := drainSource(t, merged)
+	expected := []IEvent{&a1, &b1, &a2, &b2, &c1, &a3}
+	if len(list) != len(expected) {
+		t.Fatalf("Expecting %d events, got %d", len(expected), len(list))
+	}
+	for i := range expected {
+		if list[i] != expected[i] {
+			t.Errorf("Unexpected event at index %d", i)
+		}
+	}
+	if merged.NumSources() != 0 {
+		t.Errorf("Expecting all sources to be exhausted, got %d", merged.NumSources())
+	}
+}
+
+func TestMergedSourceErrors(t *testing.T) {
+	merged := NewMergedSource(errorSource{})
+	_, _, err := merged.Next()
+	if err == nil || err.Error() != "this is a test error" {
+		t.Errorf("Unexpected error - %v", err)
+	}
+
+	// sources must yield their events in time order
+	ch := make(chan IEvent, 2)
+	e1 := newTestEvent(time.Date(2020, time.December, 15, 0, 0, 0, 0, time.UTC))
+	e2 := newTestEvent(time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC))
+	ch <- &e1
+	ch <- &e2
+	close(ch)
+
+	merged = NewMergedSource(NewChannelSource(ch))
+	event, ok, err := merged.Next()
+	if err == nil {
+		t.Error("Expecting an error for out of order events")
+	}
+	if !ok || event != &e1 {
+		t.Error("Expecting the popped event to be returned with the error")
+	}
+
+	// sources after one that fails to prime are not dropped
+	e3 := newTestEvent(time.Date(2020, time.December, 16, 0, 0, 0, 0, time.UTC))
+	e4 := newTestEvent(time.Date(2020, time.December, 17, 0, 0, 0, 0, time.UTC))
+	flaky := &flakySource{event: &e4}
+	merged = NewMergedSource(flaky, NewSliceSource([]IEvent{&e3}))
+	if _, _, err := merged.Next(); err == nil {
+		t.Error("Expecting an error from the flaky source")
+	}
+	if merged.NumSources() != 2 {
+		t.Errorf("Expecting both sources to remain, got %d", merged.NumSources())
+	}
+	list := drainSource(t, merged)
+	if len(list) != 2 || list[0] != &e3 || list[1] != &e4 {
+		t.Error("Unexpected events after retrying the flaky source")
+	}
+}
@@ -24,6 +24,11 @@ func (e *TradeEvent) GetTrade() *trade.Trade {
 	return e.trade
 }
 
+// GetPhase returns the event phase.
+func (e *TradeEvent) GetPhase() Phase {
+	return PhaseTrade
+}
+
 // Process with execute the trade if it passes compliance.
 func (e *TradeEvent) Process() error {
 	_, err := e.trade.Execute()
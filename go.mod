@@ -4,9 +4,10 @@ go 1.13
 
 require (
 	github.com/fatih/color v1.10.0 // indirect
-	github.com/fogleman/gg v1.3.0 // indirect
+	github.com/fogleman/gg v1.3.0
 	github.com/go-echarts/go-echarts/v2 v2.2.4
 	github.com/golang/freetype v0.0.0-20170609003504-e2365dfdc4a0 // indirect
 	github.com/jpoles1/gopherbadger v2.4.0+incompatible // indirect
 	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
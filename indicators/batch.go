@@ -0,0 +1,82 @@
+package indicators
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/datasources"
+	"sort"
+	"time"
+)
+
+// Point holds the value of an indicator at some time.
+type Point struct {
+	Time     time.Time
+	Value    float64
+	WarmedUp bool
+}
+
+// sortedTimes returns the times of a history in ascending order.
+func sortedTimes(history asset.History) []time.Time {
+	times := make([]time.Time, 0, len(history))
+	for snapshotTime := range history {
+		times = append(times, snapshotTime)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// Apply updates an indicator with each valid price in a history,
+// in time order, and returns the indicator value after each update.
+func Apply(indicator IIndicator, history asset.History) []Point {
+	var points []Point
+	for _, snapshotTime := range sortedTimes(history) {
+		price := history[snapshotTime].GetPrice()
+		if !price.Valid {
+			continue
+		}
+		indicator.Update(price.Float64)
+		points = append(points, Point{
+			Time:     snapshotTime,
+			Value:    indicator.GetValue(),
+			WarmedUp: indicator.IsWarmedUp(),
+		})
+	}
+	return points
+}
+
+// ApplyPair updates a pair indicator at each time where both
+// histories have valid prices and returns the value after each update.
+func ApplyPair(indicator IPairIndicator, x asset.History, y asset.History) []Point {
+	var points []Point
+	for _, snapshotTime := range sortedTimes(x) {
+		ySnapshot, ok := y[snapshotTime]
+		if !ok {
+			continue
+		}
+		xPrice, yPrice := x[snapshotTime].GetPrice(), ySnapshot.GetPrice()
+		if !xPrice.Valid || !yPrice.Valid {
+			continue
+		}
+		indicator.Update(xPrice.Float64, yPrice.Float64)
+		points = append(points, Point{
+			Time:     snapshotTime,
+			Value:    indicator.GetValue(),
+			WarmedUp: indicator.IsWarmedUp(),
+		})
+	}
+	return points
+}
+
+// ApplyBars updates an average true range with each bar
+// and returns the value after each update.
+func ApplyBars(indicator *ATR, bars []datasources.Bar) []Point {
+	var points []Point
+	for _, bar := range bars {
+		indicator.UpdateBar(bar.High, bar.Low, bar.Close)
+		points = append(points, Point{
+			Time:     bar.Time,
+			Value:    indicator.GetValue(),
+			WarmedUp: indicator.IsWarmedUp(),
+		})
+	}
+	return points
+}
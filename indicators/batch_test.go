@@ -0,0 +1,64 @@
+package indicators
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/datasources"
+	"testing"
+)
+
+// buildHistory returns a price history for consecutive days.
+func buildHistory(t *testing.T, prices ...float64) asset.History {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in NewStock - %s", err)
+	}
+	for i, price := range prices {
+		stock.SetPrice(asset.Price{Float64: price, Valid: true})
+		stock.TakeSnapshot(btutil.Date(2021, 3, 1+i), stock)
+	}
+	return stock.GetHistory()
+}
+
+func TestApply(t *testing.T) {
+	history := buildHistory(t, 3, 1, 2)
+	sma, _ := NewSMA(2)
+	points := Apply(sma, history)
+	if len(points) != 3 {
+		t.Fatalf("Unexpected number of points - wanted 3, got %d", len(points))
+	}
+	if points[0].WarmedUp || !points[1].WarmedUp {
+		t.Error("Unexpected warm-up flags")
+	}
+	if !points[0].Time.Equal(btutil.Date(2021, 3, 1)) {
+		t.Error("Expecting points in time order")
+	}
+	if btutil.Round4dp(points[1].Value) != 2 || btutil.Round4dp(points[2].Value) != 1.5 {
+		t.Errorf("Unexpected values - %v", points)
+	}
+}
+
+func TestApplyPair(t *testing.T) {
+	x := buildHistory(t, 1, 2, 3)
+	y := buildHistory(t, 2, 4)
+	corr, _ := NewCorrelation(2)
+	points := ApplyPair(corr, x, y)
+	if len(points) != 2 {
+		t.Fatalf("Unexpected number of points - wanted 2, got %d", len(points))
+	}
+	if btutil.Round4dp(points[1].Value) != 1 {
+		t.Errorf("Unexpected correlation - wanted 1, got %0.4f", points[1].Value)
+	}
+}
+
+func TestApplyBars(t *testing.T) {
+	bars := []datasources.Bar{
+		{Time: btutil.Date(2021, 3, 1), High: 10, Low: 8, Close: 9},
+		{Time: btutil.Date(2021, 3, 2), High: 11, Low: 9, Close: 10},
+	}
+	atr, _ := NewATR(2)
+	points := ApplyBars(atr, bars)
+	if len(points) != 2 || !points[1].WarmedUp || btutil.Round4dp(points[1].Value) != 2 {
+		t.Errorf("Unexpected ATR points - %v", points)
+	}
+}
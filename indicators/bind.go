@@ -0,0 +1,93 @@
+package indicators
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/events"
+	"time"
+)
+
+// priceEvent is implemented by events that set the price of an asset.
+type priceEvent interface {
+	GetAsset() asset.IAssetWriteOnly
+	GetPrice() asset.Price
+}
+
+// isAsset returns true if the event sets the price of some asset.
+func isAsset(event priceEvent, targetAsset asset.IAssetReadOnly) bool {
+	return interface{}(event.GetAsset()) == interface{}(targetAsset)
+}
+
+// Binding updates an indicator with each valid price of an asset.
+// It is registered as a listener so it is notified of processed events.
+type Binding struct {
+	indicator   IIndicator
+	targetAsset asset.IAssetReadOnly
+}
+
+// Bind returns a binding that updates an indicator from an asset's prices.
+func Bind(indicator IIndicator, targetAsset asset.IAssetReadOnly) *Binding {
+	return &Binding{indicator: indicator, targetAsset: targetAsset}
+}
+
+// GetIndicator returns the bound indicator.
+func (b *Binding) GetIndicator() IIndicator {
+	return b.indicator
+}
+
+// GetAsset returns the bound asset.
+func (b *Binding) GetAsset() asset.IAssetReadOnly {
+	return b.targetAsset
+}
+
+// OnEvent updates the indicator for processed price events of the bound asset.
+func (b *Binding) OnEvent(event events.IEvent) error {
+	e, ok := event.(priceEvent)
+	if !ok || !isAsset(e, b.targetAsset) {
+		return nil
+	}
+	if price := e.GetPrice(); price.Valid {
+		b.indicator.Update(price.Float64)
+	}
+	return nil
+}
+
+// PairBinding updates a pair indicator from the prices of two assets.
+// The indicator is updated once per time step in which either asset
+// has a new price, provided both assets have valid prices.
+type PairBinding struct {
+	indicator IPairIndicator
+	x         asset.IAssetReadOnly
+	y         asset.IAssetReadOnly
+	updated   bool
+}
+
+// BindPair returns a binding that updates a pair indicator from two assets' prices.
+func BindPair(indicator IPairIndicator, x asset.IAssetReadOnly, y asset.IAssetReadOnly) *PairBinding {
+	return &PairBinding{indicator: indicator, x: x, y: y}
+}
+
+// GetIndicator returns the bound indicator.
+func (b *PairBinding) GetIndicator() IPairIndicator {
+	return b.indicator
+}
+
+// OnEvent records when either bound asset has a new price.
+func (b *PairBinding) OnEvent(event events.IEvent) error {
+	if e, ok := event.(priceEvent); ok && (isAsset(e, b.x) || isAsset(e, b.y)) {
+		b.updated = true
+	}
+	return nil
+}
+
+// OnStep updates the indicator once all events for a time step are processed.
+func (b *PairBinding) OnStep(currentTime time.Time) error {
+	if !b.updated {
+		return nil
+	}
+	b.updated = false
+	x, y := b.x.GetPrice(), b.y.GetPrice()
+	if x.Valid && y.Valid {
+		b.indicator.Update(x.Float64, y.Float64)
+	}
+	return nil
+}
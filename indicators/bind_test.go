@@ -0,0 +1,49 @@
+package indicators
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"testing"
+)
+
+func TestBindings(t *testing.T) {
+	stock1, err1 := asset.NewStock("ZZB AU", "AUD")
+	stock2, err2 := asset.NewStock("ZZC AU", "AUD")
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+
+	sma, _ := NewSMA(2)
+	corr, _ := NewCorrelation(2)
+	bt := backtest.NewBacktest(nil)
+	bt.RegisterAsset(stock1)
+	bt.RegisterAsset(stock2)
+	if err := bt.AddListener(Bind(sma, stock1)); err != nil {
+		t.Fatalf("Error in AddListener - %s", err)
+	}
+	if err := bt.AddListener(BindPair(corr, stock1, stock2)); err != nil {
+		t.Fatalf("Error in AddListener - %s", err)
+	}
+
+	for i, price := range []float64{1, 2, 3} {
+		day := btutil.Date(2021, 3, 15+i)
+		e1 := events.NewAssetPriceEvent(stock1, day, asset.Price{Float64: price, Valid: true})
+		e2 := events.NewAssetPriceEvent(stock2, day, asset.Price{Float64: 10 - price, Valid: true})
+		bt.AddEvents([]events.IEvent{&e1, &e2})
+	}
+	// an invalid price should be ignored
+	e3 := events.NewAssetPriceEvent(stock1, btutil.Date(2021, 3, 18), asset.Price{})
+	bt.AddEvent(&e3)
+
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in backtest.Run() - %s", err)
+	}
+	if btutil.Round4dp(sma.GetValue()) != 2.5 {
+		t.Errorf("Unexpected bound SMA - wanted 2.5, got %0.4f", sma.GetValue())
+	}
+	if btutil.Round4dp(corr.GetValue()) != -1 {
+		t.Errorf("Unexpected bound correlation - wanted -1, got %0.4f", corr.GetValue())
+	}
+}
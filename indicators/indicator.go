@@ -0,0 +1,94 @@
+// Package indicators provides technical indicators that can be updated
+// one value at a time as prices arrive, or applied to a price history.
+//
+// Each indicator returns NaN from GetValue until it is warmed up,
+// that is until it has seen enough values to be meaningful.
+package indicators
+
+import (
+	"errors"
+	"math"
+)
+
+// IIndicator defines the interface for indicators over a single series.
+type IIndicator interface {
+	Update(value float64)
+	GetValue() float64
+	IsWarmedUp() bool
+}
+
+// IPairIndicator defines the interface for indicators over two series.
+type IPairIndicator interface {
+	Update(x float64, y float64)
+	GetValue() float64
+	IsWarmedUp() bool
+}
+
+// validatePeriod returns an error if the period is less than one.
+func validatePeriod(period int) error {
+	if period < 1 {
+		return errors.New("the indicator period must be at least one")
+	}
+	return nil
+}
+
+// window holds the most recent values of a series in a ring buffer.
+type window struct {
+	values []float64
+	next   int
+	count  int
+	sum    float64
+}
+
+// newWindow returns a window holding up to size values.
+func newWindow(size int) *window {
+	return &window{values: make([]float64, size)}
+}
+
+// push adds a value to the window, dropping the oldest value once full.
+func (w *window) push(value float64) {
+	if w.isFull() {
+		w.sum -= w.values[w.next]
+	} else {
+		w.count++
+	}
+	w.values[w.next] = value
+	w.sum += value
+	w.next = (w.next + 1) % len(w.values)
+}
+
+// isFull returns true once the window holds size values.
+func (w *window) isFull() bool {
+	return w.count == len(w.values)
+}
+
+// at returns the value i places from the oldest value in the window.
+func (w *window) at(i int) float64 {
+	start := w.next - w.count
+	if start < 0 {
+		start += len(w.values)
+	}
+	return w.values[(start+i)%len(w.values)]
+}
+
+// mean returns the mean of the values in the window.
+func (w *window) mean() float64 {
+	if w.count == 0 {
+		return math.NaN()
+	}
+	return w.sum / float64(w.count)
+}
+
+// stdev returns the population standard deviation of the values in the window.
+func (w *window) stdev() float64 {
+	if w.count == 0 {
+		return math.NaN()
+	}
+	mean := w.mean()
+	total := 0.0
+	for i := 0; i < w.count; i++ {
+		diff := w.at(i) - mean
+		total += diff * diff
+	}
+	return math.Sqrt(total / float64(w.count))
+}
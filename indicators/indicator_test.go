@@ -0,0 +1,37 @@
+package indicators
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	w := newWindow(3)
+	for _, value := range []float64{1, 2, 3, 4} {
+		w.push(value)
+	}
+	if !w.isFull() {
+		t.Error("Expecting the window to be full")
+	}
+	for i, expected := range []float64{2, 3, 4} {
+		if w.at(i) != expected {
+			t.Errorf("Unexpected window value at %d - wanted %0.2f, got %0.2f", i, expected, w.at(i))
+		}
+	}
+	if w.mean() != 3 {
+		t.Errorf("Unexpected window mean - wanted 3, got %0.2f", w.mean())
+	}
+	if btutil.Round4dp(w.stdev()) != btutil.Round4dp(math.Sqrt(2.0/3.0)) {
+		t.Errorf("Unexpected window stdev - got %0.4f", w.stdev())
+	}
+}
+
+func TestValidatePeriod(t *testing.T) {
+	if _, err := NewSMA(0); err == nil {
+		t.Error("Expecting error for a zero period")
+	}
+	if err := validatePeriod(1); err != nil {
+		t.Errorf("Unexpected error - %s", err)
+	}
+}
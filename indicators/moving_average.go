@@ -0,0 +1,129 @@
+package indicators
+
+import "math"
+
+// SMA is the simple moving average over a number of periods.
+type SMA struct {
+	period int
+	values *window
+}
+
+// NewSMA returns a new simple moving average.
+func NewSMA(period int) (*SMA, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &SMA{period: period, values: newWindow(period)}, nil
+}
+
+// GetPeriod returns the number of periods averaged.
+func (ind *SMA) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *SMA) Update(value float64) {
+	ind.values.push(value)
+}
+
+// GetValue returns the current average.
+func (ind *SMA) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.values.mean()
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *SMA) IsWarmedUp() bool {
+	return ind.values.isFull()
+}
+
+// EMA is the exponential moving average over a number of periods.
+// The average is seeded with the simple average of the first period.
+type EMA struct {
+	period int
+	alpha  float64
+	count  int
+	value  float64
+}
+
+// NewEMA returns a new exponential moving average
+// with a smoothing factor of 2 / (period + 1).
+func NewEMA(period int) (*EMA, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &EMA{period: period, alpha: 2.0 / float64(period+1)}, nil
+}
+
+// GetPeriod returns the number of periods averaged.
+func (ind *EMA) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *EMA) Update(value float64) {
+	ind.count++
+	if ind.count <= ind.period {
+		// running simple average until the first period is complete
+		ind.value += (value - ind.value) / float64(ind.count)
+		return
+	}
+	ind.value += ind.alpha * (value - ind.value)
+}
+
+// GetValue returns the current average.
+func (ind *EMA) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.value
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *EMA) IsWarmedUp() bool {
+	return ind.count >= ind.period
+}
+
+// WMA is the linearly weighted moving average over a number of periods,
+// where the most recent value has the largest weight.
+type WMA struct {
+	period int
+	values *window
+}
+
+// NewWMA returns a new weighted moving average.
+func NewWMA(period int) (*WMA, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &WMA{period: period, values: newWindow(period)}, nil
+}
+
+// GetPeriod returns the number of periods averaged.
+func (ind *WMA) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *WMA) Update(value float64) {
+	ind.values.push(value)
+}
+
+// GetValue returns the current average.
+func (ind *WMA) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	total := 0.0
+	for i := 0; i < ind.period; i++ {
+		total += float64(i+1) * ind.values.at(i)
+	}
+	return total / float64(ind.period*(ind.period+1)/2)
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *WMA) IsWarmedUp() bool {
+	return ind.values.isFull()
+}
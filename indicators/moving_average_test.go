@@ -0,0 +1,67 @@
+package indicators
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+// updateAll updates an indicator with each value and returns
+// the indicator values after each update.
+func updateAll(ind IIndicator, values ...float64) []float64 {
+	var results []float64
+	for _, value := range values {
+		ind.Update(value)
+		results = append(results, ind.GetValue())
+	}
+	return results
+}
+
+func TestSMA(t *testing.T) {
+	sma, err := NewSMA(3)
+	if err != nil {
+		t.Fatalf("Error in NewSMA - %s", err)
+	}
+	results := updateAll(sma, 1, 2, 3, 4, 5)
+	if !math.IsNaN(results[1]) {
+		t.Error("Expecting NaN before warm-up")
+	}
+	for i, expected := range []float64{2, 3, 4} {
+		if btutil.Round4dp(results[i+2]) != btutil.Round4dp(expected) {
+			t.Errorf("Unexpected SMA - wanted %0.4f, got %0.4f", expected, results[i+2])
+		}
+	}
+	if !sma.IsWarmedUp() || sma.GetPeriod() != 3 {
+		t.Error("Expecting a warmed up SMA with period 3")
+	}
+}
+
+func TestEMA(t *testing.T) {
+	ema, err := NewEMA(3)
+	if err != nil {
+		t.Fatalf("Error in NewEMA - %s", err)
+	}
+	results := updateAll(ema, 1, 2, 3, 4, 5)
+	if ema.IsWarmedUp() != true || !math.IsNaN(results[1]) {
+		t.Error("Unexpected EMA warm-up")
+	}
+	for i, expected := range []float64{2, 3, 4} {
+		if btutil.Round4dp(results[i+2]) != btutil.Round4dp(expected) {
+			t.Errorf("Unexpected EMA - wanted %0.4f, got %0.4f", expected, results[i+2])
+		}
+	}
+}
+
+func TestWMA(t *testing.T) {
+	wma, err := NewWMA(3)
+	if err != nil {
+		t.Fatalf("Error in NewWMA - %s", err)
+	}
+	results := updateAll(wma, 1, 2, 3, 4)
+	if btutil.Round4dp(results[2]) != btutil.Round4dp(14.0/6.0) {
+		t.Errorf("Unexpected WMA - wanted %0.4f, got %0.4f", 14.0/6.0, results[2])
+	}
+	if btutil.Round4dp(results[3]) != btutil.Round4dp(20.0/6.0) {
+		t.Errorf("Unexpected WMA - wanted %0.4f, got %0.4f", 20.0/6.0, results[3])
+	}
+}
@@ -0,0 +1,127 @@
+package indicators
+
+import (
+	"errors"
+	"gobacktrader/btutil"
+	"math"
+)
+
+// RSI is the relative strength index using Wilder's smoothing.
+type RSI struct {
+	period   int
+	count    int
+	previous float64
+	avgGain  float64
+	avgLoss  float64
+}
+
+// NewRSI returns a new relative strength index.
+func NewRSI(period int) (*RSI, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &RSI{period: period}, nil
+}
+
+// GetPeriod returns the number of periods smoothed.
+func (ind *RSI) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *RSI) Update(value float64) {
+	ind.count++
+	if ind.count == 1 {
+		ind.previous = value
+		return
+	}
+
+	change := value - ind.previous
+	ind.previous = value
+	gain, loss := math.Max(change, 0), math.Max(-change, 0)
+
+	changes := ind.count - 1
+	if changes <= ind.period {
+		// simple average of the changes in the first period
+		ind.avgGain += (gain - ind.avgGain) / float64(changes)
+		ind.avgLoss += (loss - ind.avgLoss) / float64(changes)
+		return
+	}
+	n := float64(ind.period)
+	ind.avgGain = (ind.avgGain*(n-1) + gain) / n
+	ind.avgLoss = (ind.avgLoss*(n-1) + loss) / n
+}
+
+// GetValue returns the current index between 0 and 100.
+func (ind *RSI) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	if ind.avgLoss == 0 {
+		if ind.avgGain == 0 {
+			return 50 // no movement
+		}
+		return 100
+	}
+	return 100 - 100/(1+ind.avgGain/ind.avgLoss)
+}
+
+// IsWarmedUp returns true once a full period of changes has been seen.
+func (ind *RSI) IsWarmedUp() bool {
+	return ind.count > ind.period
+}
+
+// MACD is the moving average convergence divergence indicator.
+// The value is the difference between the fast and slow exponential
+// averages, and the signal line is an exponential average of that value.
+type MACD struct {
+	fast   *EMA
+	slow   *EMA
+	signal *EMA
+}
+
+// NewMACD returns a new MACD, typically with periods of 12, 26 and 9.
+func NewMACD(fastPeriod int, slowPeriod int, signalPeriod int) (*MACD, error) {
+	if fastPeriod >= slowPeriod {
+		return nil, errors.New("the fast period must be shorter than the slow period")
+	}
+	fast, err1 := NewEMA(fastPeriod)
+	slow, err2 := NewEMA(slowPeriod)
+	signal, err3 := NewEMA(signalPeriod)
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		return nil, err
+	}
+	return &MACD{fast: fast, slow: slow, signal: signal}, nil
+}
+
+// Update adds the next value in the series.
+func (ind *MACD) Update(value float64) {
+	ind.fast.Update(value)
+	ind.slow.Update(value)
+	if ind.slow.IsWarmedUp() {
+		ind.signal.Update(ind.fast.GetValue() - ind.slow.GetValue())
+	}
+}
+
+// GetValue returns the MACD line.
+func (ind *MACD) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.fast.GetValue() - ind.slow.GetValue()
+}
+
+// GetSignal returns the signal line.
+func (ind *MACD) GetSignal() float64 {
+	return ind.signal.GetValue()
+}
+
+// GetHistogram returns the MACD line less the signal line.
+func (ind *MACD) GetHistogram() float64 {
+	return ind.GetValue() - ind.GetSignal()
+}
+
+// IsWarmedUp returns true once the signal line is warmed up.
+func (ind *MACD) IsWarmedUp() bool {
+	return ind.signal.IsWarmedUp()
+}
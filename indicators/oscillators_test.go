@@ -0,0 +1,50 @@
+package indicators
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+func TestRSI(t *testing.T) {
+	rsi, err := NewRSI(2)
+	if err != nil {
+		t.Fatalf("Error in NewRSI - %s", err)
+	}
+	results := updateAll(rsi, 1, 2, 1, 4)
+	if !math.IsNaN(results[1]) {
+		t.Error("Expecting NaN before warm-up")
+	}
+	if btutil.Round4dp(results[2]) != 50 {
+		t.Errorf("Unexpected RSI - wanted 50, got %0.4f", results[2])
+	}
+	if btutil.Round4dp(results[3]) != 87.5 {
+		t.Errorf("Unexpected RSI - wanted 87.5, got %0.4f", results[3])
+	}
+
+	rising, _ := NewRSI(2)
+	if value := updateAll(rising, 1, 2, 3)[2]; value != 100 {
+		t.Errorf("Unexpected RSI for a rising series - wanted 100, got %0.4f", value)
+	}
+}
+
+func TestMACD(t *testing.T) {
+	if _, err := NewMACD(26, 12, 9); err == nil {
+		t.Error("Expecting error where the fast period is not shorter")
+	}
+	macd, err := NewMACD(2, 3, 2)
+	if err != nil {
+		t.Fatalf("Error in NewMACD - %s", err)
+	}
+	results := updateAll(macd, 1, 2, 3, 4)
+	if !math.IsNaN(results[2]) {
+		t.Error("Expecting NaN until the signal line is warmed up")
+	}
+	if !macd.IsWarmedUp() {
+		t.Error("Expecting MACD to be warmed up")
+	}
+	if btutil.Round4dp(results[3]) != 0.5 || btutil.Round4dp(macd.GetSignal()) != 0.5 || btutil.Round4dp(macd.GetHistogram()) != 0 {
+		t.Errorf("Unexpected MACD - got %0.4f, signal %0.4f, histogram %0.4f",
+			results[3], macd.GetSignal(), macd.GetHistogram())
+	}
+}
@@ -0,0 +1,99 @@
+package indicators
+
+import "math"
+
+// ZScore is the number of standard deviations the latest value
+// lies from the rolling mean, where the window includes that value.
+type ZScore struct {
+	period int
+	values *window
+	latest float64
+}
+
+// NewZScore returns a new rolling z-score.
+func NewZScore(period int) (*ZScore, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &ZScore{period: period, values: newWindow(period)}, nil
+}
+
+// GetPeriod returns the number of periods in the window.
+func (ind *ZScore) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *ZScore) Update(value float64) {
+	ind.values.push(value)
+	ind.latest = value
+}
+
+// GetValue returns the current z-score,
+// which is zero where the window has no dispersion.
+func (ind *ZScore) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	stdev := ind.values.stdev()
+	if stdev == 0 {
+		return 0
+	}
+	return (ind.latest - ind.values.mean()) / stdev
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *ZScore) IsWarmedUp() bool {
+	return ind.values.isFull()
+}
+
+// Correlation is the rolling Pearson correlation between two series.
+type Correlation struct {
+	period int
+	xs     *window
+	ys     *window
+}
+
+// NewCorrelation returns a new rolling correlation.
+func NewCorrelation(period int) (*Correlation, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &Correlation{period: period, xs: newWindow(period), ys: newWindow(period)}, nil
+}
+
+// GetPeriod returns the number of periods in the window.
+func (ind *Correlation) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next pair of values.
+func (ind *Correlation) Update(x float64, y float64) {
+	ind.xs.push(x)
+	ind.ys.push(y)
+}
+
+// GetValue returns the current correlation,
+// which is NaN where either series has no dispersion.
+func (ind *Correlation) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	meanX, meanY := ind.xs.mean(), ind.ys.mean()
+	var covariance, varianceX, varianceY float64
+	for i := 0; i < ind.period; i++ {
+		dx, dy := ind.xs.at(i)-meanX, ind.ys.at(i)-meanY
+		covariance += dx * dy
+		varianceX += dx * dx
+		varianceY += dy * dy
+	}
+	if varianceX == 0 || varianceY == 0 {
+		return math.NaN()
+	}
+	return covariance / math.Sqrt(varianceX*varianceY)
+}
+
+// IsWarmedUp returns true once a full period of pairs has been seen.
+func (ind *Correlation) IsWarmedUp() bool {
+	return ind.xs.isFull()
+}
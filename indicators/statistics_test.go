@@ -0,0 +1,51 @@
+package indicators
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+func TestZScore(t *testing.T) {
+	zscore, err := NewZScore(3)
+	if err != nil {
+		t.Fatalf("Error in NewZScore - %s", err)
+	}
+	results := updateAll(zscore, 1, 2, 3)
+	if btutil.Round4dp(results[2]) != btutil.Round4dp(1/math.Sqrt(2.0/3.0)) {
+		t.Errorf("Unexpected z-score - got %0.4f", results[2])
+	}
+
+	flat, _ := NewZScore(2)
+	if value := updateAll(flat, 5, 5)[1]; value != 0 {
+		t.Errorf("Unexpected z-score for a flat series - wanted 0, got %0.4f", value)
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	corr, err := NewCorrelation(3)
+	if err != nil {
+		t.Fatalf("Error in NewCorrelation - %s", err)
+	}
+	corr.Update(1, 2)
+	corr.Update(2, 4)
+	if !math.IsNaN(corr.GetValue()) {
+		t.Error("Expecting NaN before warm-up")
+	}
+	corr.Update(3, 6)
+	if btutil.Round4dp(corr.GetValue()) != 1 {
+		t.Errorf("Unexpected correlation - wanted 1, got %0.4f", corr.GetValue())
+	}
+	for _, x := range []float64{4, 5, 6} {
+		corr.Update(x, -x)
+	}
+	if btutil.Round4dp(corr.GetValue()) != -1 {
+		t.Errorf("Unexpected correlation - wanted -1, got %0.4f", corr.GetValue())
+	}
+	for i := 0; i < 3; i++ {
+		corr.Update(1, float64(i))
+	}
+	if !math.IsNaN(corr.GetValue()) {
+		t.Error("Expecting NaN where a series has no dispersion")
+	}
+}
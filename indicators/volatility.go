@@ -0,0 +1,160 @@
+package indicators
+
+import (
+	"errors"
+	"math"
+)
+
+// StdDev is the rolling population standard deviation over a number of periods.
+type StdDev struct {
+	period int
+	values *window
+}
+
+// NewStdDev returns a new rolling standard deviation.
+func NewStdDev(period int) (*StdDev, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &StdDev{period: period, values: newWindow(period)}, nil
+}
+
+// GetPeriod returns the number of periods in the window.
+func (ind *StdDev) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next value in the series.
+func (ind *StdDev) Update(value float64) {
+	ind.values.push(value)
+}
+
+// GetValue returns the current standard deviation.
+func (ind *StdDev) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.values.stdev()
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *StdDev) IsWarmedUp() bool {
+	return ind.values.isFull()
+}
+
+// Bollinger holds Bollinger bands, being a simple moving average
+// with bands a number of standard deviations above and below.
+type Bollinger struct {
+	period int
+	width  float64
+	values *window
+}
+
+// NewBollinger returns new Bollinger bands, typically with
+// a period of 20 and a width of 2 standard deviations.
+func NewBollinger(period int, width float64) (*Bollinger, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	if width <= 0 {
+		return nil, errors.New("the band width must be positive")
+	}
+	return &Bollinger{period: period, width: width, values: newWindow(period)}, nil
+}
+
+// Update adds the next value in the series.
+func (ind *Bollinger) Update(value float64) {
+	ind.values.push(value)
+}
+
+// GetValue returns the middle band.
+func (ind *Bollinger) GetValue() float64 {
+	return ind.GetMiddle()
+}
+
+// GetMiddle returns the middle band.
+func (ind *Bollinger) GetMiddle() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.values.mean()
+}
+
+// GetUpper returns the upper band.
+func (ind *Bollinger) GetUpper() float64 {
+	return ind.GetMiddle() + ind.width*ind.values.stdev()
+}
+
+// GetLower returns the lower band.
+func (ind *Bollinger) GetLower() float64 {
+	return ind.GetMiddle() - ind.width*ind.values.stdev()
+}
+
+// IsWarmedUp returns true once a full period of values has been seen.
+func (ind *Bollinger) IsWarmedUp() bool {
+	return ind.values.isFull()
+}
+
+// ATR is the average true range using Wilder's smoothing.
+// Where only closing prices are available the true range
+// is the absolute change from the previous close.
+type ATR struct {
+	period      int
+	count       int
+	hasPrevious bool
+	prevClose   float64
+	value       float64
+}
+
+// NewATR returns a new average true range.
+func NewATR(period int) (*ATR, error) {
+	if err := validatePeriod(period); err != nil {
+		return nil, err
+	}
+	return &ATR{period: period}, nil
+}
+
+// GetPeriod returns the number of periods smoothed.
+func (ind *ATR) GetPeriod() int {
+	return ind.period
+}
+
+// Update adds the next closing price in the series.
+func (ind *ATR) Update(value float64) {
+	if !ind.hasPrevious {
+		ind.prevClose, ind.hasPrevious = value, true
+		return
+	}
+	ind.UpdateBar(value, value, value)
+}
+
+// UpdateBar adds the high, low and close of the next bar in the series.
+func (ind *ATR) UpdateBar(high float64, low float64, close float64) {
+	trueRange := high - low
+	if ind.hasPrevious {
+		trueRange = math.Max(trueRange, math.Abs(high-ind.prevClose))
+		trueRange = math.Max(trueRange, math.Abs(low-ind.prevClose))
+	}
+	ind.prevClose, ind.hasPrevious = close, true
+
+	ind.count++
+	if ind.count <= ind.period {
+		ind.value += (trueRange - ind.value) / float64(ind.count)
+		return
+	}
+	n := float64(ind.period)
+	ind.value = (ind.value*(n-1) + trueRange) / n
+}
+
+// GetValue returns the current average true range.
+func (ind *ATR) GetValue() float64 {
+	if !ind.IsWarmedUp() {
+		return math.NaN()
+	}
+	return ind.value
+}
+
+// IsWarmedUp returns true once a full period of true ranges has been seen.
+func (ind *ATR) IsWarmedUp() bool {
+	return ind.count >= ind.period
+}
@@ -0,0 +1,64 @@
+package indicators
+
+import (
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+func TestStdDev(t *testing.T) {
+	stdev, err := NewStdDev(8)
+	if err != nil {
+		t.Fatalf("Error in NewStdDev - %s", err)
+	}
+	results := updateAll(stdev, 2, 4, 4, 4, 5, 5, 7, 9)
+	if !math.IsNaN(results[6]) {
+		t.Error("Expecting NaN before warm-up")
+	}
+	if btutil.Round4dp(results[7]) != 2 {
+		t.Errorf("Unexpected standard deviation - wanted 2, got %0.4f", results[7])
+	}
+}
+
+func TestBollinger(t *testing.T) {
+	if _, err := NewBollinger(20, 0); err == nil {
+		t.Error("Expecting error for a zero band width")
+	}
+	bands, err := NewBollinger(8, 2)
+	if err != nil {
+		t.Fatalf("Error in NewBollinger - %s", err)
+	}
+	updateAll(bands, 2, 4, 4, 4, 5, 5, 7, 9)
+	if btutil.Round4dp(bands.GetMiddle()) != 5 || btutil.Round4dp(bands.GetUpper()) != 9 || btutil.Round4dp(bands.GetLower()) != 1 {
+		t.Errorf("Unexpected bands - got %0.4f, %0.4f, %0.4f",
+			bands.GetLower(), bands.GetMiddle(), bands.GetUpper())
+	}
+	if bands.GetValue() != bands.GetMiddle() {
+		t.Error("Expecting the value to be the middle band")
+	}
+}
+
+func TestATR(t *testing.T) {
+	atr, err := NewATR(2)
+	if err != nil {
+		t.Fatalf("Error in NewATR - %s", err)
+	}
+	atr.UpdateBar(10, 8, 9)
+	if atr.IsWarmedUp() {
+		t.Error("Not expecting ATR to be warmed up")
+	}
+	atr.UpdateBar(11, 9, 10)
+	if btutil.Round4dp(atr.GetValue()) != 2 {
+		t.Errorf("Unexpected ATR - wanted 2, got %0.4f", atr.GetValue())
+	}
+	atr.UpdateBar(15, 10, 14)
+	if btutil.Round4dp(atr.GetValue()) != 3.5 {
+		t.Errorf("Unexpected ATR - wanted 3.5, got %0.4f", atr.GetValue())
+	}
+
+	closes, _ := NewATR(2)
+	results := updateAll(closes, 10, 12, 11)
+	if !math.IsNaN(results[1]) || btutil.Round4dp(results[2]) != 1.5 {
+		t.Errorf("Unexpected ATR from closing prices - got %v", results)
+	}
+}
@@ -0,0 +1,155 @@
+package optimise
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/backtest"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// Factory builds an independent backtest for some parameters.
+// Each call must create its own portfolios, assets and events
+// as backtests are run concurrently. Portfolios created with
+// Backtest.NewPortfolio take cash from a registry owned by that backtest.
+type Factory func(params Params) (*backtest.Backtest, error)
+
+// Objective scores a completed backtest, where higher scores are better.
+// To minimise some measure return its negative.
+type Objective func(run *backtest.Backtest) (float64, error)
+
+// PortfolioObjective returns an objective that applies some measure,
+// such as a closure over analytics.Sharpe, to the history of values
+// of the portfolio with some code.
+func PortfolioObjective(portfolioCode string, measure func(values []float64) float64) Objective {
+	return func(run *backtest.Backtest) (float64, error) {
+		for _, p := range run.GetPortfolios() {
+			if p.GetCode() == portfolioCode {
+				return measure(p.GetPortfolioSeries().Floats()), nil
+			}
+		}
+		return math.NaN(), fmt.Errorf("portfolio code '%s' is not registered", portfolioCode)
+	}
+}
+
+// Result holds the outcome of the backtest for one set of parameters.
+type Result struct {
+	params Params
+	score  float64
+	run    *backtest.Backtest
+	err    error
+}
+
+// GetParams returns the parameters for this result.
+func (r Result) GetParams() Params {
+	return r.params
+}
+
+// GetScore returns the objective score, which is NaN if the run failed.
+func (r Result) GetScore() float64 {
+	return r.score
+}
+
+// GetBacktest returns the completed backtest.
+func (r Result) GetBacktest() *backtest.Backtest {
+	return r.run
+}
+
+// GetError returns any error building, running or scoring the backtest.
+func (r Result) GetError() error {
+	return r.err
+}
+
+// Optimiser runs a backtest for each combination of parameters.
+type Optimiser struct {
+	factory   Factory
+	objective Objective
+	workers   int
+}
+
+// NewOptimiser returns a new optimiser with one worker per CPU.
+func NewOptimiser(factory Factory, objective Objective) (*Optimiser, error) {
+	if factory == nil || objective == nil {
+		return nil, errors.New("the optimiser requires a factory and an objective")
+	}
+	return &Optimiser{factory: factory, objective: objective, workers: runtime.NumCPU()}, nil
+}
+
+// SetWorkers sets the number of backtests run concurrently.
+func (o *Optimiser) SetWorkers(workers int) error {
+	if workers < 1 {
+		return errors.New("the number of workers must be at least one")
+	}
+	o.workers = workers
+	return nil
+}
+
+// GetWorkers returns the number of backtests run concurrently.
+func (o *Optimiser) GetWorkers() int {
+	return o.workers
+}
+
+// runOne builds, runs and scores the backtest for some parameters.
+func (o *Optimiser) runOne(params Params) Result {
+	result := Result{params: params, score: math.NaN()}
+	run, err := o.factory(params)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.run = run
+	if err := run.Run(); err != nil {
+		result.err = err
+		return result
+	}
+	result.score, result.err = o.objective(run)
+	return result
+}
+
+// Run runs a backtest for every combination in the parameter space and
+// returns the results ranked from the highest score. Runs that fail or
+// score NaN are ranked last with their errors recorded in the result.
+func (o *Optimiser) Run(space IParameterSpace) ([]Result, error) {
+	combinations, err := space.Combinations()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, len(combinations))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < o.workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = o.runOne(combinations[i])
+			}
+		}()
+	}
+	for i := range combinations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	Rank(results)
+	return results, nil
+}
+
+// Rank sorts results from the highest score, keeping the original order
+// for equal scores and placing failed or NaN scores last.
+func Rank(results []Result) {
+	isRanked := func(r Result) bool {
+		return r.err == nil && !math.IsNaN(r.score)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		ri, rj := isRanked(results[i]), isRanked(results[j])
+		if ri != rj {
+			return ri
+		}
+		return ri && results[i].score > results[j].score
+	})
+}
@@ -0,0 +1,99 @@
+package optimise
+
+import (
+	"errors"
+	"gobacktrader/analytics"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"testing"
+)
+
+// buyAndHoldFactory buys some units of a rising stock on the first day.
+func buyAndHoldFactory(params Params) (*backtest.Backtest, error) {
+	units, err := params.Get("units")
+	if err != nil {
+		return nil, err
+	}
+	if units < 0 {
+		return nil, errors.New("units cannot be negative")
+	}
+
+	portfolio, err1 := asset.NewPortfolio("P", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := asset.NewCash("AUD") // shared between runs
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		return nil, err
+	}
+	portfolio.Transfer(cash, 1000)
+
+	bought := false
+	strategy := backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+		if bought {
+			return nil, nil
+		}
+		bought = true
+		return []*trade.Trade{ctx.NewTrade(portfolio, stock, units)}, nil
+	})
+	bt := backtest.NewContextBacktest(strategy)
+	bt.RegisterPortfolio(portfolio)
+	bt.RegisterAsset(stock)
+	bt.RegisterAsset(cash)
+	for i := 0; i < 5; i++ {
+		price := asset.Price{Float64: 1.0 + float64(i)*0.1, Valid: true}
+		event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 15+i), price)
+		bt.AddEvent(&event)
+	}
+	return &bt, nil
+}
+
+func TestOptimiser(t *testing.T) {
+	if _, err := NewOptimiser(nil, nil); err == nil {
+		t.Error("Expecting error without a factory and objective")
+	}
+	optimiser, err := NewOptimiser(buyAndHoldFactory, PortfolioObjective("P", analytics.TotalReturn))
+	if err != nil {
+		t.Fatalf("Error in NewOptimiser - %s", err)
+	}
+	if err := optimiser.SetWorkers(0); err == nil {
+		t.Error("Expecting error for zero workers")
+	}
+	optimiser.SetWorkers(4)
+
+	grid := NewGrid()
+	grid.AddValues("units", 100, -1, 500, 0, 300)
+	results, err := optimiser.Run(grid)
+	if err != nil {
+		t.Fatalf("Error in optimiser.Run() - %s", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("Unexpected number of results - wanted 5, got %d", len(results))
+	}
+
+	expectedUnits := []float64{500, 300, 100, 0}
+	for i, units := range expectedUnits {
+		if results[i].GetParams()["units"] != units {
+			t.Errorf("Unexpected ranking at %d - wanted %0.0f units, got %s", i, units, results[i].GetParams())
+		}
+		if results[i].GetError() != nil || results[i].GetBacktest() == nil {
+			t.Errorf("Unexpected failed run at %d - %s", i, results[i].GetError())
+		}
+	}
+	// 500 units bought at 1.0 and valued at 1.4 on 1000 of cash
+	if btutil.Round4dp(results[0].GetScore()) != 0.2 {
+		t.Errorf("Unexpected best score - wanted 0.2, got %0.4f", results[0].GetScore())
+	}
+	if results[4].GetError() == nil {
+		t.Error("Expecting the failed run to be ranked last")
+	}
+}
+
+func TestPortfolioObjectiveMissing(t *testing.T) {
+	bt := backtest.NewBacktest(nil)
+	objective := PortfolioObjective("XXX", analytics.TotalReturn)
+	if _, err := objective(&bt); err == nil {
+		t.Error("Expecting error for a missing portfolio")
+	}
+}
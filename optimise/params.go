@@ -0,0 +1,219 @@
+// Package optimise runs a backtest for each combination of strategy
+// parameters in parallel and ranks the results.
+package optimise
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// Params holds the parameter values for a single backtest.
+type Params map[string]float64
+
+// Get returns the value of a parameter, or an error if it is not set.
+func (p Params) Get(name string) (float64, error) {
+	value, ok := p[name]
+	if !ok {
+		return 0, fmt.Errorf("parameter '%s' is not set", name)
+	}
+	return value, nil
+}
+
+// GetInt returns the value of a parameter rounded to an integer.
+func (p Params) GetInt(name string) (int, error) {
+	value, err := p.Get(name)
+	return int(math.Round(value)), err
+}
+
+// String returns the parameters sorted by name, such as "fast=5 slow=20".
+func (p Params) String() string {
+	var names []string
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s=%g", name, p[name]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// copyParams returns a copy of some parameters.
+func copyParams(p Params) Params {
+	params := make(Params, len(p))
+	for name, value := range p {
+		params[name] = value
+	}
+	return params
+}
+
+// IParameterSpace defines the interface for a set of parameter combinations.
+type IParameterSpace interface {
+	Combinations() ([]Params, error)
+}
+
+// Grid is a parameter space covering every combination of parameter values.
+type Grid struct {
+	names  []string
+	values map[string][]float64
+}
+
+// NewGrid returns a new empty grid.
+func NewGrid() *Grid {
+	return &Grid{values: make(map[string][]float64)}
+}
+
+// AddValues adds a parameter taking each of some values.
+func (g *Grid) AddValues(name string, values ...float64) error {
+	if _, ok := g.values[name]; ok {
+		return fmt.Errorf("parameter '%s' is already defined", name)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("parameter '%s' requires at least one value", name)
+	}
+	if g.values == nil {
+		g.values = make(map[string][]float64)
+	}
+	g.names = append(g.names, name)
+	g.values[name] = values
+	return nil
+}
+
+// AddRange adds a parameter taking values from start to end inclusive
+// in increments of step.
+func (g *Grid) AddRange(name string, start float64, end float64, step float64) error {
+	if step <= 0 {
+		return fmt.Errorf("parameter '%s' requires a positive step", name)
+	}
+	if end < start {
+		return fmt.Errorf("parameter '%s' cannot end before it starts", name)
+	}
+	var values []float64
+	for i := 0; ; i++ {
+		value := start + float64(i)*step
+		if value > end+step*1e-9 {
+			break
+		}
+		values = append(values, value)
+	}
+	return g.AddValues(name, values...)
+}
+
+// Size returns the number of combinations in the grid.
+func (g *Grid) Size() int {
+	if len(g.names) == 0 {
+		return 0
+	}
+	size := 1
+	for _, name := range g.names {
+		size *= len(g.values[name])
+	}
+	return size
+}
+
+// Combinations returns every combination of parameter values, where the
+// last parameter added varies fastest.
+func (g *Grid) Combinations() ([]Params, error) {
+	if len(g.names) == 0 {
+		return nil, errors.New("the parameter space is empty")
+	}
+	combinations := []Params{{}}
+	for _, name := range g.names {
+		var next []Params
+		for _, params := range combinations {
+			for _, value := range g.values[name] {
+				p := copyParams(params)
+				p[name] = value
+				next = append(next, p)
+			}
+		}
+		combinations = next
+	}
+	return combinations, nil
+}
+
+// sampler draws a single parameter value.
+type sampler func(r *rand.Rand) float64
+
+// RandomSearch is a parameter space of randomly drawn combinations.
+// The same seed always produces the same combinations.
+type RandomSearch struct {
+	samples  int
+	seed     int64
+	names    []string
+	samplers map[string]sampler
+}
+
+// NewRandomSearch returns a new random search drawing some number of samples.
+func NewRandomSearch(samples int, seed int64) (*RandomSearch, error) {
+	if samples < 1 {
+		return nil, errors.New("the number of samples must be at least one")
+	}
+	return &RandomSearch{samples: samples, seed: seed, samplers: make(map[string]sampler)}, nil
+}
+
+// GetSeed returns the random seed.
+func (s *RandomSearch) GetSeed() int64 {
+	return s.seed
+}
+
+func (s *RandomSearch) add(name string, f sampler) error {
+	if _, ok := s.samplers[name]; ok {
+		return fmt.Errorf("parameter '%s' is already defined", name)
+	}
+	s.names = append(s.names, name)
+	s.samplers[name] = f
+	return nil
+}
+
+// AddUniform adds a parameter drawn uniformly between min and max.
+func (s *RandomSearch) AddUniform(name string, min float64, max float64) error {
+	if max < min {
+		return fmt.Errorf("parameter '%s' cannot have a maximum below its minimum", name)
+	}
+	return s.add(name, func(r *rand.Rand) float64 {
+		return min + r.Float64()*(max-min)
+	})
+}
+
+// AddIntRange adds a parameter drawn uniformly from the integers min to max inclusive.
+func (s *RandomSearch) AddIntRange(name string, min int, max int) error {
+	if max < min {
+		return fmt.Errorf("parameter '%s' cannot have a maximum below its minimum", name)
+	}
+	return s.add(name, func(r *rand.Rand) float64 {
+		return float64(min + r.Intn(max-min+1))
+	})
+}
+
+// AddChoice adds a parameter drawn from some values.
+func (s *RandomSearch) AddChoice(name string, values ...float64) error {
+	if len(values) == 0 {
+		return fmt.Errorf("parameter '%s' requires at least one value", name)
+	}
+	return s.add(name, func(r *rand.Rand) float64 {
+		return values[r.Intn(len(values))]
+	})
+}
+
+// Combinations returns the randomly drawn combinations.
+func (s *RandomSearch) Combinations() ([]Params, error) {
+	if len(s.names) == 0 {
+		return nil, errors.New("the parameter space is empty")
+	}
+	r := rand.New(rand.NewSource(s.seed))
+	combinations := make([]Params, s.samples)
+	for i := range combinations {
+		params := make(Params, len(s.names))
+		for _, name := range s.names {
+			params[name] = s.samplers[name](r)
+		}
+		combinations[i] = params
+	}
+	return combinations, nil
+}
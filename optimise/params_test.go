@@ -0,0 +1,92 @@
+package optimise
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	grid := NewGrid()
+	if _, err := grid.Combinations(); err == nil {
+		t.Error("Expecting error for an empty grid")
+	}
+	if err := grid.AddValues("fast", 5, 10); err != nil {
+		t.Fatalf("Error in AddValues - %s", err)
+	}
+	if err := grid.AddRange("slow", 20, 30, 5); err != nil {
+		t.Fatalf("Error in AddRange - %s", err)
+	}
+	if err := grid.AddValues("fast", 1); err == nil {
+		t.Error("Expecting error for a duplicate parameter")
+	}
+	if err := grid.AddRange("step", 0, 1, 0); err == nil {
+		t.Error("Expecting error for a zero step")
+	}
+	if grid.Size() != 6 {
+		t.Errorf("Unexpected grid size - wanted 6, got %d", grid.Size())
+	}
+
+	combinations, err := grid.Combinations()
+	if err != nil {
+		t.Fatalf("Error in Combinations - %s", err)
+	}
+	if len(combinations) != 6 {
+		t.Fatalf("Unexpected number of combinations - wanted 6, got %d", len(combinations))
+	}
+	if !reflect.DeepEqual(combinations[1], Params{"fast": 5, "slow": 25}) {
+		t.Errorf("Unexpected combination - %s", combinations[1])
+	}
+	if combinations[5].String() != "fast=10 slow=30" {
+		t.Errorf("Unexpected combination string - '%s'", combinations[5])
+	}
+
+	// the zero value is usable
+	var zero Grid
+	if err := zero.AddValues("fast", 5); err != nil {
+		t.Fatalf("Error in AddValues on a zero value grid - %s", err)
+	}
+	if zero.Size() != 1 {
+		t.Errorf("Unexpected zero value grid size - wanted 1, got %d", zero.Size())
+	}
+}
+
+func TestRandomSearch(t *testing.T) {
+	if _, err := NewRandomSearch(0, 1); err == nil {
+		t.Error("Expecting error for zero samples")
+	}
+	newSearch := func() *RandomSearch {
+		search, _ := NewRandomSearch(20, 42)
+		search.AddUniform("threshold", 0.5, 1.5)
+		search.AddIntRange("lookback", 5, 10)
+		search.AddChoice("band", 1, 2)
+		return search
+	}
+
+	search := newSearch()
+	if err := search.AddUniform("threshold", 0, 1); err == nil {
+		t.Error("Expecting error for a duplicate parameter")
+	}
+	if err := search.AddIntRange("other", 2, 1); err == nil {
+		t.Error("Expecting error for an invalid range")
+	}
+	combinations, err := search.Combinations()
+	if err != nil {
+		t.Fatalf("Error in Combinations - %s", err)
+	}
+	for _, params := range combinations {
+		threshold, _ := params.Get("threshold")
+		lookback, _ := params.GetInt("lookback")
+		band, _ := params.Get("band")
+		if threshold < 0.5 || threshold > 1.5 || lookback < 5 || lookback > 10 || (band != 1 && band != 2) {
+			t.Errorf("Unexpected combination - %s", params)
+		}
+	}
+
+	again, _ := newSearch().Combinations()
+	if !reflect.DeepEqual(combinations, again) {
+		t.Error("Expecting the same seed to give the same combinations")
+	}
+	if _, err := combinations[0].Get("missing"); err == nil {
+		t.Error("Expecting error for a missing parameter")
+	}
+}
@@ -0,0 +1,295 @@
+package optimise
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"math"
+	"strings"
+	"time"
+)
+
+// Span is a calendar length of time.
+type Span struct {
+	Years  int
+	Months int
+	Days   int
+}
+
+// addTo returns t moved forward by the span.
+func (s Span) addTo(t time.Time) time.Time {
+	return t.AddDate(s.Years, s.Months, s.Days)
+}
+
+// isPositive returns true if adding the span moves time forward.
+func (s Span) isPositive() bool {
+	t := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return s.addTo(t).After(t)
+}
+
+// Window holds an in-sample period followed by an out-of-sample period.
+// Each period includes its start and excludes its end.
+type Window struct {
+	InSampleStart    time.Time
+	InSampleEnd      time.Time
+	OutOfSampleStart time.Time
+	OutOfSampleEnd   time.Time
+}
+
+// buildWindows returns windows whose out-of-sample periods follow each other
+// from start + inSample until end, where anchored windows keep the in-sample
+// start fixed and rolling windows move it forward.
+func buildWindows(start time.Time, end time.Time, inSample Span, outOfSample Span, anchored bool) ([]Window, error) {
+	if !inSample.isPositive() || !outOfSample.isPositive() {
+		return nil, errors.New("the in-sample and out-of-sample spans must be positive")
+	}
+
+	var windows []Window
+	inSampleStart := start
+	inSampleEnd := inSample.addTo(start)
+	for inSampleEnd.Before(end) {
+		outOfSampleEnd := outOfSample.addTo(inSampleEnd)
+		if outOfSampleEnd.After(end) {
+			outOfSampleEnd = end
+		}
+		windows = append(windows, Window{
+			InSampleStart:    inSampleStart,
+			InSampleEnd:      inSampleEnd,
+			OutOfSampleStart: inSampleEnd,
+			OutOfSampleEnd:   outOfSampleEnd,
+		})
+		if !anchored {
+			inSampleStart = outOfSample.addTo(inSampleStart)
+		}
+		inSampleEnd = outOfSampleEnd
+	}
+
+	if len(windows) == 0 {
+		return nil, errors.New("the timeline is too short for a single window")
+	}
+	return windows, nil
+}
+
+// RollingWindows returns windows with in-sample periods of a fixed length
+// that roll forward by the out-of-sample span.
+func RollingWindows(start time.Time, end time.Time, inSample Span, outOfSample Span) ([]Window, error) {
+	return buildWindows(start, end, inSample, outOfSample, false)
+}
+
+// AnchoredWindows returns windows whose in-sample periods all begin at start
+// and grow by the out-of-sample span.
+func AnchoredWindows(start time.Time, end time.Time, inSample Span, outOfSample Span) ([]Window, error) {
+	return buildWindows(start, end, inSample, outOfSample, true)
+}
+
+// WindowFactory builds an independent backtest for some parameters
+// over the events from start up to but excluding end.
+type WindowFactory func(params Params, start time.Time, end time.Time) (*backtest.Backtest, error)
+
+// WindowResult holds the outcome of one walk-forward window.
+type WindowResult struct {
+	window           Window
+	params           Params
+	inSampleScore    float64
+	outOfSampleScore float64
+	outOfSample      *backtest.Backtest
+}
+
+// GetWindow returns the window.
+func (r WindowResult) GetWindow() Window {
+	return r.window
+}
+
+// GetParams returns the parameters chosen in-sample.
+func (r WindowResult) GetParams() Params {
+	return r.params
+}
+
+// GetInSampleScore returns the best in-sample score.
+func (r WindowResult) GetInSampleScore() float64 {
+	return r.inSampleScore
+}
+
+// GetOutOfSampleScore returns the score of the chosen parameters out-of-sample.
+func (r WindowResult) GetOutOfSampleScore() float64 {
+	return r.outOfSampleScore
+}
+
+// GetOutOfSampleBacktest returns the completed out-of-sample backtest.
+func (r WindowResult) GetOutOfSampleBacktest() *backtest.Backtest {
+	return r.outOfSample
+}
+
+// EquityPoint is a portfolio value at some time.
+type EquityPoint struct {
+	Time  time.Time
+	Value float64
+}
+
+// WalkForwardResult holds the outcome of each walk-forward window.
+type WalkForwardResult struct {
+	windows []WindowResult
+}
+
+// GetWindows returns the result for each window in time order.
+func (r *WalkForwardResult) GetWindows() []WindowResult {
+	return r.windows
+}
+
+// StitchedEquity joins the out-of-sample values of the portfolio with some
+// code across all windows. Each window's values are rescaled to begin where
+// the previous window ended, so the curve compounds out-of-sample returns
+// from the first window's starting value.
+func (r *WalkForwardResult) StitchedEquity(portfolioCode string) ([]EquityPoint, error) {
+	var points []EquityPoint
+	for _, windowResult := range r.windows {
+		var series []float64
+		var times []time.Time
+		for _, p := range windowResult.outOfSample.GetPortfolios() {
+			if p.GetCode() == portfolioCode {
+				series = p.GetPortfolioSeries().Floats()
+				times = p.GetPortfolioSeries().Times()
+			}
+		}
+		if series == nil {
+			return nil, fmt.Errorf("portfolio code '%s' has no out-of-sample history", portfolioCode)
+		}
+
+		first := firstValid(series)
+		if first == len(series) {
+			continue // no valid values in this window
+		}
+		scale := 1.0
+		if len(points) > 0 {
+			if series[first] == 0 {
+				return nil, fmt.Errorf("portfolio code '%s' has zero value at '%s'", portfolioCode, times[first])
+			}
+			scale = points[len(points)-1].Value / series[first]
+		}
+		for i := first; i < len(series); i++ {
+			if !math.IsNaN(series[i]) {
+				points = append(points, EquityPoint{Time: times[i], Value: series[i] * scale})
+			}
+		}
+	}
+	return points, nil
+}
+
+// firstValid returns the index of the first value that is not NaN.
+func firstValid(values []float64) int {
+	for i, value := range values {
+		if !math.IsNaN(value) {
+			return i
+		}
+	}
+	return len(values)
+}
+
+// Report returns a table of the chosen parameters
+// and scores for each window.
+func (r *WalkForwardResult) Report() string {
+	const layout = "2006-01-02"
+	header := []string{"In sample", "Out of sample", "In score", "Out score", "Params"}
+	rows := [][]string{header}
+	for _, w := range r.windows {
+		rows = append(rows, []string{
+			w.window.InSampleStart.Format(layout) + " - " + w.window.InSampleEnd.Format(layout),
+			w.window.OutOfSampleStart.Format(layout) + " - " + w.window.OutOfSampleEnd.Format(layout),
+			fmt.Sprintf("%0.4f", w.inSampleScore),
+			fmt.Sprintf("%0.4f", w.outOfSampleScore),
+			w.params.String(),
+		})
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+	var output strings.Builder
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(row)-1 {
+				cell = btutil.PadRight(cell, " ", uint(widths[i]+2))
+			}
+			output.WriteString(cell)
+		}
+		output.WriteString("\n")
+	}
+	return output.String()
+}
+
+// WalkForward optimises parameters on each in-sample window and
+// evaluates the best parameters on the following out-of-sample window.
+type WalkForward struct {
+	factory   WindowFactory
+	objective Objective
+	space     IParameterSpace
+	workers   int
+}
+
+// NewWalkForward returns a new walk-forward analysis.
+func NewWalkForward(factory WindowFactory, objective Objective, space IParameterSpace) (*WalkForward, error) {
+	if factory == nil || objective == nil || space == nil {
+		return nil, errors.New("walk-forward analysis requires a factory, an objective and a parameter space")
+	}
+	return &WalkForward{factory: factory, objective: objective, space: space, workers: 1}, nil
+}
+
+// SetWorkers sets the number of in-sample backtests run concurrently.
+func (wf *WalkForward) SetWorkers(workers int) error {
+	if workers < 1 {
+		return errors.New("the number of workers must be at least one")
+	}
+	wf.workers = workers
+	return nil
+}
+
+// Run runs the analysis over some windows.
+func (wf *WalkForward) Run(windows []Window) (*WalkForwardResult, error) {
+	result := &WalkForwardResult{}
+	for _, window := range windows {
+		inSample := func(params Params) (*backtest.Backtest, error) {
+			return wf.factory(params, window.InSampleStart, window.InSampleEnd)
+		}
+		optimiser, err := NewOptimiser(inSample, wf.objective)
+		if err != nil {
+			return nil, err
+		}
+		optimiser.SetWorkers(wf.workers)
+		ranked, err := optimiser.Run(wf.space)
+		if err != nil {
+			return nil, err
+		}
+		best := ranked[0]
+		if best.GetError() != nil || math.IsNaN(best.GetScore()) {
+			return nil, fmt.Errorf("no in-sample run succeeded for the window starting '%s' - %v",
+				window.InSampleStart.Format("2006-01-02"), best.GetError())
+		}
+
+		outOfSample, err := wf.factory(best.GetParams(), window.OutOfSampleStart, window.OutOfSampleEnd)
+		if err != nil {
+			return nil, err
+		}
+		if err := outOfSample.Run(); err != nil {
+			return nil, err
+		}
+		outOfSampleScore, err := wf.objective(outOfSample)
+		if err != nil {
+			return nil, err
+		}
+
+		result.windows = append(result.windows, WindowResult{
+			window:           window,
+			params:           best.GetParams(),
+			inSampleScore:    best.GetScore(),
+			outOfSampleScore: outOfSampleScore,
+			outOfSample:      outOfSample,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package optimise
+
+import (
+	"gobacktrader/analytics"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRollingWindows(t *testing.T) {
+	start, end := btutil.Date(2021, 1, 1), btutil.Date(2021, 7, 1)
+	windows, err := RollingWindows(start, end, Span{Months: 3}, Span{Months: 1})
+	if err != nil {
+		t.Fatalf("Error in RollingWindows - %s", err)
+	}
+	if len(windows) != 3 {
+		t.Fatalf("Unexpected number of windows - wanted 3, got %d", len(windows))
+	}
+	last := windows[2]
+	if !last.InSampleStart.Equal(btutil.Date(2021, 3, 1)) || !last.OutOfSampleStart.Equal(btutil.Date(2021, 6, 1)) ||
+		!last.OutOfSampleEnd.Equal(end) {
+		t.Errorf("Unexpected last window - %v", last)
+	}
+
+	if _, err := RollingWindows(start, end, Span{}, Span{Months: 1}); err == nil {
+		t.Error("Expecting error for an empty span")
+	}
+	if _, err := RollingWindows(start, end, Span{Years: 1}, Span{Months: 1}); err == nil {
+		t.Error("Expecting error where the timeline is too short")
+	}
+}
+
+func TestAnchoredWindows(t *testing.T) {
+	start, end := btutil.Date(2021, 1, 1), btutil.Date(2021, 6, 15)
+	windows, err := AnchoredWindows(start, end, Span{Months: 3}, Span{Months: 2})
+	if err != nil {
+		t.Fatalf("Error in AnchoredWindows - %s", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("Unexpected number of windows - wanted 2, got %d", len(windows))
+	}
+	for _, window := range windows {
+		if !window.InSampleStart.Equal(start) {
+			t.Error("Expecting anchored windows to share a start")
+		}
+	}
+	if !windows[1].InSampleEnd.Equal(btutil.Date(2021, 6, 1)) || !windows[1].OutOfSampleEnd.Equal(end) {
+		t.Errorf("Unexpected truncated window - %v", windows[1])
+	}
+}
+
+// risingFactory buys some units of a stock rising 1% each day on the first day.
+func risingFactory(params Params, start time.Time, end time.Time) (*backtest.Backtest, error) {
+	units, err := params.Get("units")
+	if err != nil {
+		return nil, err
+	}
+	var portfolio *asset.Portfolio
+	bought := false
+	bt := backtest.NewContextBacktest(backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+		if bought {
+			return nil, nil
+		}
+		bought = true
+		stock, err := ctx.GetAsset("ZZB AU")
+		if err != nil {
+			return nil, err
+		}
+		return []*trade.Trade{ctx.NewTrade(portfolio, stock, units)}, nil
+	}))
+
+	// cash is scoped to this backtest
+	portfolio, err1 := bt.NewPortfolio("P", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := bt.GetCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		return nil, err
+	}
+	portfolio.Transfer(cash, 1000)
+	bt.RegisterAsset(stock)
+
+	origin := btutil.Date(2021, 1, 1)
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		days := day.Sub(origin).Hours() / 24
+		event := events.NewAssetPriceEvent(stock, day, asset.Price{Float64: 1 + 0.01*days, Valid: true})
+		bt.AddEvent(&event)
+	}
+	return &bt, nil
+}
+
+func TestWalkForward(t *testing.T) {
+	if _, err := NewWalkForward(nil, nil, nil); err == nil {
+		t.Error("Expecting error without a factory")
+	}
+	grid := NewGrid()
+	grid.AddValues("units", 0, 200, 400)
+	wf, err := NewWalkForward(risingFactory, PortfolioObjective("P", analytics.TotalReturn), grid)
+	if err != nil {
+		t.Fatalf("Error in NewWalkForward - %s", err)
+	}
+	wf.SetWorkers(2)
+
+	windows, _ := RollingWindows(btutil.Date(2021, 1, 1), btutil.Date(2021, 4, 1), Span{Months: 1}, Span{Months: 1})
+	result, err := wf.Run(windows)
+	if err != nil {
+		t.Fatalf("Error in wf.Run() - %s", err)
+	}
+	if len(result.GetWindows()) != 2 {
+		t.Fatalf("Unexpected number of window results - wanted 2, got %d", len(result.GetWindows()))
+	}
+	for _, w := range result.GetWindows() {
+		if w.GetParams()["units"] != 400 {
+			t.Errorf("Unexpected chosen parameters - %s", w.GetParams())
+		}
+		if w.GetInSampleScore() <= 0 || w.GetOutOfSampleScore() <= 0 || w.GetOutOfSampleBacktest() == nil {
+			t.Error("Expecting positive in and out of sample scores")
+		}
+	}
+
+	equity, err := result.StitchedEquity("P")
+	if err != nil {
+		t.Fatalf("Error in StitchedEquity - %s", err)
+	}
+	// February has 28 days and March 31
+	if len(equity) != 59 {
+		t.Fatalf("Unexpected stitched length - wanted 59, got %d", len(equity))
+	}
+	if equity[0].Value != 1000 {
+		t.Errorf("Unexpected starting value - wanted 1000, got %0.2f", equity[0].Value)
+	}
+	// the first value of March continues from the last value of February
+	if equity[28].Value != equity[27].Value {
+		t.Errorf("Expecting windows to be joined - got %0.4f then %0.4f", equity[27].Value, equity[28].Value)
+	}
+	for i := 1; i < len(equity); i++ {
+		if equity[i].Value < equity[i-1].Value || !equity[i].Time.After(equity[i-1].Time) {
+			t.Fatalf("Expecting a rising curve in time order at %d", i)
+		}
+	}
+	if _, err := result.StitchedEquity("XXX"); err == nil {
+		t.Error("Expecting error for a missing portfolio")
+	}
+
+	report := result.Report()
+	if lines := strings.Split(strings.TrimSpace(report), "\n"); len(lines) != 3 {
+		t.Errorf("Unexpected report - %s", report)
+	}
+	if !strings.Contains(report, "2021-02-01 - 2021-03-01") || !strings.Contains(report, "units=400") {
+		t.Errorf("Unexpected report - %s", report)
+	}
+}
@@ -0,0 +1,457 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fogleman/gg"
+)
+
+// palette holds the colours used for chart series in order.
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+// PNGOptions sets the size of charts rendered to png.
+type PNGOptions struct {
+	Width  int
+	Height int
+}
+
+// NewPNGOptions returns options for 1200 by 600 pixel charts.
+func NewPNGOptions() PNGOptions {
+	return PNGOptions{Width: 1200, Height: 600}
+}
+
+func (o PNGOptions) validate() error {
+	if o.Width < 200 || o.Height < 150 {
+		return errors.New("png charts must be at least 200 by 150 pixels")
+	}
+	return nil
+}
+
+// chartSeries is a named series of values aligned with chart times,
+// where NaN values leave a gap.
+type chartSeries struct {
+	name   string
+	values []float64
+}
+
+// lineChart is a chart of series over time. Stacked charts draw each
+// series as an area on top of the series before it, with negative
+// values such as short weights stacked separately below zero.
+type lineChart struct {
+	title   string
+	yLabel  string
+	times   []time.Time
+	series  []chartSeries
+	stacked bool
+	percent bool
+}
+
+// margins around the plot area in pixels.
+const (
+	marginLeft   = 80.0
+	marginRight  = 50.0
+	marginTop    = 50.0
+	marginBottom = 60.0
+)
+
+// niceTicks returns evenly spaced round values covering a range.
+func niceTicks(min float64, max float64, count int) []float64 {
+	if max <= min {
+		max = min + 1
+	}
+	rough := (max - min) / float64(count)
+	magnitude := math.Pow(10, math.Floor(math.Log10(rough)))
+	step := magnitude * 10
+	for _, multiple := range []float64{1, 2, 2.5, 5, 10} {
+		if rough <= multiple*magnitude {
+			step = multiple * magnitude
+			break
+		}
+	}
+	var ticks []float64
+	first, last := math.Floor(min/step), math.Ceil(max/step)
+	for i := first; i <= last; i++ {
+		ticks = append(ticks, i*step)
+	}
+	return ticks
+}
+
+// dateFormat returns a layout for date ticks suited to some time span.
+func dateFormat(span time.Duration) string {
+	days := span.Hours() / 24
+	switch {
+	case days > 3*365:
+		return "2006"
+	case days > 90:
+		return "Jan 2006"
+	default:
+		return "02 Jan 06"
+	}
+}
+
+// dateTicks returns the indices of up to some number of evenly spaced times.
+func dateTicks(times []time.Time, count int) []int {
+	if len(times) <= count {
+		indices := make([]int, len(times))
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
+	}
+	indices := make([]int, count)
+	for i := range indices {
+		indices[i] = int(math.Round(float64(i) * float64(len(times)-1) / float64(count-1)))
+	}
+	return indices
+}
+
+// stack tracks the running totals of positive and negative
+// values at each time for stacked charts.
+type stack struct {
+	positive []float64
+	negative []float64
+}
+
+func newStack(n int) stack {
+	return stack{positive: make([]float64, n), negative: make([]float64, n)}
+}
+
+// add stacks a value at some index, returning the base
+// and top of its area.
+func (s stack) add(i int, value float64) (float64, float64) {
+	totals := s.positive
+	if value < 0 {
+		totals = s.negative
+	}
+	base := totals[i]
+	if !math.IsNaN(value) {
+		totals[i] += value
+	}
+	return base, totals[i]
+}
+
+// bounds returns the range of values to plot.
+func (c lineChart) bounds() (float64, float64) {
+	min, max := math.Inf(1), math.Inf(-1)
+	totals := newStack(len(c.times))
+	for _, s := range c.series {
+		for i, value := range s.values {
+			if math.IsNaN(value) {
+				continue
+			}
+			if c.stacked {
+				_, value = totals.add(i, value)
+			}
+			min, max = math.Min(min, value), math.Max(max, value)
+		}
+	}
+	if c.stacked {
+		min = math.Min(min, 0)
+	}
+	if math.IsInf(min, 0) {
+		return 0, 1
+	}
+	return min, max
+}
+
+func (c lineChart) formatValue(value float64) string {
+	if c.percent {
+		return fmt.Sprintf("%.0f%%", value*100)
+	}
+	if math.Abs(value) >= 10000 {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", value), "0"), ".")
+}
+
+// render draws the chart.
+func (c lineChart) render(options PNGOptions) (*gg.Context, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	if len(c.times) < 2 {
+		return nil, fmt.Errorf("chart '%s' needs at least two times", c.title)
+	}
+	start, end := c.times[0], c.times[len(c.times)-1]
+	if !end.After(start) {
+		return nil, fmt.Errorf("chart '%s' needs times spanning some period", c.title)
+	}
+
+	width, height := float64(options.Width), float64(options.Height)
+	plotWidth := width - marginLeft - marginRight
+	plotHeight := height - marginTop - marginBottom
+	dc := gg.NewContext(options.Width, options.Height)
+	dc.SetHexColor("#ffffff")
+	dc.Clear()
+
+	min, max := c.bounds()
+	ticks := niceTicks(min, max, 6)
+	low, high := ticks[0], ticks[len(ticks)-1]
+	if high == low {
+		high = low + 1
+	}
+	x := func(t time.Time) float64 {
+		return marginLeft + plotWidth*float64(t.Sub(start))/float64(end.Sub(start))
+	}
+	y := func(value float64) float64 {
+		return marginTop + plotHeight*(1-(value-low)/(high-low))
+	}
+
+	// grid lines and value ticks
+	dc.SetLineWidth(1)
+	for _, tick := range ticks {
+		dc.SetHexColor("#e0e0e0")
+		dc.DrawLine(marginLeft, y(tick), marginLeft+plotWidth, y(tick))
+		dc.Stroke()
+		dc.SetHexColor("#333333")
+		dc.DrawStringAnchored(c.formatValue(tick), marginLeft-8, y(tick), 1, 0.5)
+	}
+
+	// date ticks
+	layout := dateFormat(end.Sub(start))
+	for _, i := range dateTicks(c.times, 7) {
+		tickX := x(c.times[i])
+		dc.SetHexColor("#333333")
+		dc.DrawLine(tickX, marginTop+plotHeight, tickX, marginTop+plotHeight+5)
+		dc.Stroke()
+		dc.DrawStringAnchored(c.times[i].Format(layout), tickX, marginTop+plotHeight+18, 0.5, 0.5)
+	}
+
+	// series
+	totals := newStack(len(c.times))
+	for n, s := range c.series {
+		dc.SetHexColor(palette[n%len(palette)])
+		if c.stacked {
+			lower := make([]float64, len(c.times))
+			upper := make([]float64, len(c.times))
+			for i := range c.times {
+				value := math.NaN()
+				if i < len(s.values) {
+					value = s.values[i]
+				}
+				lower[i], upper[i] = totals.add(i, value)
+			}
+			dc.MoveTo(x(c.times[0]), y(upper[0]))
+			for i := 1; i < len(c.times); i++ {
+				dc.LineTo(x(c.times[i]), y(upper[i]))
+			}
+			for i := len(c.times) - 1; i >= 0; i-- {
+				dc.LineTo(x(c.times[i]), y(lower[i]))
+			}
+			dc.ClosePath()
+			dc.Fill()
+			continue
+		}
+		dc.SetLineWidth(2)
+		drawing := false
+		for i, value := range s.values {
+			if math.IsNaN(value) {
+				drawing = false
+				continue
+			}
+			if drawing {
+				dc.LineTo(x(c.times[i]), y(value))
+			} else {
+				dc.MoveTo(x(c.times[i]), y(value))
+				drawing = true
+			}
+		}
+		dc.Stroke()
+	}
+
+	// axes, labels and title
+	dc.SetHexColor("#333333")
+	dc.SetLineWidth(1)
+	dc.DrawLine(marginLeft, marginTop, marginLeft, marginTop+plotHeight)
+	dc.DrawLine(marginLeft, marginTop+plotHeight, marginLeft+plotWidth, marginTop+plotHeight)
+	dc.Stroke()
+	dc.DrawStringAnchored(c.title, width/2, marginTop/2, 0.5, 0.5)
+	dc.DrawStringAnchored("Date", marginLeft+plotWidth/2, height-15, 0.5, 0.5)
+	dc.Push()
+	dc.RotateAbout(gg.Radians(-90), 15, marginTop+plotHeight/2)
+	dc.DrawStringAnchored(c.yLabel, 15, marginTop+plotHeight/2, 0.5, 0.5)
+	dc.Pop()
+
+	// legend on a background so that it is visible over areas
+	legendX, legendY := marginLeft+15, marginTop+15
+	legendWidth := 0.0
+	for _, s := range c.series {
+		textWidth, _ := dc.MeasureString(s.name)
+		legendWidth = math.Max(legendWidth, textWidth)
+	}
+	dc.DrawRectangle(legendX-6, legendY-11, legendWidth+28, float64(len(c.series))*18+4)
+	dc.SetHexColor("#ffffff")
+	dc.FillPreserve()
+	dc.SetHexColor("#cccccc")
+	dc.Stroke()
+	for n, s := range c.series {
+		dc.SetHexColor(palette[n%len(palette)])
+		dc.DrawRectangle(legendX, legendY+float64(n)*18-5, 10, 10)
+		dc.Fill()
+		dc.SetHexColor("#333333")
+		dc.DrawStringAnchored(s.name, legendX+16, legendY+float64(n)*18, 0, 0.5)
+	}
+	return dc, nil
+}
+
+// encode writes the chart as png.
+func (c lineChart) encode(w io.Writer, options PNGOptions) error {
+	dc, err := c.render(options)
+	if err != nil {
+		return err
+	}
+	return dc.EncodePNG(w)
+}
+
+// save writes the chart to a png file.
+func (c lineChart) save(filePath string, options PNGOptions) error {
+	dc, err := c.render(options)
+	if err != nil {
+		return err
+	}
+	return dc.SavePNG(filePath)
+}
+
+// portfolioCodes returns the portfolios in the results in run order.
+func portfolioCodes(results *backtest.Results) []string {
+	if len(results.Metadata.Portfolios) > 0 {
+		return results.Metadata.Portfolios
+	}
+	var codes []string
+	for code := range results.Equity {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// snapshotTimes returns the times of every equity snapshot in the results.
+func snapshotTimes(results *backtest.Results) []time.Time {
+	seen := make(map[time.Time]bool)
+	var times []time.Time
+	for _, series := range results.Equity {
+		for _, point := range series {
+			if !seen[point.Time] {
+				seen[point.Time] = true
+				times = append(times, point.Time)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func equityChart(results *backtest.Results) lineChart {
+	chart := lineChart{title: "Portfolio value", yLabel: "Value", times: snapshotTimes(results)}
+	for _, code := range portfolioCodes(results) {
+		chart.series = append(chart.series, chartSeries{name: code, values: alignAsOf(chart.times, results.Equity[code])})
+	}
+	return chart
+}
+
+func drawdownChart(results *backtest.Results) lineChart {
+	chart := lineChart{title: "Drawdown", yLabel: "Drawdown", times: snapshotTimes(results), percent: true}
+	for _, code := range portfolioCodes(results) {
+		chart.series = append(chart.series, chartSeries{name: code, values: drawdowns(alignAsOf(chart.times, results.Equity[code]))})
+	}
+	return chart
+}
+
+func weightsChart(results *backtest.Results, portfolioCode string) (lineChart, error) {
+	holdings, ok := results.Holdings[portfolioCode]
+	if !ok {
+		return lineChart{}, fmt.Errorf("portfolio code '%s' is not in the backtest results", portfolioCode)
+	}
+	chart := lineChart{title: "Weights for " + portfolioCode, yLabel: "Weight", stacked: true, percent: true}
+	tickerSet := make(map[string]bool)
+	for _, point := range holdings {
+		chart.times = append(chart.times, point.Time)
+		for ticker := range point.Weights {
+			tickerSet[ticker] = true
+		}
+	}
+	var tickers []string
+	for ticker := range tickerSet {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+	for _, ticker := range tickers {
+		values := make([]float64, len(holdings))
+		for i, point := range holdings {
+			values[i] = point.Weights[ticker]
+		}
+		chart.series = append(chart.series, chartSeries{name: ticker, values: values})
+	}
+	return chart, nil
+}
+
+// EquityPNG writes a chart of the value of each portfolio to png.
+func EquityPNG(w io.Writer, results *backtest.Results, options PNGOptions) error {
+	return equityChart(results).encode(w, options)
+}
+
+// DrawdownPNG writes a chart of the drawdown of each portfolio to png.
+func DrawdownPNG(w io.Writer, results *backtest.Results, options PNGOptions) error {
+	return drawdownChart(results).encode(w, options)
+}
+
+// WeightsPNG writes a stacked chart of the weights of
+// each holding in a portfolio to png.
+func WeightsPNG(w io.Writer, results *backtest.Results, portfolioCode string, options PNGOptions) error {
+	chart, err := weightsChart(results, portfolioCode)
+	if err != nil {
+		return err
+	}
+	return chart.encode(w, options)
+}
+
+// SavePNGCharts writes equity and drawdown charts for all portfolios and
+// a weights chart for each portfolio to a directory from the history of
+// a completed backtest, returning the paths of the files written.
+func SavePNGCharts(bt *backtest.Backtest, dir string, options PNGOptions) ([]string, error) {
+	results, err := bt.GetResults()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	charts := map[string]lineChart{
+		"equity.png":   equityChart(results),
+		"drawdown.png": drawdownChart(results),
+	}
+	names := []string{"equity.png", "drawdown.png"}
+	for _, code := range portfolioCodes(results) {
+		chart, err := weightsChart(results, code)
+		if err != nil {
+			return nil, err
+		}
+		name := "weights_" + btutil.CleanFileName(code) + ".png"
+		charts[name] = chart
+		names = append(names, name)
+	}
+
+	var paths []string
+	for _, name := range names {
+		filePath := filepath.Join(dir, name)
+		if err := charts[name].save(filePath, options); err != nil {
+			return paths, err
+		}
+		paths = append(paths, filePath)
+	}
+	return paths, nil
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bytes"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNiceTicks(t *testing.T) {
+	ticks := niceTicks(0.3, 9.7, 5)
+	if !reflect.DeepEqual(ticks, []float64{0, 2, 4, 6, 8, 10}) {
+		t.Errorf("Unexpected ticks - %v", ticks)
+	}
+	ticks = niceTicks(5, 5, 5)
+	if len(ticks) < 2 || ticks[0] > 5 || ticks[len(ticks)-1] < 5 {
+		t.Errorf("Unexpected ticks for a flat range - %v", ticks)
+	}
+}
+
+func TestDateTicks(t *testing.T) {
+	if layout := dateFormat(10 * 365 * 24 * time.Hour); layout != "2006" {
+		t.Errorf("Unexpected layout for ten years - %s", layout)
+	}
+	if layout := dateFormat(30 * 24 * time.Hour); layout != "02 Jan 06" {
+		t.Errorf("Unexpected layout for a month - %s", layout)
+	}
+	times := make([]time.Time, 13)
+	if indices := dateTicks(times, 7); !reflect.DeepEqual(indices, []int{0, 2, 4, 6, 8, 10, 12}) {
+		t.Errorf("Unexpected tick indices - %v", indices)
+	}
+	if indices := dateTicks(times[:3], 7); !reflect.DeepEqual(indices, []int{0, 1, 2}) {
+		t.Errorf("Unexpected tick indices for few times - %v", indices)
+	}
+}
+
+func TestPNGCharts(t *testing.T) {
+	results := testResults()
+	options := NewPNGOptions()
+	options.Width, options.Height = 400, 300
+
+	var buffer bytes.Buffer
+	if err := EquityPNG(&buffer, results, options); err != nil {
+		t.Fatalf("Error in EquityPNG - %s", err)
+	}
+	image, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatalf("Error in Decode - %s", err)
+	}
+	if image.Bounds().Dx() != 400 || image.Bounds().Dy() != 300 {
+		t.Errorf("Unexpected image size - %v", image.Bounds())
+	}
+
+	buffer.Reset()
+	if err := DrawdownPNG(&buffer, results, options); err != nil {
+		t.Errorf("Error in DrawdownPNG - %s", err)
+	}
+	buffer.Reset()
+	if err := WeightsPNG(&buffer, results, "XXX", options); err != nil {
+		t.Errorf("Error in WeightsPNG - %s", err)
+	}
+	if err := WeightsPNG(&buffer, results, "YYY", options); err == nil {
+		t.Errorf("Expected error for an unknown portfolio")
+	}
+	if err := EquityPNG(&buffer, results, PNGOptions{Width: 10, Height: 10}); err == nil {
+		t.Errorf("Expected error for a tiny chart")
+	}
+}
+
+func TestLineChart(t *testing.T) {
+	day := btutil.Date(2021, 1, 4)
+	chart := lineChart{
+		title:   "Weights",
+		times:   []time.Time{day, day.AddDate(0, 0, 1)},
+		stacked: true,
+		series: []chartSeries{
+			{name: "A", values: []float64{0.6, 0.8}},
+			{name: "B", values: []float64{-0.3, -0.5}},
+			{name: "C", values: []float64{0.7, math.NaN()}},
+		},
+	}
+
+	// short weights stack below zero
+	if min, max := chart.bounds(); btutil.Round2dp(min) != -0.5 || btutil.Round2dp(max) != 1.3 {
+		t.Errorf("Unexpected stacked bounds - %f to %f", min, max)
+	}
+	if _, err := chart.render(NewPNGOptions()); err != nil {
+		t.Errorf("Error in render - %s", err)
+	}
+
+	chart.times[1] = day
+	_, err := chart.render(NewPNGOptions())
+	errStr := btutil.GetErrorString(err)
+	if errStr != "chart 'Weights' needs times spanning some period" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+}
+
+func TestSavePNGCharts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "charts")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	bt := backtest.NewBacktest(nil)
+	if _, err := SavePNGCharts(&bt, dir, NewPNGOptions()); err == nil {
+		t.Errorf("Expected error for a backtest that has not run")
+	}
+
+	portfolio, err1 := bt.NewPortfolio("XXX/1", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	cash, err3 := bt.GetCash("AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in setup - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+	portfolio.Transfer(stock, 100)
+	bt.RegisterAsset(stock)
+	for i := 0; i < 20; i++ {
+		event := events.NewAssetPriceEvent(stock, btutil.Date(2021, 3, 1).AddDate(0, 0, i), asset.Price{Float64: 10 + float64(i%4), Valid: true})
+		bt.AddEvent(&event)
+	}
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	paths, err := SavePNGCharts(&bt, filepath.Join(dir, "out"), NewPNGOptions())
+	if err != nil {
+		t.Fatalf("Error in SavePNGCharts - %s", err)
+	}
+	expected := []string{"equity.png", "drawdown.png", "weights_XXX_1.png"}
+	if len(paths) != len(expected) {
+		t.Fatalf("Unexpected paths - %v", paths)
+	}
+	for i, path := range paths {
+		if filepath.Base(path) != expected[i] {
+			t.Errorf("Unexpected chart file - %s", path)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Error in Open - %s", err)
+		}
+		if _, err := png.Decode(file); err != nil {
+			t.Errorf("Error in Decode for '%s' - %s", path, err)
+		}
+		file.Close()
+	}
+}
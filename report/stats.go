@@ -0,0 +1,175 @@
+// Package report renders backtest results for sharing.
+package report
+
+import (
+	"gobacktrader/analytics"
+	"gobacktrader/backtest"
+	"math"
+	"sort"
+	"time"
+)
+
+// inferPeriodsPerYear returns the number of observations per year
+// implied by the average spacing of some times, or NaN where there
+// are fewer than two times.
+func inferPeriodsPerYear(times []time.Time) float64 {
+	if len(times) < 2 {
+		return math.NaN()
+	}
+	span := times[len(times)-1].Sub(times[0]).Hours() / 24
+	if span <= 0 {
+		return math.NaN()
+	}
+	return 365.25 * float64(len(times)-1) / span
+}
+
+// alignAsOf returns the last value of a series at or before each time.
+func alignAsOf(times []time.Time, series backtest.ResultSeries) []float64 {
+	sorted := make(backtest.ResultSeries, len(series))
+	copy(sorted, series)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time.Before(sorted[j].Time) })
+
+	aligned := make([]float64, len(times))
+	for i, t := range times {
+		j := sort.Search(len(sorted), func(k int) bool { return sorted[k].Time.After(t) })
+		if j == 0 {
+			aligned[i] = math.NaN()
+		} else {
+			aligned[i] = sorted[j-1].Value
+		}
+	}
+	return aligned
+}
+
+// rebase scales values so that the first valid value is some base.
+// Values before the first valid value are NaN, as are all values
+// where there is no valid value to rebase from.
+func rebase(values []float64, base float64) []float64 {
+	rebased := make([]float64, len(values))
+	first := -1
+	for i, value := range values {
+		if !math.IsNaN(value) && value != 0 {
+			first = i
+			break
+		}
+	}
+	for i, value := range values {
+		if first < 0 || i < first {
+			rebased[i] = math.NaN()
+			continue
+		}
+		rebased[i] = value / values[first] * base
+	}
+	return rebased
+}
+
+// drawdowns returns the fall from the running peak at each value,
+// keeping NaN for invalid values so that results align with times.
+func drawdowns(values []float64) []float64 {
+	result := make([]float64, len(values))
+	peak := math.Inf(-1)
+	for i, value := range values {
+		if math.IsNaN(value) {
+			result[i] = math.NaN()
+			continue
+		}
+		peak = math.Max(peak, value)
+		result[i] = value/peak - 1
+	}
+	return result
+}
+
+// rolling applies a statistic to each trailing window of values,
+// which is NaN until a full window is available.
+func rolling(values []float64, window int, statistic func([]float64) float64) []float64 {
+	result := make([]float64, len(values))
+	for i := range values {
+		if i < window {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = statistic(values[i-window : i+1])
+	}
+	return result
+}
+
+// rollingVolatility returns the annualised volatility of returns
+// over each trailing window of some number of returns.
+func rollingVolatility(values []float64, window int, periodsPerYear float64) []float64 {
+	return rolling(values, window, func(w []float64) float64 {
+		return analytics.Volatility(w, periodsPerYear)
+	})
+}
+
+// rollingSharpe returns the annualised Sharpe ratio over each
+// trailing window of some number of returns.
+func rollingSharpe(values []float64, window int, periodsPerYear float64, riskFreeRate float64) []float64 {
+	return rolling(values, window, func(w []float64) float64 {
+		return analytics.Sharpe(w, periodsPerYear, riskFreeRate)
+	})
+}
+
+// monthlyReturn is the return over some calendar month.
+type monthlyReturn struct {
+	year  int
+	month time.Month
+	value float64
+}
+
+// monthlyReturns returns the return for each calendar month from the last
+// valid value of the previous month, or the first valid value for the
+// first month.
+func monthlyReturns(series backtest.ResultSeries) []monthlyReturn {
+	var returns []monthlyReturn
+	start := math.NaN()
+	for _, point := range series {
+		if math.IsNaN(point.Value) {
+			continue
+		}
+		if math.IsNaN(start) {
+			start = point.Value
+		}
+		// record the last value seen in each month
+		year, month, _ := point.Time.Date()
+		last := len(returns) - 1
+		if last >= 0 && returns[last].year == year && returns[last].month == month {
+			returns[last].value = point.Value
+		} else {
+			returns = append(returns, monthlyReturn{year: year, month: month, value: point.Value})
+		}
+	}
+
+	// then convert month end values into returns
+	for i := range returns {
+		end := returns[i].value
+		returns[i].value = end/start - 1
+		start = end
+	}
+	return returns
+}
+
+// turnover returns the value traded between each snapshot and the
+// previous one as a fraction of the portfolio value at the snapshot.
+// Trades before the first snapshot are counted at the first snapshot.
+// Turnover is NaN where the portfolio value is zero or NaN.
+func turnover(equity backtest.ResultSeries, trades []backtest.TradeResult, portfolioCode string) []float64 {
+	traded := make([]float64, len(equity))
+	for _, t := range trades {
+		if t.Portfolio != portfolioCode || !t.Executed || math.IsNaN(t.Price) {
+			continue
+		}
+		i := sort.Search(len(equity), func(k int) bool { return !equity[k].Time.Before(t.Time) })
+		if i < len(equity) {
+			traded[i] += math.Abs(t.Units * t.Price)
+		}
+	}
+	result := make([]float64, len(equity))
+	for i, point := range equity {
+		if point.Value == 0 || math.IsNaN(point.Value) {
+			result[i] = math.NaN()
+			continue
+		}
+		result[i] = traded[i] / point.Value
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestInferPeriodsPerYear(t *testing.T) {
+	weekly := []time.Time{btutil.Date(2021, 1, 1), btutil.Date(2021, 1, 8), btutil.Date(2021, 1, 15)}
+	if value := inferPeriodsPerYear(weekly); btutil.Round4dp(value) != btutil.Round4dp(365.25/7) {
+		t.Errorf("Unexpected weekly periods per year - %f", value)
+	}
+	if value := inferPeriodsPerYear(weekly[:1]); !math.IsNaN(value) {
+		t.Errorf("Expected NaN for a single time - %f", value)
+	}
+}
+
+func TestAlignAsOf(t *testing.T) {
+	series := backtest.ResultSeries{
+		{Time: btutil.Date(2021, 1, 5), Value: 2},
+		{Time: btutil.Date(2021, 1, 2), Value: 1},
+	}
+	times := []time.Time{btutil.Date(2021, 1, 1), btutil.Date(2021, 1, 3), btutil.Date(2021, 1, 5)}
+	aligned := alignAsOf(times, series)
+	if !math.IsNaN(aligned[0]) || aligned[1] != 1 || aligned[2] != 2 {
+		t.Errorf("Unexpected aligned values - %v", aligned)
+	}
+}
+
+func TestRebaseAndDrawdowns(t *testing.T) {
+	values := []float64{math.NaN(), 50, 100, 75}
+	rebased := rebase(values, 100)
+	if !math.IsNaN(rebased[0]) || rebased[1] != 100 || rebased[2] != 200 || rebased[3] != 150 {
+		t.Errorf("Unexpected rebased values - %v", rebased)
+	}
+	for _, value := range rebase([]float64{math.NaN(), 0, math.NaN()}, 100) {
+		if !math.IsNaN(value) {
+			t.Errorf("Expecting NaN without a valid value to rebase from, got %f", value)
+		}
+	}
+	falls := drawdowns(values)
+	if !math.IsNaN(falls[0]) || falls[1] != 0 || falls[2] != 0 || falls[3] != -0.25 {
+		t.Errorf("Unexpected drawdowns - %v", falls)
+	}
+}
+
+func TestRolling(t *testing.T) {
+	values := []float64{100, 101, 99, 102, 103}
+	volatility := rollingVolatility(values, 2, 52)
+	if !math.IsNaN(volatility[1]) || math.IsNaN(volatility[2]) {
+		t.Errorf("Unexpected rolling volatility warm-up - %v", volatility)
+	}
+	expected := math.Abs(99.0/101-101.0/100) / math.Sqrt(2) * math.Sqrt(52)
+	if btutil.Round4dp(volatility[2]) != btutil.Round4dp(expected) {
+		t.Errorf("Unexpected rolling volatility - wanted %f, got %f", expected, volatility[2])
+	}
+	sharpe := rollingSharpe(values, 3, 52, 0)
+	if !math.IsNaN(sharpe[2]) || math.IsNaN(sharpe[3]) {
+		t.Errorf("Unexpected rolling Sharpe warm-up - %v", sharpe)
+	}
+}
+
+func TestMonthlyReturns(t *testing.T) {
+	series := backtest.ResultSeries{
+		{Time: btutil.Date(2021, 1, 15), Value: 100},
+		{Time: btutil.Date(2021, 1, 29), Value: 100},
+		{Time: btutil.Date(2021, 2, 12), Value: 105},
+		{Time: btutil.Date(2021, 2, 26), Value: 110},
+		{Time: btutil.Date(2021, 3, 31), Value: math.NaN()},
+		{Time: btutil.Date(2021, 4, 30), Value: 99},
+	}
+	returns := monthlyReturns(series)
+	if len(returns) != 3 {
+		t.Fatalf("Unexpected number of monthly returns - %d", len(returns))
+	}
+	if returns[0].month != time.January || returns[0].value != 0 {
+		t.Errorf("Unexpected January return - %+v", returns[0])
+	}
+	if returns[1].month != time.February || btutil.Round4dp(returns[1].value) != 0.1 {
+		t.Errorf("Unexpected February return - %+v", returns[1])
+	}
+	if returns[2].month != time.April || btutil.Round4dp(returns[2].value) != -0.1 {
+		t.Errorf("Unexpected April return - %+v", returns[2])
+	}
+}
+
+func TestTurnover(t *testing.T) {
+	equity := backtest.ResultSeries{
+		{Time: btutil.Date(2021, 1, 1), Value: 1000},
+		{Time: btutil.Date(2021, 1, 8), Value: 2000},
+	}
+	trades := []backtest.TradeResult{
+		{Time: btutil.Date(2021, 1, 1), Portfolio: "XXX", Units: 10, Price: 10, Executed: true},
+		{Time: btutil.Date(2021, 1, 5), Portfolio: "XXX", Units: -5, Price: 20, Executed: true},
+		{Time: btutil.Date(2021, 1, 6), Portfolio: "XXX", Units: 100, Price: 10},
+		{Time: btutil.Date(2021, 1, 6), Portfolio: "YYY", Units: 100, Price: 10, Executed: true},
+	}
+	result := turnover(equity, trades, "XXX")
+	if result[0] != 0.1 || result[1] != 0.05 {
+		t.Errorf("Unexpected turnover - %v", result)
+	}
+
+	// no value gives NaN rather than an infinite turnover
+	equity[0].Value, equity[1].Value = 0, math.NaN()
+	result = turnover(equity, trades, "XXX")
+	if !math.IsNaN(result[0]) || !math.IsNaN(result[1]) {
+		t.Errorf("Expecting NaN turnover without a value - %v", result)
+	}
+}
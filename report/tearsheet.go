@@ -0,0 +1,557 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gobacktrader/analytics"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/calendar"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/components"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+// DefaultAssetsHost is where the echarts script is loaded from
+// unless a local copy is inlined with SetEChartsScript.
+const DefaultAssetsHost = "https://go-echarts.github.io/go-echarts-assets/assets/"
+
+const echartsScript = "echarts.min.js"
+
+// Tearsheet renders the results of a backtest for one portfolio as
+// a single HTML page of charts and tables.
+type Tearsheet struct {
+	results        *backtest.Results
+	portfolioCode  string
+	title          string
+	benchmarkName  string
+	benchmark      backtest.ResultSeries
+	periodsPerYear float64
+	calendar       calendar.ITradingCalendar
+	rollingWindow  int
+	riskFreeRate   float64
+	assetsHost     string
+	scriptPath     string
+	useCDN         bool
+}
+
+// NewTearsheet returns a new Tearsheet for a portfolio
+// from the results of a completed backtest. Returns are
+// annualised using the trading calendar of the first
+// asset in the backtest that has one set explicitly.
+func NewTearsheet(bt *backtest.Backtest, portfolioCode string) (*Tearsheet, error) {
+	results, err := bt.GetResults()
+	if err != nil {
+		return nil, err
+	}
+	tearsheet, err := NewTearsheetFromResults(results, portfolioCode)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range bt.GetAssets() {
+		if hasCalendar, ok := a.(asset.IHasCalendar); ok {
+			if cal, ok := hasCalendar.GetCalendar(); ok {
+				tearsheet.SetCalendar(cal)
+				break
+			}
+		}
+	}
+	return tearsheet, nil
+}
+
+// NewTearsheetFromResults returns a new Tearsheet for a portfolio
+// from backtest results, such as those reloaded from json.
+func NewTearsheetFromResults(results *backtest.Results, portfolioCode string) (*Tearsheet, error) {
+	if results == nil {
+		return nil, errors.New("a tearsheet requires backtest results")
+	}
+	if _, ok := results.Equity[portfolioCode]; !ok {
+		return nil, fmt.Errorf("portfolio code '%s' is not in the backtest results", portfolioCode)
+	}
+	return &Tearsheet{
+		results:        results,
+		portfolioCode:  portfolioCode,
+		title:          portfolioCode,
+		periodsPerYear: math.NaN(),
+		assetsHost:     DefaultAssetsHost,
+		useCDN:         true,
+	}, nil
+}
+
+// SetTitle sets the page title.
+func (t *Tearsheet) SetTitle(title string) {
+	t.title = title
+}
+
+// GetTitle returns the page title, which defaults to the portfolio code.
+func (t *Tearsheet) GetTitle() string {
+	return t.title
+}
+
+// SetBenchmark sets a series of benchmark values to compare against.
+func (t *Tearsheet) SetBenchmark(name string, series backtest.ResultSeries) error {
+	if len(series) == 0 {
+		return fmt.Errorf("benchmark '%s' has no values", name)
+	}
+	t.benchmarkName, t.benchmark = name, series
+	return nil
+}
+
+// SetBenchmarkTicker uses the price history of an asset
+// in the backtest results as the benchmark.
+func (t *Tearsheet) SetBenchmarkTicker(ticker string) error {
+	series, ok := t.results.Prices[ticker]
+	if !ok {
+		return fmt.Errorf("ticker '%s' is not in the backtest results", ticker)
+	}
+	return t.SetBenchmark(ticker, series)
+}
+
+// SetPeriodsPerYear sets the number of snapshots per year
+// used to annualise returns and volatility.
+func (t *Tearsheet) SetPeriodsPerYear(periodsPerYear float64) error {
+	if !(periodsPerYear > 0) {
+		return errors.New("the number of periods per year must be positive")
+	}
+	t.periodsPerYear = periodsPerYear
+	return nil
+}
+
+// GetPeriodsPerYear returns the number of snapshots per year unless it
+// has been set. This is taken from the trading calendar where there is
+// one and is otherwise inferred from the snapshot times.
+func (t *Tearsheet) GetPeriodsPerYear() float64 {
+	if !math.IsNaN(t.periodsPerYear) {
+		return t.periodsPerYear
+	}
+	times := t.results.Equity[t.portfolioCode].Times()
+	if t.calendar != nil {
+		if periodsPerYear := analytics.PeriodsPerYear(t.calendar, times); !math.IsNaN(periodsPerYear) {
+			return periodsPerYear
+		}
+	}
+	return inferPeriodsPerYear(times)
+}
+
+// SetCalendar sets the trading calendar used to annualise
+// returns and volatility. Pass nil to infer the number of
+// periods per year from the snapshot times instead.
+func (t *Tearsheet) SetCalendar(cal calendar.ITradingCalendar) {
+	t.calendar = cal
+}
+
+// GetCalendar returns the trading calendar, which is nil
+// where none has been set.
+func (t *Tearsheet) GetCalendar() calendar.ITradingCalendar {
+	return t.calendar
+}
+
+// SetRollingWindow sets the number of returns used for
+// rolling volatility and Sharpe ratios.
+func (t *Tearsheet) SetRollingWindow(window int) error {
+	if window < 2 {
+		return errors.New("the rolling window must be at least two periods")
+	}
+	t.rollingWindow = window
+	return nil
+}
+
+// GetRollingWindow returns the rolling window, which defaults
+// to the number of snapshots in three months.
+func (t *Tearsheet) GetRollingWindow() int {
+	if t.rollingWindow > 0 {
+		return t.rollingWindow
+	}
+	window := int(math.Round(t.GetPeriodsPerYear() / 4))
+	if window < 2 {
+		return 2
+	}
+	return window
+}
+
+// SetRiskFreeRate sets the annual risk free rate used for Sharpe ratios.
+func (t *Tearsheet) SetRiskFreeRate(rate float64) {
+	t.riskFreeRate = rate
+}
+
+// SetAssetsHost sets where the echarts script is loaded from when
+// the CDN is used, such as an internal mirror of DefaultAssetsHost.
+func (t *Tearsheet) SetAssetsHost(host string) {
+	if !strings.HasSuffix(host, "/") {
+		host += "/"
+	}
+	t.assetsHost = host
+}
+
+// SetEChartsScript inlines a local copy of echarts.min.js into the
+// page so that it can be viewed without network access.
+func (t *Tearsheet) SetEChartsScript(filePath string) {
+	t.scriptPath = filePath
+}
+
+// SetUseCDN sets whether the page may load the echarts script from
+// the assets host, which is the default. Pages that must be viewed
+// offline should set this to false and inline a local copy of the
+// script with SetEChartsScript.
+func (t *Tearsheet) SetUseCDN(useCDN bool) {
+	t.useCDN = useCDN
+}
+
+// GetUseCDN returns true if the page loads the echarts
+// script from the assets host.
+func (t *Tearsheet) GetUseCDN() bool {
+	return t.useCDN && t.scriptPath == ""
+}
+
+// script returns the echarts script to inline, or nil where
+// the page loads the script from the assets host. An error is
+// returned for an offline page without a local script.
+func (t *Tearsheet) script() ([]byte, error) {
+	if t.scriptPath != "" {
+		return ioutil.ReadFile(t.scriptPath)
+	}
+	if !t.useCDN {
+		return nil, errors.New("an offline tearsheet needs a local echarts script set with SetEChartsScript")
+	}
+	return nil, nil
+}
+
+// Save will write the tearsheet to an html file.
+func (t *Tearsheet) Save(filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return t.Render(file)
+}
+
+// Render writes the tearsheet as html.
+func (t *Tearsheet) Render(w io.Writer) error {
+	equity := t.results.Equity[t.portfolioCode]
+	if len(equity) < 2 {
+		return fmt.Errorf("portfolio '%s' needs at least two snapshots for a tearsheet", t.portfolioCode)
+	}
+	times, values := equity.Times(), equity.Values()
+	var benchmark []float64
+	if t.benchmark != nil {
+		benchmark = alignAsOf(times, t.benchmark)
+	}
+
+	page := components.NewPage()
+	page.PageTitle = t.title
+	page.AssetsHost = t.assetsHost
+	page.AddCharts(
+		t.equityChart(times, values, benchmark),
+		t.drawdownChart(times, values, benchmark),
+		t.rollingChart(times, values, benchmark, "Rolling volatility (%)", func(v []float64) []float64 {
+			return scale(rollingVolatility(v, t.GetRollingWindow(), t.GetPeriodsPerYear()), 100)
+		}),
+		t.rollingChart(times, values, benchmark, "Rolling Sharpe ratio", func(v []float64) []float64 {
+			return rollingSharpe(v, t.GetRollingWindow(), t.GetPeriodsPerYear(), t.riskFreeRate)
+		}),
+		t.monthlyChart(equity),
+		t.weightsChart(times),
+		t.turnoverChart(equity),
+	)
+
+	var buffer bytes.Buffer
+	if err := page.Render(&buffer); err != nil {
+		return err
+	}
+	html := buffer.String()
+
+	script, err := t.script()
+	if err != nil {
+		return err
+	}
+	if script != nil {
+		tag := fmt.Sprintf(`<script src="%s%s"></script>`, t.assetsHost, echartsScript)
+		if !strings.Contains(html, tag) {
+			return errors.New("cannot find the echarts script to inline")
+		}
+		html = strings.Replace(html, tag, "<script>"+string(script)+"</script>", 1)
+	}
+
+	var tables bytes.Buffer
+	if err := tablesTemplate.Execute(&tables, t.tables(values, benchmark)); err != nil {
+		return err
+	}
+	html = strings.Replace(html, "</body>", tables.String()+"</body>", 1)
+
+	_, err = io.WriteString(w, html)
+	return err
+}
+
+func (t *Tearsheet) initOpts(title string) []charts.GlobalOpts {
+	return []charts.GlobalOpts{
+		charts.WithInitializationOpts(opts.Initialization{Width: "1100px", Height: "360px", AssetsHost: t.assetsHost}),
+		charts.WithTitleOpts(opts.Title{Title: title}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true, Trigger: "axis"}),
+		charts.WithLegendOpts(opts.Legend{Show: true, Right: "10%"}),
+	}
+}
+
+func dateLabels(times []time.Time) []string {
+	labels := make([]string, len(times))
+	for i, t := range times {
+		labels[i] = t.Format("2006-01-02")
+	}
+	return labels
+}
+
+func scale(values []float64, factor float64) []float64 {
+	scaled := make([]float64, len(values))
+	for i, value := range values {
+		scaled[i] = value * factor
+	}
+	return scaled
+}
+
+// lineData returns chart values rounded for display, where
+// invalid values are shown as gaps.
+func lineData(values []float64) []opts.LineData {
+	data := make([]opts.LineData, len(values))
+	for i, value := range values {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			data[i] = opts.LineData{Value: "-"}
+		} else {
+			data[i] = opts.LineData{Value: math.Round(value*10000) / 10000}
+		}
+	}
+	return data
+}
+
+func (t *Tearsheet) equityChart(times []time.Time, values []float64, benchmark []float64) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(t.initOpts("Equity (rebased to 100)")...)
+	line.SetXAxis(dateLabels(times)).AddSeries(t.portfolioCode, lineData(rebase(values, 100)))
+	if benchmark != nil {
+		line.AddSeries(t.benchmarkName, lineData(rebase(benchmark, 100)))
+	}
+	return line
+}
+
+func (t *Tearsheet) drawdownChart(times []time.Time, values []float64, benchmark []float64) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(t.initOpts("Drawdown (%)")...)
+	line.SetXAxis(dateLabels(times)).AddSeries(t.portfolioCode, lineData(scale(drawdowns(values), 100)),
+		charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: 0.3}))
+	if benchmark != nil {
+		line.AddSeries(t.benchmarkName, lineData(scale(drawdowns(benchmark), 100)))
+	}
+	return line
+}
+
+func (t *Tearsheet) rollingChart(times []time.Time, values []float64, benchmark []float64, title string, statistic func([]float64) []float64) *charts.Line {
+	line := charts.NewLine()
+	line.SetGlobalOptions(t.initOpts(fmt.Sprintf("%s, %d periods", title, t.GetRollingWindow()))...)
+	line.SetXAxis(dateLabels(times)).AddSeries(t.portfolioCode, lineData(statistic(values)))
+	if benchmark != nil {
+		line.AddSeries(t.benchmarkName, lineData(statistic(benchmark)))
+	}
+	return line
+}
+
+func (t *Tearsheet) monthlyChart(equity backtest.ResultSeries) *charts.HeatMap {
+	returns := monthlyReturns(equity)
+	var years []string
+	yearIndex := make(map[int]int)
+	maxAbs := 0.0
+	var data []opts.HeatMapData
+	for _, r := range returns {
+		if _, ok := yearIndex[r.year]; !ok {
+			yearIndex[r.year] = len(years)
+			years = append(years, fmt.Sprint(r.year))
+		}
+		percent := math.Round(r.value*10000) / 100
+		maxAbs = math.Max(maxAbs, math.Abs(percent))
+		data = append(data, opts.HeatMapData{Value: [3]interface{}{int(r.month) - 1, yearIndex[r.year], percent}})
+	}
+	if maxAbs == 0 {
+		maxAbs = 1
+	}
+
+	months := make([]string, 12)
+	for i := range months {
+		months[i] = time.Month(i + 1).String()[:3]
+	}
+	heatMap := charts.NewHeatMap()
+	heatMap.SetGlobalOptions(
+		charts.WithInitializationOpts(opts.Initialization{Width: "1100px", Height: "360px", AssetsHost: t.assetsHost}),
+		charts.WithTitleOpts(opts.Title{Title: "Monthly returns (%)"}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: true}),
+		charts.WithXAxisOpts(opts.XAxis{Type: "category", Data: months}),
+		charts.WithYAxisOpts(opts.YAxis{Type: "category", Data: years}),
+		charts.WithVisualMapOpts(opts.VisualMap{
+			Calculable: true,
+			Min:        float32(-maxAbs),
+			Max:        float32(maxAbs),
+			InRange:    &opts.VisualMapInRange{Color: []string{"#d73027", "#ffffbf", "#1a9850"}},
+		}),
+	)
+	heatMap.SetXAxis(months).AddSeries("Return", data, charts.WithLabelOpts(opts.Label{Show: true}))
+	return heatMap
+}
+
+// weightsChart stacks the weights of long positions above zero. Short
+// positions are stacked separately below zero so that they do not
+// offset the long positions.
+func (t *Tearsheet) weightsChart(times []time.Time) *charts.Line {
+	holdings := t.results.Holdings[t.portfolioCode]
+	tickerSet := make(map[string]bool)
+	for _, point := range holdings {
+		for ticker := range point.Weights {
+			tickerSet[ticker] = true
+		}
+	}
+	var tickers []string
+	for ticker := range tickerSet {
+		tickers = append(tickers, ticker)
+	}
+	sort.Strings(tickers)
+
+	line := charts.NewLine()
+	line.SetGlobalOptions(t.initOpts("Weights (%)")...)
+	line.SetXAxis(dateLabels(times))
+	for _, ticker := range tickers {
+		long := make([]float64, len(times))
+		short := make([]float64, len(times))
+		hasShort := false
+		for i, point := range holdings {
+			if i >= len(times) {
+				break
+			}
+			weight := point.Weights[ticker] * 100
+			if weight < 0 {
+				short[i], hasShort = weight, true
+			} else {
+				long[i] = weight
+			}
+		}
+		line.AddSeries(ticker, lineData(long),
+			charts.WithLineChartOpts(opts.LineChart{Stack: "long"}),
+			charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: 0.6}))
+		if hasShort {
+			line.AddSeries(ticker+" (short)", lineData(short),
+				charts.WithLineChartOpts(opts.LineChart{Stack: "short"}),
+				charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: 0.6}))
+		}
+	}
+	return line
+}
+
+func (t *Tearsheet) turnoverChart(equity backtest.ResultSeries) *charts.Bar {
+	var data []opts.BarData
+	for _, value := range turnover(equity, t.results.Trades, t.portfolioCode) {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			data = append(data, opts.BarData{Value: "-"})
+		} else {
+			data = append(data, opts.BarData{Value: math.Round(value*10000) / 100})
+		}
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(t.initOpts("Turnover (% of value)")...)
+	bar.SetXAxis(dateLabels(equity.Times())).AddSeries(t.portfolioCode, data)
+	return bar
+}
+
+// tearsheetTables holds the tables shown below the charts.
+type tearsheetTables struct {
+	Summary  [][]string
+	Trades   []backtest.TradeResult
+	Breaches []backtest.TradeResult
+}
+
+func formatPercent(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", value*100)
+}
+
+func formatRatio(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+func (t *Tearsheet) tables(values []float64, benchmark []float64) tearsheetTables {
+	periodsPerYear := t.GetPeriodsPerYear()
+	statistics := func(v []float64) []string {
+		return []string{
+			formatPercent(analytics.TotalReturn(v)),
+			formatPercent(analytics.AnnualisedReturn(v, periodsPerYear)),
+			formatPercent(analytics.Volatility(v, periodsPerYear)),
+			formatRatio(analytics.Sharpe(v, periodsPerYear, t.riskFreeRate)),
+			formatPercent(analytics.MaxDrawdown(v)),
+		}
+	}
+
+	headers := []string{"", "Total return", "Annualised return", "Volatility", "Sharpe ratio", "Max drawdown"}
+	summary := [][]string{headers, append([]string{t.portfolioCode}, statistics(values)...)}
+	if benchmark != nil {
+		summary = append(summary, append([]string{t.benchmarkName}, statistics(benchmark)...))
+	}
+
+	tables := tearsheetTables{}
+	tables.Summary = summary
+	for _, trade := range t.results.Trades {
+		if trade.Portfolio == t.portfolioCode {
+			tables.Trades = append(tables.Trades, trade)
+		}
+	}
+	for _, breach := range t.results.ComplianceBreaches {
+		if breach.Portfolio == t.portfolioCode {
+			tables.Breaches = append(tables.Breaches, breach)
+		}
+	}
+	return tables
+}
+
+var tablesTemplate = template.Must(template.New("tables").Funcs(template.FuncMap{
+	"date":   func(t time.Time) string { return t.Format("2006-01-02 15:04") },
+	"number": func(v float64) string { return formatRatio(v) },
+}).Parse(`
+<style>
+  .tables { width: 1100px; margin: 20px auto; font-family: sans-serif; font-size: 13px; }
+  .tables table { border-collapse: collapse; width: 100%; margin-bottom: 24px; }
+  .tables th, .tables td { border-bottom: 1px solid #ddd; padding: 4px 8px; text-align: right; }
+  .tables th:first-child, .tables td:first-child { text-align: left; }
+</style>
+<div class="tables">
+  <h3>Summary</h3>
+  <table>
+  {{- range $i, $row := .Summary }}
+    <tr>{{ range $row }}{{ if eq $i 0 }}<th>{{ . }}</th>{{ else }}<td>{{ . }}</td>{{ end }}{{ end }}</tr>
+  {{- end }}
+  </table>
+  <h3>Trades</h3>
+  <table>
+    <tr><th>Time</th><th>Strategy</th><th>Ticker</th><th>Units</th><th>Price</th><th>Charges</th></tr>
+  {{- range .Trades }}
+    <tr><td>{{ date .Time }}</td><td>{{ .Strategy }}</td><td>{{ .Ticker }}</td><td>{{ number .Units }}</td><td>{{ number .Price }}</td><td>{{ number .Charges }}</td></tr>
+  {{- end }}
+  </table>
+  {{- if .Breaches }}
+  <h3>Compliance breaches</h3>
+  <table>
+    <tr><th>Time</th><th>Strategy</th><th>Ticker</th><th>Units</th><th>Price</th></tr>
+  {{- range .Breaches }}
+    <tr><td>{{ date .Time }}</td><td>{{ .Strategy }}</td><td>{{ .Ticker }}</td><td>{{ number .Units }}</td><td>{{ number .Price }}</td></tr>
+  {{- end }}
+  </table>
+  {{- end }}
+</div>
+`))
@@ -0,0 +1,241 @@
+package report
+
+import (
+	"bytes"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/calendar"
+	"gobacktrader/events"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testResults returns results for a portfolio holding a
+// stock over ten weeks, with a trade and a breach.
+func testResults() *backtest.Results {
+	results := &backtest.Results{
+		Equity:   map[string]backtest.ResultSeries{},
+		Prices:   map[string]backtest.ResultSeries{},
+		Holdings: map[string][]backtest.HoldingsPoint{},
+	}
+	for i := 0; i < 10; i++ {
+		date := btutil.Date(2021, 1, 4).AddDate(0, 0, 7*i)
+		price := 10 + float64(i%3)
+		results.Prices["ZZB AU"] = append(results.Prices["ZZB AU"], backtest.ResultPoint{Time: date, Value: price})
+		results.Equity["XXX"] = append(results.Equity["XXX"], backtest.ResultPoint{Time: date, Value: 1000 + 100*price})
+		results.Holdings["XXX"] = append(results.Holdings["XXX"], backtest.HoldingsPoint{
+			Time:    date,
+			Units:   map[string]float64{"ZZB AU": 100, "AUD": 1000},
+			Weights: map[string]float64{"ZZB AU": 100 * price / (1000 + 100*price), "AUD": 1000 / (1000 + 100*price)},
+		})
+	}
+	results.Trades = []backtest.TradeResult{
+		{Time: btutil.Date(2021, 1, 4), Strategy: "default", Portfolio: "XXX", Ticker: "ZZB AU", Units: 100, Price: 10, Charges: 5, Executed: true},
+	}
+	results.ComplianceBreaches = []backtest.TradeResult{
+		{Time: btutil.Date(2021, 1, 11), Strategy: "default", Portfolio: "XXX", Ticker: "<ZZB>", Units: 1000, Price: 11, Reason: backtest.RejectCompliance},
+	}
+	return results
+}
+
+func TestNewTearsheet(t *testing.T) {
+	bt := backtest.NewBacktest(nil)
+	if _, err := NewTearsheet(&bt, "XXX"); err == nil {
+		t.Errorf("Expected error for a backtest that has not run")
+	}
+	if _, err := NewTearsheetFromResults(testResults(), "YYY"); err == nil {
+		t.Errorf("Expected error for an unknown portfolio")
+	}
+
+	tearsheet, err := NewTearsheetFromResults(testResults(), "XXX")
+	if err != nil {
+		t.Fatalf("Error in NewTearsheetFromResults - %s", err)
+	}
+	if tearsheet.GetTitle() != "XXX" {
+		t.Errorf("Unexpected default title - %s", tearsheet.GetTitle())
+	}
+	if btutil.Round4dp(tearsheet.GetPeriodsPerYear()) != btutil.Round4dp(365.25/7) || tearsheet.GetRollingWindow() != 13 {
+		t.Errorf("Unexpected defaults - %f periods, %d window", tearsheet.GetPeriodsPerYear(), tearsheet.GetRollingWindow())
+	}
+	if tearsheet.GetCalendar() != nil {
+		t.Error("Expecting no calendar by default")
+	}
+
+	// nine weeks of weekly snapshots span 45 of the 261 weekdays in 2021
+	tearsheet.SetCalendar(calendar.NewWeekdayCalendar())
+	if btutil.Round4dp(tearsheet.GetPeriodsPerYear()) != 52.2 {
+		t.Errorf("Unexpected calendar periods per year - %f", tearsheet.GetPeriodsPerYear())
+	}
+	tearsheet.SetCalendar(nil)
+
+	if err := tearsheet.SetPeriodsPerYear(0); err == nil {
+		t.Errorf("Expected error for zero periods per year")
+	}
+	if err := tearsheet.SetRollingWindow(1); err == nil {
+		t.Errorf("Expected error for a rolling window of one")
+	}
+	if err := tearsheet.SetBenchmarkTicker("ZZC AU"); err == nil {
+		t.Errorf("Expected error for an unknown benchmark ticker")
+	}
+	if err := tearsheet.SetBenchmark("Empty", nil); err == nil {
+		t.Errorf("Expected error for an empty benchmark")
+	}
+}
+
+func TestNewTearsheetCalendar(t *testing.T) {
+	bt := backtest.NewBacktest(nil)
+	portfolio, err1 := bt.NewPortfolio("XXX", "AUD")
+	stock1, err2 := asset.NewStock("ZZB AU", "AUD")
+	stock2, err3 := asset.NewStock("ZZC AU", "AUD")
+	cash, err4 := bt.GetCash("AUD")
+	cal, err5 := calendar.NewCalendar("ASX", time.UTC)
+	if err := btutil.AnyValidError(err1, err2, err3, err4, err5); err != nil {
+		t.Fatalf("Error in setup - %s", err)
+	}
+	portfolio.Transfer(cash, 1000)
+	stock2.SetCalendar(cal)
+	bt.RegisterAsset(stock1)
+	bt.RegisterAsset(stock2)
+	for i := 0; i < 5; i++ {
+		date := btutil.Date(2021, 3, 1).AddDate(0, 0, i)
+		event1 := events.NewAssetPriceEvent(stock1, date, asset.Price{Float64: 10, Valid: true})
+		event2 := events.NewAssetPriceEvent(stock2, date, asset.Price{Float64: 20, Valid: true})
+		bt.AddEvent(&event1)
+		bt.AddEvent(&event2)
+	}
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+
+	// the first asset only has the default calendar
+	tearsheet, err := NewTearsheet(&bt, "XXX")
+	if err != nil {
+		t.Fatalf("Error in NewTearsheet - %s", err)
+	}
+	if tearsheet.GetCalendar() != cal {
+		t.Error("Expecting the calendar set on the second asset")
+	}
+}
+
+func TestTearsheetRender(t *testing.T) {
+	tearsheet, _ := NewTearsheetFromResults(testResults(), "XXX")
+	tearsheet.SetTitle("Test tearsheet")
+	tearsheet.SetRollingWindow(4)
+	tearsheet.SetUseCDN(true)
+	if err := tearsheet.SetBenchmarkTicker("ZZB AU"); err != nil {
+		t.Fatalf("Error in SetBenchmarkTicker - %s", err)
+	}
+
+	var buffer bytes.Buffer
+	if err := tearsheet.Render(&buffer); err != nil {
+		t.Fatalf("Error in Render - %s", err)
+	}
+	html := buffer.String()
+	for _, expected := range []string{
+		"<title>Test tearsheet</title>",
+		DefaultAssetsHost + "echarts.min.js",
+		"Equity (rebased to 100)",
+		"Drawdown (%)",
+		"Rolling volatility (%), 4 periods",
+		"Rolling Sharpe ratio, 4 periods",
+		"Monthly returns (%)",
+		"Weights (%)",
+		"Turnover (% of value)",
+		"<h3>Trades</h3>",
+		"<h3>Compliance breaches</h3>",
+		"&lt;ZZB&gt;",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected tearsheet to contain '%s'", expected)
+		}
+	}
+	if strings.Index(html, "<h3>Summary</h3>") > strings.Index(html, "</body>") {
+		t.Errorf("Expected tables within the page body")
+	}
+}
+
+func TestTearsheetInlineScript(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tearsheet")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+	scriptPath := filepath.Join(dir, "echarts.min.js")
+	ioutil.WriteFile(scriptPath, []byte("var echarts = {};"), 0644)
+
+	tearsheet, _ := NewTearsheetFromResults(testResults(), "XXX")
+	tearsheet.SetAssetsHost("https://mirror.example.com/assets")
+	tearsheet.SetEChartsScript(scriptPath)
+	filePath := filepath.Join(dir, "tearsheet.html")
+	if err := tearsheet.Save(filePath); err != nil {
+		t.Fatalf("Error in Save - %s", err)
+	}
+	data, _ := ioutil.ReadFile(filePath)
+	html := string(data)
+	if !strings.Contains(html, "<script>var echarts = {};</script>") || strings.Contains(html, "mirror.example.com") {
+		t.Errorf("Expected echarts script to be inlined")
+	}
+
+	tearsheet.SetEChartsScript(filepath.Join(dir, "missing.js"))
+	if err := tearsheet.Save(filePath); err == nil {
+		t.Errorf("Expected error for a missing script")
+	}
+
+	results := testResults()
+	results.Equity["XXX"] = results.Equity["XXX"][:1]
+	short, _ := NewTearsheetFromResults(results, "XXX")
+	if err := short.Render(&bytes.Buffer{}); err == nil {
+		t.Errorf("Expected error for a single snapshot")
+	}
+}
+
+func TestTearsheetShortWeights(t *testing.T) {
+	results := testResults()
+	results.Holdings["XXX"][3].Weights["ZZB AU"] = -0.2
+	tearsheet, _ := NewTearsheetFromResults(results, "XXX")
+	var buffer bytes.Buffer
+	if err := tearsheet.Render(&buffer); err != nil {
+		t.Fatalf("Error in Render - %s", err)
+	}
+	html := buffer.String()
+	if !strings.Contains(html, `"ZZB AU (short)"`) || !strings.Contains(html, `"stack":"short"`) {
+		t.Errorf("Expected short weights to be stacked separately")
+	}
+}
+
+func TestTearsheetOffline(t *testing.T) {
+	tearsheet, _ := NewTearsheetFromResults(testResults(), "XXX")
+	if !tearsheet.GetUseCDN() {
+		t.Error("Expecting the cdn by default")
+	}
+
+	// an offline page needs a local script
+	tearsheet.SetUseCDN(false)
+	err := tearsheet.Render(&bytes.Buffer{})
+	errStr := btutil.GetErrorString(err)
+	if errStr != "an offline tearsheet needs a local echarts script set with SetEChartsScript" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	dir, err := ioutil.TempDir("", "tearsheet")
+	if err != nil {
+		t.Fatalf("Error in TempDir - %s", err)
+	}
+	defer os.RemoveAll(dir)
+	scriptPath := filepath.Join(dir, "echarts.min.js")
+	ioutil.WriteFile(scriptPath, []byte("var local = {};"), 0644)
+	tearsheet.SetEChartsScript(scriptPath)
+	var buffer bytes.Buffer
+	if err := tearsheet.Render(&buffer); err != nil {
+		t.Fatalf("Error in Render - %s", err)
+	}
+	html := buffer.String()
+	if !strings.Contains(html, "<script>var local = {};</script>") || strings.Contains(html, DefaultAssetsHost+"echarts.min.js") {
+		t.Errorf("Expected the local script to be inlined")
+	}
+}
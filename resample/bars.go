@@ -0,0 +1,50 @@
+package resample
+
+import (
+	"gobacktrader/datasources"
+	"math"
+	"sort"
+	"time"
+)
+
+// ResampleBars aggregates bars into periods. Each resampled bar takes the
+// first open, the highest high, the lowest low and the last close in its
+// period, along with the total volume. The resampled bar is stamped with
+// the time of the last bar in the period.
+func ResampleBars(bars []datasources.Bar, freq Frequency) []datasources.Bar {
+	sorted := make([]datasources.Bar, len(bars))
+	copy(sorted, bars)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	times := make([]time.Time, len(sorted))
+	for i, bar := range sorted {
+		times[i] = bar.Time
+	}
+
+	var resampled []datasources.Bar
+	start := 0
+	for _, end := range periods(times, freq) {
+		resampled = append(resampled, aggregate(sorted[start:end+1]))
+		start = end + 1
+	}
+	return resampled
+}
+
+func aggregate(bars []datasources.Bar) datasources.Bar {
+	first, last := bars[0], bars[len(bars)-1]
+	bar := datasources.Bar{
+		Time:  last.Time,
+		Open:  first.Open,
+		High:  first.High,
+		Low:   first.Low,
+		Close: last.Close,
+	}
+	for _, b := range bars {
+		bar.High = math.Max(bar.High, b.High)
+		bar.Low = math.Min(bar.Low, b.Low)
+		bar.Volume += b.Volume
+	}
+	return bar
+}
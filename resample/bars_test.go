@@ -0,0 +1,41 @@
+package resample
+
+import (
+	"gobacktrader/btutil"
+	"gobacktrader/datasources"
+	"testing"
+)
+
+func TestResampleBars(t *testing.T) {
+	bars := []datasources.Bar{
+		{Time: btutil.Date(2021, 3, 8), Open: 10, High: 11, Low: 9.5, Close: 10.5, Volume: 100},
+		{Time: btutil.Date(2021, 3, 9), Open: 10.5, High: 12, Low: 10, Close: 11.5, Volume: 200},
+		{Time: btutil.Date(2021, 3, 12), Open: 11.5, High: 11.8, Low: 9, Close: 9.5, Volume: 300},
+		{Time: btutil.Date(2021, 3, 15), Open: 9.5, High: 10, Low: 9.2, Close: 9.8, Volume: 400},
+	}
+
+	weekly := ResampleBars(bars, NewWeekly())
+	if len(weekly) != 2 {
+		t.Fatalf("Expecting 2 weekly bars, got %d", len(weekly))
+	}
+
+	week1 := weekly[0]
+	if !week1.Time.Equal(btutil.Date(2021, 3, 12)) {
+		t.Error("Expecting the first week to be stamped with its last bar")
+	}
+	if week1.Open != 10 || week1.High != 12 || week1.Low != 9 || week1.Close != 9.5 {
+		t.Errorf("Unexpected OHLC for the first week - %v", week1)
+	}
+	if week1.Volume != 600 {
+		t.Errorf("Unexpected volume for the first week - wanted 600, got %0.2f", week1.Volume)
+	}
+
+	week2 := weekly[1]
+	if week2 != bars[3] {
+		t.Errorf("Expecting a single bar week to be unchanged - %v", week2)
+	}
+
+	if len(ResampleBars(nil, NewWeekly())) != 0 {
+		t.Error("Expecting no bars for an empty series")
+	}
+}
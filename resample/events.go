@@ -0,0 +1,29 @@
+package resample
+
+import (
+	"gobacktrader/events"
+	"sort"
+	"time"
+)
+
+// ResampleEvents takes the events for a single ticker and returns the
+// last event in each period, so the close for a period is the last value
+// observed within it. The input slice is not modified.
+func ResampleEvents(eventList []events.IEvent, freq Frequency) []events.IEvent {
+	sorted := make([]events.IEvent, len(eventList))
+	copy(sorted, eventList)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetTime().Before(sorted[j].GetTime())
+	})
+
+	times := make([]time.Time, len(sorted))
+	for i, event := range sorted {
+		times[i] = event.GetTime()
+	}
+
+	var resampled []events.IEvent
+	for _, index := range periods(times, freq) {
+		resampled = append(resampled, sorted[index])
+	}
+	return resampled
+}
@@ -0,0 +1,59 @@
+package resample
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"testing"
+)
+
+func TestResampleEvents(t *testing.T) {
+	stock, err := asset.NewStock("ZZB AU", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+
+	newEvent := func(year, month, day int, price float64) events.IEvent {
+		event := events.NewAssetPriceEvent(stock, btutil.Date(year, month, day), asset.Price{Float64: price, Valid: true})
+		return &event
+	}
+
+	// events are deliberately out of order
+	e1 := newEvent(2021, 2, 26, 2.2)
+	e2 := newEvent(2021, 1, 28, 1.9)
+	e3 := newEvent(2021, 1, 29, 2.0)
+	e4 := newEvent(2021, 3, 1, 2.3)
+	e5 := newEvent(2021, 2, 1, 2.1)
+	eventList := []events.IEvent{e1, e2, e3, e4, e5}
+
+	monthly := ResampleEvents(eventList, NewMonthEnd())
+	expected := []events.IEvent{e3, e1, e4}
+	if len(monthly) != len(expected) {
+		t.Fatalf("Unexpected number of events - wanted %d, got %d", len(expected), len(monthly))
+	}
+	for i := range expected {
+		if monthly[i] != expected[i] {
+			t.Errorf("Unexpected event at index %d", i)
+		}
+	}
+
+	// the input should be left untouched
+	if eventList[0] != e1 {
+		t.Error("Expecting the input events to keep their order")
+	}
+
+	twoBars, err := NewNBars(2)
+	if err != nil {
+		t.Fatalf("Error in NewNBars - %s", err)
+	}
+	resampled := ResampleEvents(eventList, twoBars)
+	expected = []events.IEvent{e3, e1, e4}
+	if len(resampled) != len(expected) {
+		t.Fatalf("Unexpected number of events - wanted %d, got %d", len(expected), len(resampled))
+	}
+	for i := range expected {
+		if resampled[i] != expected[i] {
+			t.Errorf("Unexpected event at index %d", i)
+		}
+	}
+}
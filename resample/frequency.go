@@ -0,0 +1,120 @@
+// Package resample converts price series to coarser frequencies.
+package resample
+
+import (
+	"errors"
+	"time"
+)
+
+// Frequency determines where one resampling period ends and the next begins.
+type Frequency interface {
+	// IsNewPeriod returns true if an observation at time next starts a new
+	// period, given the time of the previous observation and the number
+	// of observations already collected in the current period.
+	IsNewPeriod(previous time.Time, next time.Time, count int) bool
+}
+
+// Daily starts a new period on each calendar day.
+type Daily struct{}
+
+// NewDaily returns a new instance of Daily.
+func NewDaily() Daily {
+	return Daily{}
+}
+
+// IsNewPeriod returns true if next falls on a different day to previous.
+func (f Daily) IsNewPeriod(previous time.Time, next time.Time, count int) bool {
+	y1, m1, d1 := previous.Date()
+	y2, m2, d2 := next.Date()
+	return y1 != y2 || m1 != m2 || d1 != d2
+}
+
+// Weekly starts a new period with each ISO week.
+type Weekly struct{}
+
+// NewWeekly returns a new instance of Weekly.
+func NewWeekly() Weekly {
+	return Weekly{}
+}
+
+// IsNewPeriod returns true if next falls in a different week to previous.
+func (f Weekly) IsNewPeriod(previous time.Time, next time.Time, count int) bool {
+	y1, w1 := previous.ISOWeek()
+	y2, w2 := next.ISOWeek()
+	return y1 != y2 || w1 != w2
+}
+
+// MonthEnd starts a new period with each calendar month.
+type MonthEnd struct{}
+
+// NewMonthEnd returns a new instance of MonthEnd.
+func NewMonthEnd() MonthEnd {
+	return MonthEnd{}
+}
+
+// IsNewPeriod returns true if next falls in a different month to previous.
+func (f MonthEnd) IsNewPeriod(previous time.Time, next time.Time, count int) bool {
+	return previous.Year() != next.Year() || previous.Month() != next.Month()
+}
+
+// QuarterEnd starts a new period with each calendar quarter.
+type QuarterEnd struct{}
+
+// NewQuarterEnd returns a new instance of QuarterEnd.
+func NewQuarterEnd() QuarterEnd {
+	return QuarterEnd{}
+}
+
+// IsNewPeriod returns true if next falls in a different quarter to previous.
+func (f QuarterEnd) IsNewPeriod(previous time.Time, next time.Time, count int) bool {
+	q1 := (previous.Month() - 1) / 3
+	q2 := (next.Month() - 1) / 3
+	return previous.Year() != next.Year() || q1 != q2
+}
+
+// NBars starts a new period after every n observations
+// regardless of the time between them. The zero value
+// starts a new period with every observation, as for n = 1.
+type NBars struct {
+	n int
+}
+
+// NewNBars returns a new instance of NBars.
+func NewNBars(n int) (NBars, error) {
+	if n < 1 {
+		return NBars{}, errors.New("the number of bars per period must be at least one")
+	}
+	return NBars{n: n}, nil
+}
+
+// GetN returns the number of observations per period,
+// which is one for the zero value.
+func (f NBars) GetN() int {
+	if f.n < 1 {
+		return 1
+	}
+	return f.n
+}
+
+// IsNewPeriod returns true once n observations have been collected.
+func (f NBars) IsNewPeriod(previous time.Time, next time.Time, count int) bool {
+	return count >= f.GetN()
+}
+
+// periods splits a sorted slice of times into periods and
+// returns the index of the last observation in each.
+func periods(times []time.Time, freq Frequency) []int {
+	var lastIndexes []int
+	count := 0
+	for i, t := range times {
+		if i > 0 && freq.IsNewPeriod(times[i-1], t, count) {
+			lastIndexes = append(lastIndexes, i-1)
+			count = 0
+		}
+		count++
+	}
+	if len(times) > 0 {
+		lastIndexes = append(lastIndexes, len(times)-1)
+	}
+	return lastIndexes
+}
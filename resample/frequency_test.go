@@ -0,0 +1,116 @@
+package resample
+
+import (
+	"gobacktrader/btutil"
+	"testing"
+	"time"
+)
+
+func TestDaily(t *testing.T) {
+	freq := NewDaily()
+	t1 := time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, time.March, 1, 16, 0, 0, 0, time.UTC)
+	t3 := time.Date(2021, time.March, 2, 10, 0, 0, 0, time.UTC)
+	if freq.IsNewPeriod(t1, t2, 1) {
+		t.Error("Expecting t1 and t2 to fall on the same day")
+	}
+	if !freq.IsNewPeriod(t2, t3, 2) {
+		t.Error("Expecting t3 to start a new day")
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	freq := NewWeekly()
+	friday := btutil.Date(2021, 3, 5)
+	monday := btutil.Date(2021, 3, 8)
+	tuesday := btutil.Date(2021, 3, 9)
+	if !freq.IsNewPeriod(friday, monday, 5) {
+		t.Error("Expecting Monday to start a new week")
+	}
+	if freq.IsNewPeriod(monday, tuesday, 1) {
+		t.Error("Expecting Monday and Tuesday to fall in the same week")
+	}
+
+	// ISO weeks span the year end
+	thursday := btutil.Date(2020, 12, 31)
+	nextFriday := btutil.Date(2021, 1, 1)
+	if freq.IsNewPeriod(thursday, nextFriday, 4) {
+		t.Error("Expecting the year end to fall in the same ISO week")
+	}
+}
+
+func TestMonthEnd(t *testing.T) {
+	freq := NewMonthEnd()
+	if freq.IsNewPeriod(btutil.Date(2021, 3, 1), btutil.Date(2021, 3, 31), 1) {
+		t.Error("Expecting both dates to fall in March")
+	}
+	if !freq.IsNewPeriod(btutil.Date(2021, 3, 31), btutil.Date(2021, 4, 1), 22) {
+		t.Error("Expecting April to start a new month")
+	}
+	if !freq.IsNewPeriod(btutil.Date(2020, 3, 31), btutil.Date(2021, 3, 31), 1) {
+		t.Error("Expecting a different year to start a new month")
+	}
+}
+
+func TestQuarterEnd(t *testing.T) {
+	freq := NewQuarterEnd()
+	if freq.IsNewPeriod(btutil.Date(2021, 1, 4), btutil.Date(2021, 3, 31), 1) {
+		t.Error("Expecting both dates to fall in the first quarter")
+	}
+	if !freq.IsNewPeriod(btutil.Date(2021, 3, 31), btutil.Date(2021, 4, 1), 60) {
+		t.Error("Expecting April to start a new quarter")
+	}
+}
+
+func TestNBars(t *testing.T) {
+	_, err := NewNBars(0)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "the number of bars per period must be at least one" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	freq, err := NewNBars(3)
+	if err != nil {
+		t.Fatalf("Error in NewNBars - %s", err)
+	}
+	if freq.GetN() != 3 {
+		t.Error("Unexpected number of bars")
+	}
+
+	t1 := btutil.Date(2021, 3, 1)
+	if freq.IsNewPeriod(t1, t1, 2) {
+		t.Error("Expecting two bars to remain in the same period")
+	}
+	if !freq.IsNewPeriod(t1, t1, 3) {
+		t.Error("Expecting a new period after three bars")
+	}
+
+	// the zero value behaves as one bar per period
+	zero := NBars{}
+	if zero.GetN() != 1 || zero.IsNewPeriod(t1, t1, 0) || !zero.IsNewPeriod(t1, t1, 1) {
+		t.Error("Unexpected zero value NBars")
+	}
+}
+
+func TestPeriods(t *testing.T) {
+	times := []time.Time{
+		btutil.Date(2021, 1, 29),
+		btutil.Date(2021, 2, 1),
+		btutil.Date(2021, 2, 26),
+		btutil.Date(2021, 3, 1),
+	}
+	lastIndexes := periods(times, NewMonthEnd())
+	expected := []int{0, 2, 3}
+	if len(lastIndexes) != len(expected) {
+		t.Fatalf("Unexpected number of periods - wanted %d, got %d", len(expected), len(lastIndexes))
+	}
+	for i := range expected {
+		if lastIndexes[i] != expected[i] {
+			t.Errorf("Unexpected period end - wanted %d, got %d", expected[i], lastIndexes[i])
+		}
+	}
+
+	if len(periods(nil, NewMonthEnd())) != 0 {
+		t.Error("Expecting no periods for an empty series")
+	}
+}
@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"errors"
+	"gobacktrader/calendar"
+	"time"
+)
+
+// IRule defines the interface for schedule rules.
+// Dates returns the days on which a rule fires between
+// the dates of start and end inclusive.
+type IRule interface {
+	Dates(cal calendar.ITradingCalendar, start time.Time, end time.Time) []time.Time
+}
+
+// EveryNTradingDays fires on every nth trading day, starting
+// with the first trading day in the schedule. The zero value
+// fires on every trading day, as for n = 1.
+type EveryNTradingDays struct {
+	n int
+}
+
+// NewEveryNTradingDays returns a new instance of EveryNTradingDays.
+func NewEveryNTradingDays(n int) (EveryNTradingDays, error) {
+	if n < 1 {
+		return EveryNTradingDays{}, errors.New("the number of trading days must be at least one")
+	}
+	return EveryNTradingDays{n: n}, nil
+}
+
+// NewEveryTradingDay returns a rule that fires on every trading day.
+func NewEveryTradingDay() EveryNTradingDays {
+	return EveryNTradingDays{n: 1}
+}
+
+// GetN returns the number of trading days between each date,
+// which is one for the zero value.
+func (r EveryNTradingDays) GetN() int {
+	if r.n < 1 {
+		return 1
+	}
+	return r.n
+}
+
+// Dates returns every nth trading day.
+func (r EveryNTradingDays) Dates(cal calendar.ITradingCalendar, start time.Time, end time.Time) []time.Time {
+	var dates []time.Time
+	n := r.GetN()
+	for i, day := range calendar.TradingDays(cal, start, end) {
+		if i%n == 0 {
+			dates = append(dates, day)
+		}
+	}
+	return dates
+}
+
+// Weekly fires once a week on some weekday. Where that weekday is
+// not a trading day the rule fires on the next trading day that week.
+type Weekly struct {
+	weekday time.Weekday
+}
+
+// NewWeekly returns a new instance of Weekly.
+func NewWeekly(weekday time.Weekday) Weekly {
+	return Weekly{weekday: weekday}
+}
+
+// GetWeekday returns the weekday on which this rule fires.
+func (r Weekly) GetWeekday() time.Weekday {
+	return r.weekday
+}
+
+// isoWeekday numbers the days of the week from Monday = 1 to Sunday = 7.
+func isoWeekday(weekday time.Weekday) int {
+	if weekday == time.Sunday {
+		return 7
+	}
+	return int(weekday)
+}
+
+// Dates returns the first trading day on or after the weekday in each week.
+func (r Weekly) Dates(cal calendar.ITradingCalendar, start time.Time, end time.Time) []time.Time {
+	var dates []time.Time
+	target := isoWeekday(r.weekday)
+	lastYear, lastWeek := 0, 0
+	for _, day := range calendar.TradingDays(cal, start, end) {
+		year, week := day.ISOWeek()
+		if year == lastYear && week == lastWeek {
+			continue // we've already fired this week
+		}
+		if isoWeekday(day.Weekday()) >= target {
+			dates = append(dates, day)
+			lastYear, lastWeek = year, week
+		}
+	}
+	return dates
+}
+
+// Periodic fires on either the first or last trading day of each
+// month or quarter. The zero value fires on the last trading day
+// of each month.
+type Periodic struct {
+	months int
+	first  bool
+}
+
+// NewFirstTradingDayOfMonth returns a rule that fires on the first trading day of each month.
+func NewFirstTradingDayOfMonth() Periodic {
+	return Periodic{months: 1, first: true}
+}
+
+// NewLastTradingDayOfMonth returns a rule that fires on the last trading day of each month.
+func NewLastTradingDayOfMonth() Periodic {
+	return Periodic{months: 1, first: false}
+}
+
+// NewFirstTradingDayOfQuarter returns a rule that fires on the first trading day of each quarter.
+func NewFirstTradingDayOfQuarter() Periodic {
+	return Periodic{months: 3, first: true}
+}
+
+// NewLastTradingDayOfQuarter returns a rule that fires on the last trading day of each quarter.
+func NewLastTradingDayOfQuarter() Periodic {
+	return Periodic{months: 3, first: false}
+}
+
+// periodMonths returns the number of months in each period,
+// which is one for the zero value.
+func (r Periodic) periodMonths() int {
+	if r.months < 1 {
+		return 1
+	}
+	return r.months
+}
+
+// periodStart returns the first day of the period containing t.
+func (r Periodic) periodStart(t time.Time) time.Time {
+	months := r.periodMonths()
+	month := time.Month((int(t.Month())-1)/months*months + 1)
+	return time.Date(t.Year(), month, 1, 0, 0, 0, 0, t.Location())
+}
+
+// Dates returns the first or last trading day in each period.
+// Whole periods are considered so that, for example, a schedule
+// ending mid month does not treat its end date as month end.
+func (r Periodic) Dates(cal calendar.ITradingCalendar, start time.Time, end time.Time) []time.Time {
+	from := r.periodStart(start)
+	to := r.periodStart(end).AddDate(0, r.periodMonths(), -1)
+	days := calendar.TradingDays(cal, from, to)
+
+	var dates []time.Time
+	for i, day := range days {
+		isFirst := i == 0 || !r.periodStart(days[i-1]).Equal(r.periodStart(day))
+		isLast := i == len(days)-1 || !r.periodStart(days[i+1]).Equal(r.periodStart(day))
+		if (r.first && isFirst) || (!r.first && isLast) {
+			if !day.Before(calendar.Day(start)) && !day.After(end) {
+				dates = append(dates, day)
+			}
+		}
+	}
+	return dates
+}
@@ -0,0 +1,128 @@
+package schedule
+
+import (
+	"gobacktrader/btutil"
+	"gobacktrader/calendar"
+	"testing"
+	"time"
+)
+
+// holidayCalendar is a weekday calendar with some extra holidays.
+type holidayCalendar struct {
+	holidays []time.Time
+}
+
+func (c holidayCalendar) IsTradingDay(t time.Time) bool {
+	for _, holiday := range c.holidays {
+		if holiday.Equal(t) {
+			return false
+		}
+	}
+	return calendar.NewWeekdayCalendar().IsTradingDay(t)
+}
+
+func checkDates(t *testing.T, dates []time.Time, expected []time.Time) {
+	t.Helper()
+	if len(dates) != len(expected) {
+		t.Fatalf("Expecting %d dates, got %d - %v", len(expected), len(dates), dates)
+	}
+	for i := range expected {
+		if !dates[i].Equal(expected[i]) {
+			t.Errorf("Unexpected date - wanted %s, got %s", expected[i], dates[i])
+		}
+	}
+}
+
+func TestEveryNTradingDays(t *testing.T) {
+	_, err := NewEveryNTradingDays(0)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "the number of trading days must be at least one" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	rule, err := NewEveryNTradingDays(3)
+	if err != nil {
+		t.Fatalf("Error in NewEveryNTradingDays - %s", err)
+	}
+	if rule.GetN() != 3 {
+		t.Error("Unexpected number of trading days")
+	}
+
+	cal := calendar.NewWeekdayCalendar()
+	dates := rule.Dates(cal, btutil.Date(2021, 3, 1), btutil.Date(2021, 3, 12))
+	checkDates(t, dates, []time.Time{
+		btutil.Date(2021, 3, 1),
+		btutil.Date(2021, 3, 4),
+		btutil.Date(2021, 3, 9),
+		btutil.Date(2021, 3, 12),
+	})
+
+	daily := NewEveryTradingDay()
+	dates = daily.Dates(cal, btutil.Date(2021, 3, 5), btutil.Date(2021, 3, 8))
+	checkDates(t, dates, []time.Time{btutil.Date(2021, 3, 5), btutil.Date(2021, 3, 8)})
+
+	// the zero value fires on every trading day
+	zero := EveryNTradingDays{}
+	if zero.GetN() != 1 {
+		t.Errorf("Unexpected zero value n - %d", zero.GetN())
+	}
+	dates = zero.Dates(cal, btutil.Date(2021, 3, 5), btutil.Date(2021, 3, 8))
+	checkDates(t, dates, []time.Time{btutil.Date(2021, 3, 5), btutil.Date(2021, 3, 8)})
+}
+
+func TestWeekly(t *testing.T) {
+	rule := NewWeekly(time.Wednesday)
+	if rule.GetWeekday() != time.Wednesday {
+		t.Error("Unexpected weekday")
+	}
+
+	// Wednesday the 10th is a holiday, so fire on Thursday instead
+	cal := holidayCalendar{holidays: []time.Time{btutil.Date(2021, 3, 10)}}
+	dates := rule.Dates(cal, btutil.Date(2021, 3, 1), btutil.Date(2021, 3, 21))
+	checkDates(t, dates, []time.Time{
+		btutil.Date(2021, 3, 3),
+		btutil.Date(2021, 3, 11),
+		btutil.Date(2021, 3, 17),
+	})
+
+	// Sunday is never a trading day
+	sunday := NewWeekly(time.Sunday)
+	dates = sunday.Dates(cal, btutil.Date(2021, 3, 1), btutil.Date(2021, 3, 21))
+	if len(dates) != 0 {
+		t.Errorf("Expecting no dates, got %v", dates)
+	}
+}
+
+func TestPeriodic(t *testing.T) {
+	// the 1st of March 2021 is a holiday
+	cal := holidayCalendar{holidays: []time.Time{btutil.Date(2021, 3, 1)}}
+	start := btutil.Date(2021, 2, 10)
+	end := btutil.Date(2021, 4, 15)
+
+	dates := NewFirstTradingDayOfMonth().Dates(cal, start, end)
+	checkDates(t, dates, []time.Time{btutil.Date(2021, 3, 2), btutil.Date(2021, 4, 1)})
+
+	// April has not finished by the end date so has no month end
+	dates = NewLastTradingDayOfMonth().Dates(cal, start, end)
+	checkDates(t, dates, []time.Time{btutil.Date(2021, 2, 26), btutil.Date(2021, 3, 31)})
+
+	dates = NewFirstTradingDayOfQuarter().Dates(cal, btutil.Date(2021, 1, 1), btutil.Date(2021, 12, 31))
+	checkDates(t, dates, []time.Time{
+		btutil.Date(2021, 1, 1),
+		btutil.Date(2021, 4, 1),
+		btutil.Date(2021, 7, 1),
+		btutil.Date(2021, 10, 1),
+	})
+
+	dates = NewLastTradingDayOfQuarter().Dates(cal, btutil.Date(2021, 1, 1), btutil.Date(2021, 12, 31))
+	checkDates(t, dates, []time.Time{
+		btutil.Date(2021, 3, 31),
+		btutil.Date(2021, 6, 30),
+		btutil.Date(2021, 9, 30),
+		btutil.Date(2021, 12, 31),
+	})
+
+	// the zero value fires on the last trading day of each month
+	dates = Periodic{}.Dates(cal, start, end)
+	checkDates(t, dates, []time.Time{btutil.Date(2021, 2, 26), btutil.Date(2021, 3, 31)})
+}
@@ -0,0 +1,114 @@
+// Package schedule generates recurring callback events from
+// rules resolved against a trading calendar.
+package schedule
+
+import (
+	"errors"
+	"gobacktrader/calendar"
+	"gobacktrader/events"
+	"time"
+)
+
+// Entry pairs a rule with the callback to run when it fires.
+type Entry struct {
+	rule     IRule
+	callback events.Callback
+	times    []calendar.TimeOfDay
+	err      error
+}
+
+// At adds an intraday time at which the entry fires on each of its dates.
+// Entries with no times fire at the start of the day. An invalid time is
+// recorded against the entry and returned by GetError and GenerateEvents.
+func (e *Entry) At(hour int, minute int) *Entry {
+	t, err := calendar.NewTimeOfDay(hour, minute)
+	if err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		return e
+	}
+	e.times = append(e.times, t)
+	return e
+}
+
+// GetError returns the first error recorded against the entry, if any.
+func (e *Entry) GetError() error {
+	return e.err
+}
+
+// GetRule returns the entry rule.
+func (e *Entry) GetRule() IRule {
+	return e.rule
+}
+
+// Scheduler keeps a collection of scheduled entries.
+type Scheduler struct {
+	calendar calendar.ITradingCalendar
+	entries  []*Entry
+}
+
+// NewScheduler returns a new instance of Scheduler using some trading calendar.
+func NewScheduler(cal calendar.ITradingCalendar) *Scheduler {
+	return &Scheduler{calendar: cal}
+}
+
+// GetCalendar returns the scheduler's trading calendar.
+func (s *Scheduler) GetCalendar() calendar.ITradingCalendar {
+	return s.calendar
+}
+
+// Add schedules a callback to run whenever some rule fires.
+func (s *Scheduler) Add(rule IRule, callback events.Callback) *Entry {
+	entry := &Entry{rule: rule, callback: callback}
+	s.entries = append(s.entries, entry)
+	return entry
+}
+
+// NumEntries returns the number of scheduled entries.
+func (s *Scheduler) NumEntries() int {
+	return len(s.entries)
+}
+
+// iHasLocation is implemented by calendars with an exchange time zone.
+type iHasLocation interface {
+	GetLocation() *time.Location
+}
+
+// GenerateEvents returns callback events for all entries between
+// the dates of start and end inclusive. Intraday times are resolved
+// in the calendar's time zone where it has one and in the location
+// of start otherwise.
+func (s *Scheduler) GenerateEvents(start time.Time, end time.Time) ([]events.IEvent, error) {
+	var scheduledEvents []events.IEvent
+	if end.Before(start) {
+		return scheduledEvents, errors.New("the schedule end cannot be before its start")
+	}
+
+	location := start.Location()
+	if hasLocation, ok := s.calendar.(iHasLocation); ok {
+		location = hasLocation.GetLocation()
+	}
+
+	end = end.In(start.Location())
+	for _, entry := range s.entries {
+		if entry.err != nil {
+			return scheduledEvents, entry.err
+		}
+		times := entry.times
+		if len(times) == 0 {
+			times = []calendar.TimeOfDay{{Hour: 0, Minute: 0}}
+		}
+
+		for _, day := range entry.rule.Dates(s.calendar, start, end) {
+			for _, t := range times {
+				year, month, date := day.Date()
+				eventTime := time.Date(year, month, date, t.Hour, t.Minute, 0, 0, location)
+				callbackEvent := events.NewCallbackEvent(eventTime, entry.callback)
+				scheduledEvents = append(scheduledEvents, &callbackEvent)
+			}
+		}
+	}
+
+	return scheduledEvents, nil
+}
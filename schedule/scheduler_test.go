@@ -0,0 +1,131 @@
+package schedule
+
+import (
+	"gobacktrader/btutil"
+	"gobacktrader/calendar"
+	"gobacktrader/events"
+	"testing"
+	"time"
+)
+
+func TestScheduler(t *testing.T) {
+	scheduler := NewScheduler(calendar.NewWeekdayCalendar())
+	if scheduler.NumEntries() != 0 {
+		t.Error("Expecting a new scheduler to have no entries")
+	}
+
+	var monthEnds, intraday []time.Time
+	monthEndRule := NewLastTradingDayOfMonth()
+	entry := scheduler.Add(monthEndRule, func(t time.Time) error {
+		monthEnds = append(monthEnds, t)
+		return nil
+	})
+	if entry.GetRule() != monthEndRule {
+		t.Error("Unexpected entry rule")
+	}
+
+	scheduler.Add(NewWeekly(time.Monday), func(t time.Time) error {
+		intraday = append(intraday, t)
+		return nil
+	}).At(10, 0).At(15, 30)
+
+	if scheduler.NumEntries() != 2 {
+		t.Errorf("Expecting 2 entries, got %d", scheduler.NumEntries())
+	}
+
+	start := btutil.Date(2021, 3, 1)
+	end := btutil.Date(2021, 3, 31)
+	scheduledEvents, err := scheduler.GenerateEvents(start, end)
+	if err != nil {
+		t.Fatalf("Error in GenerateEvents - %s", err)
+	}
+
+	// one month end plus five Mondays at two times each
+	if len(scheduledEvents) != 11 {
+		t.Fatalf("Expecting 11 events, got %d", len(scheduledEvents))
+	}
+
+	queue := events.NewEvents()
+	for _, event := range scheduledEvents {
+		queue.Add(event)
+	}
+	for !queue.IsEmpty() {
+		event, _ := queue.Get()
+		if err := event.Process(); err != nil {
+			t.Fatalf("Error in event.Process() - %s", err)
+		}
+	}
+
+	if len(monthEnds) != 1 || !monthEnds[0].Equal(btutil.Date(2021, 3, 31)) {
+		t.Errorf("Unexpected month end callbacks %v", monthEnds)
+	}
+	if len(intraday) != 10 {
+		t.Fatalf("Expecting 10 intraday callbacks, got %d", len(intraday))
+	}
+	if !intraday[0].Equal(time.Date(2021, time.March, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected first intraday callback %s", intraday[0])
+	}
+	if !intraday[1].Equal(time.Date(2021, time.March, 1, 15, 30, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected second intraday callback %s", intraday[1])
+	}
+
+	_, err = scheduler.GenerateEvents(end, start)
+	errStr := btutil.GetErrorString(err)
+	if errStr != "the schedule end cannot be before its start" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+}
+
+func TestSchedulerInvalidTime(t *testing.T) {
+	scheduler := NewScheduler(calendar.NewWeekdayCalendar())
+	entry := scheduler.Add(NewEveryTradingDay(), func(t time.Time) error {
+		return nil
+	}).At(25, 0).At(10, 0)
+
+	errStr := btutil.GetErrorString(entry.GetError())
+	if errStr != "'25:00' is not a valid time of day" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+
+	_, err := scheduler.GenerateEvents(btutil.Date(2021, 3, 1), btutil.Date(2021, 3, 5))
+	errStr = btutil.GetErrorString(err)
+	if errStr != "'25:00' is not a valid time of day" {
+		t.Errorf("Unexpected error string '%s'", errStr)
+	}
+}
+
+func TestSchedulerExchangeTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("Error in time.LoadLocation - %s", err)
+	}
+	cal, err := calendar.NewCalendar("NYSE", newYork)
+	if err != nil {
+		t.Fatalf("Error in NewCalendar - %s", err)
+	}
+	cal.AddHoliday(calendar.NewEasterOffset(-2))
+
+	scheduler := NewScheduler(cal)
+	scheduler.Add(NewEveryTradingDay(), func(t time.Time) error {
+		return nil
+	}).At(16, 0)
+
+	scheduledEvents, err := scheduler.GenerateEvents(btutil.Date(2021, 4, 1), btutil.Date(2021, 4, 5))
+	if err != nil {
+		t.Fatalf("Error in GenerateEvents - %s", err)
+	}
+
+	// Good Friday and the weekend are skipped, with the close in New York time
+	expected := []time.Time{
+		time.Date(2021, time.April, 1, 16, 0, 0, 0, newYork),
+		time.Date(2021, time.April, 5, 16, 0, 0, 0, newYork),
+	}
+	if len(scheduledEvents) != len(expected) {
+		t.Fatalf("Expecting %d events, got %d", len(expected), len(scheduledEvents))
+	}
+	for i := range expected {
+		if !scheduledEvents[i].GetTime().Equal(expected[i]) {
+			t.Errorf("Unexpected event time %s", scheduledEvents[i].GetTime())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/resample"
+	"gobacktrader/trade"
+	"math"
+	"sort"
+	"time"
+)
+
+// Rebalancer is a strategy that trades each portfolio it owns back to the
+// target weights from some scheme. It rebalances at the first time step,
+// then at the first step of each new period for some frequency, or at
+// every step where no frequency is set. Assets within a tolerance band
+// of their target weight are not traded, which avoids tiny trades.
+// A rebalance that cannot be made, such as while prices are missing,
+// is tried again at the next step.
+type Rebalancer struct {
+	scheme    IWeightScheme
+	assets    []asset.IAssetReadOnly
+	frequency resample.Frequency
+	tolerance float64
+	lastTime  time.Time
+	count     int
+	due       bool
+}
+
+// NewRebalancer returns a new instance of Rebalancer that sets
+// target weights for some assets using some weight scheme.
+func NewRebalancer(scheme IWeightScheme, assets ...asset.IAssetReadOnly) (*Rebalancer, error) {
+	if scheme == nil {
+		return nil, errors.New("a rebalancer requires a weight scheme")
+	}
+	if len(assets) == 0 {
+		return nil, errors.New("a rebalancer requires at least one asset")
+	}
+	seen := make(map[string]bool)
+	for _, a := range assets {
+		if seen[a.GetTicker()] {
+			return nil, fmt.Errorf("'%s' is included more than once", a.GetTicker())
+		}
+		seen[a.GetTicker()] = true
+	}
+	return &Rebalancer{
+		scheme: scheme,
+		assets: sortAssets(append([]asset.IAssetReadOnly(nil), assets...)),
+		due:    true,
+	}, nil
+}
+
+// GetAssets returns the assets rebalanced, ordered by ticker.
+func (r *Rebalancer) GetAssets() []asset.IAssetReadOnly {
+	return append([]asset.IAssetReadOnly(nil), r.assets...)
+}
+
+// SetFrequency sets how often to rebalance, where nil rebalances at every step.
+func (r *Rebalancer) SetFrequency(freq resample.Frequency) *Rebalancer {
+	r.frequency = freq
+	return r
+}
+
+// GetFrequency returns how often to rebalance.
+func (r *Rebalancer) GetFrequency() resample.Frequency {
+	return r.frequency
+}
+
+// SetTolerance sets the band either side of each target weight,
+// as a fraction of portfolio value, within which no trade is made.
+func (r *Rebalancer) SetTolerance(tolerance float64) error {
+	if tolerance < 0 || tolerance >= 1 {
+		return fmt.Errorf("tolerance %f must be at least zero and less than one", tolerance)
+	}
+	r.tolerance = tolerance
+	return nil
+}
+
+// GetTolerance returns the band either side of each target weight.
+func (r *Rebalancer) GetTolerance() float64 {
+	return r.tolerance
+}
+
+// GenerateTradesWithContext returns trades back to the target weights
+// when a rebalance is due.
+func (r *Rebalancer) GenerateTradesWithContext(ctx *backtest.Context) ([]*trade.Trade, error) {
+	currentTime := ctx.GetTime()
+	if r.frequency != nil && !r.lastTime.IsZero() && r.frequency.IsNewPeriod(r.lastTime, currentTime, r.count) {
+		r.due = true
+		r.count = 0
+	}
+	r.lastTime = currentTime
+	r.count++
+	if !r.due || ctx.IsWarmingUp() {
+		return nil, nil
+	}
+
+	targets, ok, err := r.scheme.GetTargetWeights(ctx, r.assets)
+	if err != nil || !ok {
+		return nil, err
+	}
+	for _, a := range r.assets {
+		if !a.GetValue().Valid || a.GetValue().Float64 == 0 {
+			return nil, nil // wait until every asset is priced
+		}
+	}
+
+	var trades []*trade.Trade
+	for _, p := range ctx.GetPortfolios() {
+		portfolioTrades, ok, err := r.rebalance(p, targets)
+		if err != nil || !ok {
+			return nil, err
+		}
+		trades = append(trades, portfolioTrades...)
+	}
+	r.due = r.frequency == nil
+	return trades, nil
+}
+
+// rebalance returns the trades that move a portfolio to target weights,
+// with sells ahead of buys, or false if the portfolio cannot be valued.
+func (r *Rebalancer) rebalance(p *asset.Portfolio, targets map[asset.IAssetReadOnly]float64) ([]*trade.Trade, bool, error) {
+	value, weights, err := p.GetValueWeights()
+	if err != nil || !value.Valid {
+		return nil, false, nil
+	}
+
+	outside := make(map[asset.IAssetReadOnly]float64)
+	for _, a := range r.assets {
+		current := 0.0
+		if weight, ok := weights[a]; ok && weight.Valid {
+			current = weight.Float64
+		}
+		if math.Abs(targets[a]-current) <= r.tolerance {
+			continue
+		}
+		_, ok, err := p.GetFxRates().GetRate(a.GetBaseCurrency() + p.GetBaseCurrency())
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			return nil, false, nil // wait for an fx rate
+		}
+		outside[a] = targets[a]
+	}
+
+	builder, err := trade.NewTargetBuilder(p)
+	if err != nil {
+		return nil, false, err
+	}
+	trades, err := builder.ToWeights(outside)
+	return trades, err == nil, err
+}
+
+// sortAssets sorts assets by ticker.
+func sortAssets(assets []asset.IAssetReadOnly) []asset.IAssetReadOnly {
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].GetTicker() < assets[j].GetTicker()
+	})
+	return assets
+}
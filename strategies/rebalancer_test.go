@@ -0,0 +1,167 @@
+package strategies
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/resample"
+	"gobacktrader/trade"
+	"math"
+	"testing"
+	"time"
+)
+
+// newTestBacktest returns a backtest over a number of daily steps from
+// 2021-03-01 with a portfolio holding 10,000 AUD and two stocks. The price
+// of AAA alternates by 1% and the price of BBB alternates by 2%.
+func newTestBacktest(t *testing.T, strategy backtest.IContextStrategy, days int) (*backtest.Backtest, *asset.Portfolio, *asset.Asset, *asset.Asset) {
+	bt := backtest.NewContextBacktest(strategy)
+	p, err1 := bt.NewPortfolio("XXX", "AUD")
+	stockA, err2 := asset.NewStock("AAA", "AUD")
+	stockB, err3 := asset.NewStock("BBB", "AUD")
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset creation - %s", err)
+	}
+	cash, err := p.GetCash("AUD")
+	if err != nil {
+		t.Fatalf("Error in GetCash - %s", err)
+	}
+	p.Transfer(cash, 10000)
+	bt.RegisterAsset(stockA)
+	bt.RegisterAsset(stockB)
+
+	priceA, priceB := 10.0, 20.0
+	for i := 0; i < days; i++ {
+		eventTime := btutil.Date(2021, 3, 1).AddDate(0, 0, i)
+		sign := 1.0
+		if i%2 == 1 {
+			sign = -1.0
+		}
+		priceA *= 1 + 0.01*sign
+		priceB *= 1 + 0.02*sign
+		eventA := events.NewAssetPriceEvent(stockA, eventTime, asset.Price{Float64: priceA, Valid: true})
+		eventB := events.NewAssetPriceEvent(stockB, eventTime, asset.Price{Float64: priceB, Valid: true})
+		bt.AddEvents([]events.IEvent{&eventA, &eventB})
+	}
+	return &bt, p, stockA, stockB
+}
+
+func TestRebalancer(t *testing.T) {
+	tests := []struct {
+		tolerance float64
+		expected  []time.Time
+	}{
+		// an initial rebalance then one at the start of each month
+		{0, []time.Time{btutil.Date(2021, 3, 1), btutil.Date(2021, 4, 1), btutil.Date(2021, 5, 1)}},
+		// the weights stay within the tolerance band after the initial rebalance
+		{0.01, []time.Time{btutil.Date(2021, 3, 1)}},
+	}
+	for _, test := range tests {
+		var rebalancer *Rebalancer
+		var tradeTimes []time.Time
+		strategy := backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+			trades, err := rebalancer.GenerateTradesWithContext(ctx)
+			if len(trades) > 0 {
+				tradeTimes = append(tradeTimes, ctx.GetTime())
+			}
+			return trades, err
+		})
+		bt, p, stockA, stockB := newTestBacktest(t, strategy, 70)
+
+		var err error
+		rebalancer, err = NewRebalancer(NewEqualWeight(), stockB, stockA)
+		if err != nil {
+			t.Fatalf("Error in NewRebalancer - %s", err)
+		}
+		rebalancer.SetFrequency(resample.NewMonthEnd())
+		if err := rebalancer.SetTolerance(test.tolerance); err != nil {
+			t.Fatalf("Error in SetTolerance - %s", err)
+		}
+		if assets := rebalancer.GetAssets(); assets[0] != stockA || assets[1] != stockB {
+			t.Error("Unexpected assets order")
+		}
+		if rebalancer.GetTolerance() != test.tolerance || rebalancer.GetFrequency() == nil {
+			t.Error("Unexpected rebalancer settings")
+		}
+
+		if err := bt.Run(); err != nil {
+			t.Fatalf("Error in Run - %s", err)
+		}
+		if len(tradeTimes) != len(test.expected) {
+			t.Fatalf("Unexpected rebalance times %v", tradeTimes)
+		}
+		for i := range tradeTimes {
+			if !tradeTimes[i].Equal(test.expected[i]) {
+				t.Errorf("Unexpected rebalance times %v", tradeTimes)
+			}
+		}
+
+		value, weights, err := p.GetValueWeights()
+		if err != nil || !value.Valid {
+			t.Fatalf("Error in GetValueWeights - %s", err)
+		}
+		for _, a := range []asset.IAssetReadOnly{stockA, stockB} {
+			if math.Abs(weights[a].Float64-0.5) > 0.01 {
+				t.Errorf("Unexpected weight for %s - %f", a.GetTicker(), weights[a].Float64)
+			}
+		}
+	}
+}
+
+func TestRebalancerEveryStep(t *testing.T) {
+	var rebalancer *Rebalancer
+	steps := 0
+	strategy := backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+		trades, err := rebalancer.GenerateTradesWithContext(ctx)
+		if len(trades) > 0 {
+			steps++
+		}
+		return trades, err
+	})
+	bt, _, stockA, stockB := newTestBacktest(t, strategy, 10)
+	bt.SetWarmUpSteps(3)
+
+	weights, err := NewFixedWeights(map[asset.IAssetReadOnly]float64{stockA: 0.3, stockB: 0.6})
+	if err != nil {
+		t.Fatalf("Error in NewFixedWeights - %s", err)
+	}
+	rebalancer, err = NewRebalancer(weights, weights.GetAssets()...)
+	if err != nil {
+		t.Fatalf("Error in NewRebalancer - %s", err)
+	}
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+	// with no frequency or tolerance every step after warm-up trades
+	if steps != 7 {
+		t.Errorf("Unexpected rebalance steps %d", steps)
+	}
+}
+
+func TestRebalancerErrors(t *testing.T) {
+	stock, err := asset.NewStock("AAA", "AUD")
+	if err != nil {
+		t.Fatalf("Error in asset.NewStock - %s", err)
+	}
+	if _, err := NewRebalancer(nil, stock); err == nil {
+		t.Error("Expected error for a missing weight scheme")
+	}
+	if _, err := NewRebalancer(NewEqualWeight()); err == nil {
+		t.Error("Expected error for no assets")
+	}
+	_, err = NewRebalancer(NewEqualWeight(), stock, stock)
+	if err == nil || err.Error() != "'AAA' is included more than once" {
+		t.Errorf("Unexpected error in NewRebalancer - %v", err)
+	}
+	rebalancer, err := NewRebalancer(NewEqualWeight(), stock)
+	if err != nil {
+		t.Fatalf("Error in NewRebalancer - %s", err)
+	}
+	if err := rebalancer.SetTolerance(-0.1); err == nil {
+		t.Error("Expected error for a negative tolerance")
+	}
+	if err := rebalancer.SetTolerance(1); err == nil {
+		t.Error("Expected error for a tolerance of one")
+	}
+}
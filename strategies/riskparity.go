@@ -0,0 +1,147 @@
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/analytics"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"math"
+)
+
+// RiskParity weights assets so that each contributes equally to portfolio
+// volatility, given the covariance of their returns over a number of
+// price snapshots. Weights are found by cyclical coordinate descent.
+type RiskParity struct {
+	lookback      int
+	maxIterations int
+	tolerance     float64
+}
+
+// NewRiskParity returns a new instance of RiskParity estimating covariance
+// from the returns between a lookback number of price snapshots,
+// which must be at least two.
+func NewRiskParity(lookback int) (*RiskParity, error) {
+	if lookback < 2 {
+		return nil, errors.New("the covariance lookback must be at least two returns")
+	}
+	return &RiskParity{lookback: lookback, maxIterations: 1000, tolerance: 1e-10}, nil
+}
+
+// GetLookback returns the number of returns used to estimate covariance.
+func (s *RiskParity) GetLookback() int {
+	return s.lookback
+}
+
+// SetSolver sets the maximum number of iterations and the tolerance on
+// the relative change in weights at which the solve stops, defaulting to 1000 and 1e-10.
+func (s *RiskParity) SetSolver(maxIterations int, tolerance float64) error {
+	if maxIterations < 1 {
+		return errors.New("the solver requires at least one iteration")
+	}
+	if tolerance <= 0 {
+		return errors.New("the solver tolerance must be positive")
+	}
+	s.maxIterations = maxIterations
+	s.tolerance = tolerance
+	return nil
+}
+
+// GetTargetWeights returns weights with equal risk contributions,
+// or false until there are enough price snapshots for every asset.
+// An asset with flat prices over the lookback has no risk to budget,
+// so is given a weight of zero. It is false while every asset is flat.
+func (s *RiskParity) GetTargetWeights(ctx *backtest.Context, assets []asset.IAssetReadOnly) (map[asset.IAssetReadOnly]float64, bool, error) {
+	if len(assets) == 0 {
+		return nil, false, errors.New("target weights require at least one asset")
+	}
+	returns, ok := alignedReturns(ctx, assets, s.lookback)
+	if !ok {
+		return nil, false, nil
+	}
+	var volatile []int
+	var volatileReturns [][]float64
+	for i := range assets {
+		if hasVolatility(returns[i]) {
+			volatile = append(volatile, i)
+			volatileReturns = append(volatileReturns, returns[i])
+		}
+	}
+	if len(volatile) == 0 {
+		return nil, false, nil
+	}
+
+	solved, err := solveRiskParity(covariance(volatileReturns), s.maxIterations, s.tolerance)
+	if err != nil {
+		return nil, false, err
+	}
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for _, a := range assets {
+		weights[a] = 0
+	}
+	for j, i := range volatile {
+		weights[assets[i]] = solved[j]
+	}
+	return weights, true, nil
+}
+
+// covariance returns the sample covariance matrix of some return series.
+func covariance(returns [][]float64) [][]float64 {
+	n := len(returns)
+	means := make([]float64, n)
+	for i := range returns {
+		means[i] = analytics.Mean(returns[i])
+	}
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i; j < n; j++ {
+			total := 0.0
+			for k := range returns[i] {
+				total += (returns[i][k] - means[i]) * (returns[j][k] - means[j])
+			}
+			matrix[i][j] = total / float64(len(returns[i])-1)
+			matrix[j][i] = matrix[i][j]
+		}
+	}
+	return matrix
+}
+
+// solveRiskParity solves x_i * (Σx)_i = 1/n for each asset by cyclical
+// coordinate descent, returning x scaled to sum to one.
+func solveRiskParity(matrix [][]float64, maxIterations int, tolerance float64) ([]float64, error) {
+	n := len(matrix)
+	budget := 1.0 / float64(n)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = 1 / math.Sqrt(matrix[i][i]) / float64(n)
+	}
+
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		change := 0.0
+		for i := 0; i < n; i++ {
+			c := 0.0
+			for j := 0; j < n; j++ {
+				if j != i {
+					c += matrix[i][j] * x[j]
+				}
+			}
+			next := (-c + math.Sqrt(c*c+4*matrix[i][i]*budget)) / (2 * matrix[i][i])
+			change = math.Max(change, math.Abs(next-x[i])/next)
+			x[i] = next
+		}
+		if change < tolerance {
+			total := 0.0
+			for _, value := range x {
+				total += value
+			}
+			for i := range x {
+				x[i] /= total
+			}
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("risk parity weights did not converge within %d iterations", maxIterations)
+}
@@ -0,0 +1,81 @@
+package strategies
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolveRiskParity(t *testing.T) {
+	// uncorrelated assets are weighted by inverse volatility
+	weights, err := solveRiskParity([][]float64{{0.01, 0}, {0, 0.04}}, 1000, 1e-12)
+	if err != nil {
+		t.Fatalf("Error in solveRiskParity - %s", err)
+	}
+	if math.Abs(weights[0]-2.0/3.0) > 1e-9 || math.Abs(weights[1]-1.0/3.0) > 1e-9 {
+		t.Errorf("Unexpected weights %v", weights)
+	}
+
+	// correlated assets have equal risk contributions
+	matrix := [][]float64{
+		{0.04, 0.006, 0.01},
+		{0.006, 0.01, -0.002},
+		{0.01, -0.002, 0.09},
+	}
+	weights, err = solveRiskParity(matrix, 1000, 1e-12)
+	if err != nil {
+		t.Fatalf("Error in solveRiskParity - %s", err)
+	}
+	contributions := make([]float64, 3)
+	total := 0.0
+	for i := range matrix {
+		marginal := 0.0
+		for j := range matrix {
+			marginal += matrix[i][j] * weights[j]
+		}
+		contributions[i] = weights[i] * marginal
+		total += weights[i]
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("Unexpected total weight %f", total)
+	}
+	for i := 1; i < 3; i++ {
+		if math.Abs(contributions[i]-contributions[0]) > 1e-9 {
+			t.Errorf("Unexpected risk contributions %v", contributions)
+		}
+	}
+
+	if _, err := solveRiskParity(matrix, 1, 1e-12); err == nil {
+		t.Error("Expected error when the solve does not converge")
+	}
+}
+
+func TestRiskParity(t *testing.T) {
+	if _, err := NewRiskParity(1); err == nil {
+		t.Error("Expected error for a lookback of one")
+	}
+	scheme, err := NewRiskParity(10)
+	if err != nil {
+		t.Fatalf("Error in NewRiskParity - %s", err)
+	}
+	if scheme.GetLookback() != 10 {
+		t.Error("Unexpected lookback")
+	}
+	if err := scheme.SetSolver(0, 1e-8); err == nil {
+		t.Error("Expected error for no iterations")
+	}
+	if err := scheme.SetSolver(100, 0); err == nil {
+		t.Error("Expected error for a zero tolerance")
+	}
+	if err := scheme.SetSolver(500, 1e-12); err != nil {
+		t.Errorf("Error in SetSolver - %s", err)
+	}
+
+	// for two assets risk parity matches inverse volatility
+	weights, ok, stockA, stockB := lastTargetWeights(t, scheme, 12)
+	if !ok {
+		t.Fatal("Expected weights with enough history")
+	}
+	if math.Abs(weights[stockA]-2.0/3.0) > 0.001 || math.Abs(weights[stockB]-1.0/3.0) > 0.001 {
+		t.Errorf("Unexpected risk parity weights %f, %f", weights[stockA], weights[stockB])
+	}
+}
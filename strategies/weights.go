@@ -0,0 +1,210 @@
+// Package strategies provides ready-made strategies that periodically
+// rebalance portfolios to target weights.
+package strategies
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/analytics"
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"math"
+)
+
+// IWeightScheme defines the interface for schemes that set target weights
+// for some assets. The weights are fractions of portfolio value, where any
+// remainder is held in cash. Schemes return false until they have
+// enough history to set weights.
+type IWeightScheme interface {
+	GetTargetWeights(ctx *backtest.Context, assets []asset.IAssetReadOnly) (map[asset.IAssetReadOnly]float64, bool, error)
+}
+
+// EqualWeight invests an equal weight in each asset.
+type EqualWeight struct{}
+
+// NewEqualWeight returns a new instance of EqualWeight.
+func NewEqualWeight() EqualWeight {
+	return EqualWeight{}
+}
+
+// GetTargetWeights returns an equal weight for each asset.
+func (s EqualWeight) GetTargetWeights(ctx *backtest.Context, assets []asset.IAssetReadOnly) (map[asset.IAssetReadOnly]float64, bool, error) {
+	if len(assets) == 0 {
+		return nil, false, errors.New("target weights require at least one asset")
+	}
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for _, a := range assets {
+		weights[a] = 1.0 / float64(len(assets))
+	}
+	return weights, true, nil
+}
+
+// FixedWeights invests fixed weights in each asset,
+// where assets without a weight are sold.
+type FixedWeights struct {
+	weights map[asset.IAssetReadOnly]float64
+}
+
+// NewFixedWeights returns a new instance of FixedWeights. Weights must
+// not be negative and must sum to no more than one.
+func NewFixedWeights(weights map[asset.IAssetReadOnly]float64) (*FixedWeights, error) {
+	if len(weights) == 0 {
+		return nil, errors.New("fixed weights require at least one asset")
+	}
+	total := 0.0
+	copied := make(map[asset.IAssetReadOnly]float64)
+	for a, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("'%s' weight %f must not be negative", a.GetTicker(), weight)
+		}
+		total += weight
+		copied[a] = weight
+	}
+	if total > 1+1e-9 {
+		return nil, fmt.Errorf("fixed weights sum to %f which is more than one", total)
+	}
+	return &FixedWeights{weights: copied}, nil
+}
+
+// GetWeights returns a copy of the fixed weights.
+func (s *FixedWeights) GetWeights() map[asset.IAssetReadOnly]float64 {
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for a, weight := range s.weights {
+		weights[a] = weight
+	}
+	return weights
+}
+
+// GetAssets returns the assets with fixed weights.
+func (s *FixedWeights) GetAssets() []asset.IAssetReadOnly {
+	var assets []asset.IAssetReadOnly
+	for a := range s.weights {
+		assets = append(assets, a)
+	}
+	return sortAssets(assets)
+}
+
+// GetTargetWeights returns the fixed weight of each asset,
+// which is zero for assets without a weight.
+func (s *FixedWeights) GetTargetWeights(ctx *backtest.Context, assets []asset.IAssetReadOnly) (map[asset.IAssetReadOnly]float64, bool, error) {
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for _, a := range assets {
+		weights[a] = s.weights[a]
+	}
+	return weights, true, nil
+}
+
+// InverseVolatility weights assets in proportion to the inverse of
+// the volatility of their returns over a number of price snapshots.
+type InverseVolatility struct {
+	lookback int
+}
+
+// NewInverseVolatility returns a new instance of InverseVolatility
+// estimating volatility from the returns between a lookback
+// number of price snapshots, which must be at least two.
+func NewInverseVolatility(lookback int) (*InverseVolatility, error) {
+	if lookback < 2 {
+		return nil, errors.New("the volatility lookback must be at least two returns")
+	}
+	return &InverseVolatility{lookback: lookback}, nil
+}
+
+// GetLookback returns the number of returns used to estimate volatility.
+func (s *InverseVolatility) GetLookback() int {
+	return s.lookback
+}
+
+// GetTargetWeights returns weights in proportion to inverse volatility,
+// or false until there are enough price snapshots for every asset. An
+// asset with flat prices over the lookback has no volatility to weight
+// by, so is given a weight of zero. It is false while every asset is flat.
+func (s *InverseVolatility) GetTargetWeights(ctx *backtest.Context, assets []asset.IAssetReadOnly) (map[asset.IAssetReadOnly]float64, bool, error) {
+	if len(assets) == 0 {
+		return nil, false, errors.New("target weights require at least one asset")
+	}
+	returns, ok := alignedReturns(ctx, assets, s.lookback)
+	if !ok {
+		return nil, false, nil
+	}
+
+	inverse := make([]float64, len(assets))
+	total := 0.0
+	for i := range assets {
+		if !hasVolatility(returns[i]) {
+			continue
+		}
+		inverse[i] = 1 / analytics.StdDev(returns[i])
+		total += inverse[i]
+	}
+	if total == 0 {
+		return nil, false, nil
+	}
+
+	weights := make(map[asset.IAssetReadOnly]float64)
+	for i, a := range assets {
+		weights[a] = inverse[i] / total
+	}
+	return weights, true, nil
+}
+
+// hasVolatility returns true if some returns are not all equal.
+func hasVolatility(returns []float64) bool {
+	volatility := analytics.StdDev(returns)
+	return volatility > 0 && !math.IsNaN(volatility)
+}
+
+// alignedReturns returns the returns of each asset between the last
+// lookback + 1 price snapshot times at which every asset has a valid
+// price, or false if there are not yet enough snapshots. Only the
+// trailing snapshots are read, walking back from the current time.
+func alignedReturns(ctx *backtest.Context, assets []asset.IAssetReadOnly, lookback int) ([][]float64, bool) {
+	series := make([]*asset.PriceSeries, len(assets))
+	for i, a := range assets {
+		series[i] = a.GetPriceSeries()
+	}
+
+	// prices are collected from the latest time backwards
+	prices := make([][]float64, len(assets))
+	for j := series[0].Len() - 1; j >= 0 && len(prices[0]) < lookback+1; j-- {
+		snap := series[0].At(j)
+		if snap.GetTime().After(ctx.GetTime()) {
+			continue
+		}
+		row, common := make([]float64, len(assets)), true
+		for i := range assets {
+			if i > 0 {
+				var ok bool
+				if snap, ok = series[i].Get(snap.GetTime()); !ok {
+					common = false
+					break
+				}
+			}
+			price := snap.GetPrice()
+			if !price.Valid {
+				common = false
+				break
+			}
+			row[i] = price.Float64
+		}
+		if !common {
+			continue
+		}
+		for i := range assets {
+			prices[i] = append(prices[i], row[i])
+		}
+	}
+	if len(prices[0]) < lookback+1 {
+		return nil, false
+	}
+
+	returns := make([][]float64, len(assets))
+	for i := range assets {
+		values := prices[i]
+		for l, r := 0, len(values)-1; l < r; l, r = l+1, r-1 {
+			values[l], values[r] = values[r], values[l]
+		}
+		returns[i] = analytics.Returns(values)
+	}
+	return returns, true
+}
@@ -0,0 +1,163 @@
+package strategies
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/backtest"
+	"gobacktrader/btutil"
+	"gobacktrader/events"
+	"gobacktrader/trade"
+	"math"
+	"testing"
+)
+
+// lastTargetWeights runs a test backtest and returns the target weights
+// from some scheme at the last step along with whether they were set.
+func lastTargetWeights(t *testing.T, scheme IWeightScheme, days int) (map[asset.IAssetReadOnly]float64, bool, *asset.Asset, *asset.Asset) {
+	var weights map[asset.IAssetReadOnly]float64
+	var ok bool
+	var assets []asset.IAssetReadOnly
+	strategy := backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+		var err error
+		weights, ok, err = scheme.GetTargetWeights(ctx, assets)
+		return nil, err
+	})
+	bt, _, stockA, stockB := newTestBacktest(t, strategy, days)
+	assets = []asset.IAssetReadOnly{stockA, stockB}
+	if err := bt.Run(); err != nil {
+		t.Fatalf("Error in Run - %s", err)
+	}
+	return weights, ok, stockA, stockB
+}
+
+func TestEqualWeight(t *testing.T) {
+	stockA, err1 := asset.NewStock("AAA", "AUD")
+	stockB, err2 := asset.NewStock("BBB", "AUD")
+	if err1 != nil || err2 != nil {
+		t.Fatal("Error in asset.NewStock")
+	}
+	weights, ok, err := NewEqualWeight().GetTargetWeights(nil, []asset.IAssetReadOnly{stockA, stockB})
+	if err != nil || !ok {
+		t.Fatalf("Error in GetTargetWeights - %v", err)
+	}
+	if weights[stockA] != 0.5 || weights[stockB] != 0.5 {
+		t.Error("Unexpected equal weights")
+	}
+	if _, _, err := NewEqualWeight().GetTargetWeights(nil, nil); err == nil {
+		t.Error("Expected error for no assets")
+	}
+}
+
+func TestFixedWeights(t *testing.T) {
+	stockA, err1 := asset.NewStock("AAA", "AUD")
+	stockB, err2 := asset.NewStock("BBB", "AUD")
+	stockC, err3 := asset.NewStock("CCC", "AUD")
+	if err1 != nil || err2 != nil || err3 != nil {
+		t.Fatal("Error in asset.NewStock")
+	}
+	fixed, err := NewFixedWeights(map[asset.IAssetReadOnly]float64{stockB: 0.25, stockA: 0.7})
+	if err != nil {
+		t.Fatalf("Error in NewFixedWeights - %s", err)
+	}
+	if assets := fixed.GetAssets(); len(assets) != 2 || assets[0] != stockA {
+		t.Error("Unexpected fixed weight assets")
+	}
+	weights, ok, err := fixed.GetTargetWeights(nil, []asset.IAssetReadOnly{stockA, stockB, stockC})
+	if err != nil || !ok {
+		t.Fatalf("Error in GetTargetWeights - %v", err)
+	}
+	if weights[stockA] != 0.7 || weights[stockB] != 0.25 || weights[stockC] != 0 {
+		t.Error("Unexpected fixed weights")
+	}
+
+	tests := []struct {
+		weights map[asset.IAssetReadOnly]float64
+		errStr  string
+	}{
+		{nil, "fixed weights require at least one asset"},
+		{map[asset.IAssetReadOnly]float64{stockA: -0.1}, "'AAA' weight -0.100000 must not be negative"},
+		{map[asset.IAssetReadOnly]float64{stockA: 0.6, stockB: 0.5}, "fixed weights sum to 1.100000 which is more than one"},
+	}
+	for _, test := range tests {
+		_, err := NewFixedWeights(test.weights)
+		if err == nil || err.Error() != test.errStr {
+			t.Errorf("Unexpected error in NewFixedWeights - %v", err)
+		}
+	}
+}
+
+func TestInverseVolatility(t *testing.T) {
+	if _, err := NewInverseVolatility(1); err == nil {
+		t.Error("Expected error for a lookback of one")
+	}
+	scheme, err := NewInverseVolatility(10)
+	if err != nil {
+		t.Fatalf("Error in NewInverseVolatility - %s", err)
+	}
+	if scheme.GetLookback() != 10 {
+		t.Error("Unexpected lookback")
+	}
+
+	// not enough snapshots, as these are taken after each step
+	if _, ok, _, _ := lastTargetWeights(t, scheme, 11); ok {
+		t.Error("Expected no weights without enough history")
+	}
+
+	// BBB has twice the volatility of AAA
+	weights, ok, stockA, stockB := lastTargetWeights(t, scheme, 12)
+	if !ok {
+		t.Fatal("Expected weights with enough history")
+	}
+	if math.Abs(weights[stockA]-2.0/3.0) > 0.001 || math.Abs(weights[stockB]-1.0/3.0) > 0.001 {
+		t.Errorf("Unexpected inverse volatility weights %f, %f", weights[stockA], weights[stockB])
+	}
+}
+
+func TestFlatPriceWeights(t *testing.T) {
+	inverseVolatility, err1 := NewInverseVolatility(5)
+	riskParity, err2 := NewRiskParity(5)
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in scheme creation - %s", err)
+	}
+
+	// runs a backtest with an asset with flat prices, returning the last
+	// weights for that asset along with the first stock if withStock is set
+	run := func(scheme IWeightScheme, withStock bool) (map[asset.IAssetReadOnly]float64, bool, asset.IAssetReadOnly, asset.IAssetReadOnly) {
+		var ok bool
+		var weights map[asset.IAssetReadOnly]float64
+		var assets []asset.IAssetReadOnly
+		strategy := backtest.NewContextStrategy(func(ctx *backtest.Context) ([]*trade.Trade, error) {
+			var err error
+			weights, ok, err = scheme.GetTargetWeights(ctx, assets)
+			return nil, err
+		})
+		bt, _, stockA, _ := newTestBacktest(t, strategy, 12)
+		flat, err := asset.NewStock("CCC", "AUD")
+		if err != nil {
+			t.Fatalf("Error in NewStock - %s", err)
+		}
+		bt.RegisterAsset(flat)
+		for i := 0; i < 12; i++ {
+			event := events.NewAssetPriceEvent(flat, btutil.Date(2021, 3, 1).AddDate(0, 0, i), asset.Price{Float64: 5, Valid: true})
+			bt.AddEvent(&event)
+		}
+		assets = []asset.IAssetReadOnly{flat}
+		if withStock {
+			assets = append(assets, stockA)
+		}
+		if err := bt.Run(); err != nil {
+			t.Fatalf("Error in Run - %s", err)
+		}
+		return weights, ok, flat, stockA
+	}
+
+	// an asset with flat prices has no volatility, so is given no weight
+	for _, scheme := range []IWeightScheme{inverseVolatility, riskParity} {
+		weights, ok, flat, stockA := run(scheme, true)
+		if !ok || weights[flat] != 0 || btutil.Round4dp(weights[stockA]) != 1 {
+			t.Errorf("Unexpected weights with a flat price asset - %v", weights)
+		}
+		if _, ok, _, _ := run(scheme, false); ok {
+			t.Error("Expected no weights when every asset is flat")
+		}
+	}
+}
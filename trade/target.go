@@ -0,0 +1,207 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"gobacktrader/asset"
+	"math"
+	"sort"
+)
+
+// EstimateCharges returns the expected fall in portfolio value from
+// executing the trade in the portfolio's base currency, which includes
+// broker charges and any slippage. The trade is executed against a copy
+// of the portfolio, so the portfolio itself is unchanged.
+func (t *Trade) EstimateCharges() (float64, error) {
+	portfolio := t.GetPortfolio()
+	if portfolio == nil {
+		return 0, errors.New("estimating charges requires a trade portfolio")
+	}
+	if portfolio.GetBroker() == nil || t.GetUnits() == 0 {
+		return 0, nil
+	}
+
+	portfolioCopy, err := portfolio.Copy()
+	if err != nil {
+		return 0, err
+	}
+	before, err := portfolioCopy.GetValue()
+	if err != nil {
+		return 0, err
+	}
+	if err := portfolioCopy.GetBroker().Execute(t.ChangePortfolio(portfolioCopy)); err != nil {
+		return 0, err
+	}
+	after, err := portfolioCopy.GetValue()
+	if err != nil {
+		return 0, err
+	}
+	if !before.Valid || !after.Valid {
+		return 0, fmt.Errorf("'%s' cannot estimate charges for a portfolio with invalid value", portfolio.GetCode())
+	}
+	return before.Float64 - after.Float64, nil
+}
+
+// TargetBuilder builds the trades that move a portfolio to target units,
+// base currency values or weights for one or more assets, without
+// executing them. Values are converted to units with the portfolio's
+// FX rates and each asset's value, which includes its multiplier.
+// Trades are rounded toward zero to meet each asset's trading rules,
+// and trades that are still too small are left out, except for trades
+// that close out a position.
+type TargetBuilder struct {
+	portfolio      *asset.Portfolio
+	includeCharges bool
+}
+
+// NewTargetBuilder returns a new instance of TargetBuilder for some portfolio.
+func NewTargetBuilder(portfolio *asset.Portfolio) (*TargetBuilder, error) {
+	if portfolio == nil {
+		return nil, errors.New("a target builder requires a portfolio")
+	}
+	return &TargetBuilder{portfolio: portfolio}, nil
+}
+
+// GetPortfolio returns the portfolio traded.
+func (b *TargetBuilder) GetPortfolio() *asset.Portfolio {
+	return b.portfolio
+}
+
+// SetIncludeCharges sets whether buys for target values and weights are
+// reduced by their expected charges so that their cost, including
+// charges, does not exceed the value targeted.
+func (b *TargetBuilder) SetIncludeCharges(includeCharges bool) *TargetBuilder {
+	b.includeCharges = includeCharges
+	return b
+}
+
+// GetIncludeCharges returns true if buys allow for expected charges.
+func (b *TargetBuilder) GetIncludeCharges() bool {
+	return b.includeCharges
+}
+
+// ToUnits returns the trades that move assets to target units.
+// Assets without a target are not traded.
+func (b *TargetBuilder) ToUnits(targets map[asset.IAssetReadOnly]float64) ([]*Trade, error) {
+	var trades []*Trade
+	for _, a := range sortedAssets(targets) {
+		if t := b.newTrade(a, targets[a]-b.portfolio.GetUnits(a)); t != nil {
+			trades = append(trades, t)
+		}
+	}
+	return sellsFirst(trades), nil
+}
+
+// ToValues returns the trades that move assets to target values
+// in the portfolio's base currency.
+func (b *TargetBuilder) ToValues(targets map[asset.IAssetReadOnly]float64) ([]*Trade, error) {
+	var trades []*Trade
+	for _, a := range sortedAssets(targets) {
+		unitValue, err := b.baseCurrencyValue(a)
+		if err != nil {
+			return nil, err
+		}
+		units := targets[a]/unitValue - b.portfolio.GetUnits(a)
+		if units > 0 && b.includeCharges {
+			if units, err = b.netOfCharges(a, units); err != nil {
+				return nil, err
+			}
+		}
+		if t := b.newTrade(a, units); t != nil {
+			trades = append(trades, t)
+		}
+	}
+	return sellsFirst(trades), nil
+}
+
+// ToWeights returns the trades that move assets to target weights
+// of the portfolio's current value.
+func (b *TargetBuilder) ToWeights(targets map[asset.IAssetReadOnly]float64) ([]*Trade, error) {
+	value, err := b.portfolio.GetValue()
+	if err != nil {
+		return nil, err
+	}
+	if !value.Valid {
+		return nil, fmt.Errorf("'%s' cannot target weights for a portfolio with invalid value", b.portfolio.GetCode())
+	}
+
+	values := make(map[asset.IAssetReadOnly]float64)
+	for a, weight := range targets {
+		values[a] = weight * value.Float64
+	}
+	return b.ToValues(values)
+}
+
+// baseCurrencyValue returns the value of one unit of some asset
+// in the portfolio's base currency.
+func (b *TargetBuilder) baseCurrencyValue(a asset.IAssetReadOnly) (float64, error) {
+	assetValue := a.GetValue()
+	if !assetValue.Valid || assetValue.Float64 == 0 {
+		return 0, fmt.Errorf("'%s' cannot size a trade for an asset with invalid value", a.GetTicker())
+	}
+	pair := a.GetBaseCurrency() + b.portfolio.GetBaseCurrency()
+	fxRate, ok, err := b.portfolio.GetFxRates().GetRate(pair)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("'%s' has no fx rate to size a trade", pair)
+	}
+	return assetValue.Float64 * fxRate, nil
+}
+
+// netOfCharges reduces units bought so that their value plus
+// expected charges matches the value of the units given. Charges
+// are estimated on the units rounded to the asset's trading rules,
+// as only those units can be executed.
+func (b *TargetBuilder) netOfCharges(a asset.IAssetReadOnly, units float64) (float64, error) {
+	unitValue, err := b.baseCurrencyValue(a)
+	if err != nil {
+		return 0, err
+	}
+	rules := asset.GetTradingRules(a)
+	rounded := rules.Round(units)
+	if rounded == 0 || rules.Check(a, rounded) != nil {
+		return 0, nil // there is nothing that can be traded
+	}
+	charges, err := NewTrade(b.portfolio, a, rounded).EstimateCharges()
+	if err != nil {
+		return 0, err
+	}
+	return math.Max(0, units-charges/unitValue), nil
+}
+
+// newTrade returns a trade in some units rounded to the asset's
+// trading rules, or nil if there is nothing that can be traded.
+func (b *TargetBuilder) newTrade(a asset.IAssetReadOnly, units float64) *Trade {
+	if units != 0 && units == -b.portfolio.GetUnits(a) {
+		return NewTrade(b.portfolio, a, units) // closing out a position
+	}
+	rules := asset.GetTradingRules(a)
+	units = rules.Round(units)
+	if units == 0 || rules.Check(a, units) != nil {
+		return nil
+	}
+	return NewTrade(b.portfolio, a, units)
+}
+
+// sortedAssets returns the assets with targets ordered by ticker.
+func sortedAssets(targets map[asset.IAssetReadOnly]float64) []asset.IAssetReadOnly {
+	assets := make([]asset.IAssetReadOnly, 0, len(targets))
+	for a := range targets {
+		assets = append(assets, a)
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].GetTicker() < assets[j].GetTicker()
+	})
+	return assets
+}
+
+// sellsFirst orders sells ahead of buys so that sale proceeds
+// are available to pay for buys.
+func sellsFirst(trades []*Trade) []*Trade {
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].GetUnits() < 0 && trades[j].GetUnits() > 0
+	})
+	return trades
+}
@@ -0,0 +1,231 @@
+package trade
+
+import (
+	"gobacktrader/asset"
+	"gobacktrader/broker"
+	"gobacktrader/btutil"
+	"math"
+	"testing"
+)
+
+// newTargetPortfolio returns an AUD portfolio holding 10,000 AUD cash
+// with a broker charging 10 AUD plus 0.1%, an AUD stock priced at 10
+// and a USD future priced at 5 with a multiplier of 10, where AUDUSD is 0.8.
+func newTargetPortfolio(t *testing.T) (*asset.Portfolio, *asset.Asset, *asset.Asset) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	future, err3 := asset.NewAssetWithMultiplier("ZZF US", "USD", 10)
+	aud, err4 := portfolio.GetCash("AUD")
+	audusd, err5 := asset.NewFxRate("AUDUSD", asset.Price{Float64: 0.8, Valid: true})
+	charges, err6 := broker.NewFixedRatePlusPercentageCharges(10, 0.001, "AUD")
+	if err := btutil.AnyValidError(err1, err2, err3, err4, err5, err6); err != nil {
+		t.Fatalf("Error in asset init - %s", err)
+	}
+	fxRates := asset.NewFxRates()
+	if err := fxRates.Register(audusd); err != nil {
+		t.Fatalf("Error in Register - %s", err)
+	}
+	portfolio.SetFxRates(fxRates)
+	portfolio.SetBroker(broker.NewBroker(charges, broker.NewFillAtLast()))
+	portfolio.Transfer(aud, 10000)
+	stock.SetPrice(asset.Price{Float64: 10, Valid: true})
+	future.SetPrice(asset.Price{Float64: 5, Valid: true})
+	return portfolio, stock, future
+}
+
+func TestEstimateCharges(t *testing.T) {
+	portfolio, stock, _ := newTargetPortfolio(t)
+	charges, err := NewTrade(portfolio, stock, 100).EstimateCharges()
+	if err != nil {
+		t.Fatalf("Error in EstimateCharges - %s", err)
+	}
+	if math.Abs(charges-11) > 1e-9 {
+		t.Errorf("Unexpected charges %f", charges)
+	}
+	if portfolio.GetUnits(stock) != 0 {
+		t.Error("Expecting the portfolio to be unchanged")
+	}
+
+	portfolio.SetBroker(nil)
+	if charges, err := NewTrade(portfolio, stock, 100).EstimateCharges(); err != nil || charges != 0 {
+		t.Error("Expecting no charges without a broker")
+	}
+	if _, err := NewTrade(nil, stock, 100).EstimateCharges(); err == nil {
+		t.Error("Expecting an error without a portfolio")
+	}
+}
+
+func TestTargetUnits(t *testing.T) {
+	portfolio, stock, future := newTargetPortfolio(t)
+	portfolio.Transfer(stock, 120)
+
+	builder, err := NewTargetBuilder(portfolio)
+	if err != nil {
+		t.Fatalf("Error in NewTargetBuilder - %s", err)
+	}
+	if builder.GetPortfolio() != portfolio {
+		t.Error("Unexpected portfolio")
+	}
+	rules, err := asset.NewTradingRules(100, 0, 0, true)
+	if err != nil {
+		t.Fatalf("Error in NewTradingRules - %s", err)
+	}
+	stock.SetTradingRules(rules)
+
+	// sells come ahead of buys and trades are rounded toward zero to whole lots
+	trades, err := builder.ToUnits(map[asset.IAssetReadOnly]float64{future: 2.5, stock: 10})
+	if err != nil {
+		t.Fatalf("Error in ToUnits - %s", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Unexpected number of trades %d", len(trades))
+	}
+	if trades[0].GetAsset() != stock || trades[0].GetUnits() != -100 {
+		t.Errorf("Unexpected stock trade %f", trades[0].GetUnits())
+	}
+	if trades[1].GetAsset() != future || trades[1].GetUnits() != 2.5 {
+		t.Errorf("Unexpected future trade %f", trades[1].GetUnits())
+	}
+
+	// trades that close out a position are not rounded
+	trades, err = builder.ToUnits(map[asset.IAssetReadOnly]float64{stock: 0})
+	if err != nil || len(trades) != 1 || trades[0].GetUnits() != -120 {
+		t.Error("Expecting the whole position to be sold")
+	}
+
+	// no trade where the target is already held
+	trades, err = builder.ToUnits(map[asset.IAssetReadOnly]float64{stock: 120})
+	if err != nil || len(trades) != 0 {
+		t.Error("Expecting no trades")
+	}
+}
+
+func TestTargetValues(t *testing.T) {
+	portfolio, stock, future := newTargetPortfolio(t)
+	builder, err := NewTargetBuilder(portfolio)
+	if err != nil {
+		t.Fatalf("Error in NewTargetBuilder - %s", err)
+	}
+
+	// one future is worth 5 * 10 USD, or 62.5 AUD
+	trades, err := builder.ToValues(map[asset.IAssetReadOnly]float64{future: 1000, stock: 2000})
+	if err != nil {
+		t.Fatalf("Error in ToValues - %s", err)
+	}
+	if len(trades) != 2 || trades[0].GetAsset() != stock || trades[1].GetAsset() != future {
+		t.Fatal("Unexpected trades")
+	}
+	if math.Abs(trades[0].GetUnits()-200) > 1e-9 || math.Abs(trades[1].GetUnits()-16) > 1e-9 {
+		t.Errorf("Unexpected units %f, %f", trades[0].GetUnits(), trades[1].GetUnits())
+	}
+
+	future.SetPrice(asset.Price{Float64: 0, Valid: false})
+	if _, err := builder.ToValues(map[asset.IAssetReadOnly]float64{future: 1000}); err == nil {
+		t.Error("Expecting an error for an asset with invalid value")
+	}
+	future.SetPrice(asset.Price{Float64: 5, Valid: true})
+	portfolio.SetFxRates(asset.NewFxRates())
+	_, err = builder.ToValues(map[asset.IAssetReadOnly]float64{future: 1000})
+	if err == nil || err.Error() != "'USDAUD' has no fx rate to size a trade" {
+		t.Errorf("Unexpected error in ToValues - %v", err)
+	}
+}
+
+func TestTargetWeights(t *testing.T) {
+	portfolio, stock, _ := newTargetPortfolio(t)
+	builder, err := NewTargetBuilder(portfolio)
+	if err != nil {
+		t.Fatalf("Error in NewTargetBuilder - %s", err)
+	}
+
+	trades, err := builder.ToWeights(map[asset.IAssetReadOnly]float64{stock: 0.5})
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("Error in ToWeights - %v", err)
+	}
+	if math.Abs(trades[0].GetUnits()-500) > 1e-9 {
+		t.Errorf("Unexpected units %f", trades[0].GetUnits())
+	}
+
+	// charges of 10 + 0.1% of 5,000 reduce the buy by 1.5 units
+	builder.SetIncludeCharges(true)
+	if !builder.GetIncludeCharges() {
+		t.Error("Expecting charges to be included")
+	}
+	trades, err = builder.ToWeights(map[asset.IAssetReadOnly]float64{stock: 0.5})
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("Error in ToWeights - %v", err)
+	}
+	if math.Abs(trades[0].GetUnits()-498.5) > 1e-9 {
+		t.Errorf("Unexpected units %f", trades[0].GetUnits())
+	}
+	rules, err := asset.NewTradingRules(1, 0, 0, true)
+	if err != nil {
+		t.Fatalf("Error in NewTradingRules - %s", err)
+	}
+	stock.SetTradingRules(rules)
+	trades, err = builder.ToWeights(map[asset.IAssetReadOnly]float64{stock: 0.5})
+	if err != nil || len(trades) != 1 || trades[0].GetUnits() != 498 {
+		t.Error("Unexpected units rounded to a lot")
+	}
+
+	// the trades can be executed as returned
+	if _, err := trades[0].Execute(); err != nil {
+		t.Fatalf("Error in Execute - %s", err)
+	}
+	value, weights, err := portfolio.GetValueWeights()
+	if err != nil || !value.Valid {
+		t.Fatalf("Error in GetValueWeights - %s", err)
+	}
+	if weights[stock].Float64 > 0.5 {
+		t.Errorf("Unexpected weight %f", weights[stock].Float64)
+	}
+
+	if _, err := NewTargetBuilder(nil); err == nil {
+		t.Error("Expecting an error without a portfolio")
+	}
+}
+
+func TestTargetTradingRules(t *testing.T) {
+	portfolio, stock, future := newTargetPortfolio(t)
+	stockRules, err1 := asset.NewTradingRules(100, 0, 0, false)
+	futureRules, err2 := asset.NewTradingRules(0, 0, 300, false)
+	if err := btutil.AnyValidError(err1, err2); err != nil {
+		t.Fatalf("Error in NewTradingRules - %s", err)
+	}
+	stock.SetTradingRules(stockRules)
+	future.SetTradingRules(futureRules)
+	builder, err := NewTargetBuilder(portfolio)
+	if err != nil {
+		t.Fatalf("Error in NewTargetBuilder - %s", err)
+	}
+
+	// 16 futures round to whole units and 250 stock to whole lots
+	trades, err := builder.ToValues(map[asset.IAssetReadOnly]float64{future: 1050, stock: 2500})
+	if err != nil || len(trades) != 2 {
+		t.Fatalf("Error in ToValues - %v", err)
+	}
+	if trades[0].GetUnits() != 200 || trades[1].GetUnits() != 16 {
+		t.Errorf("Unexpected units %f, %f", trades[0].GetUnits(), trades[1].GetUnits())
+	}
+
+	// charges are estimated on 200 stock rounded to a lot, and
+	// the 1.2 units they cost leave 253.8 units to round down
+	builder.SetIncludeCharges(true)
+	trades, err = builder.ToValues(map[asset.IAssetReadOnly]float64{stock: 2550})
+	if err != nil || len(trades) != 1 || trades[0].GetUnits() != 200 {
+		t.Fatalf("Error in ToValues with charges - %v", err)
+	}
+	builder.SetIncludeCharges(false)
+
+	// trades below the minimum notional or a lot are left out
+	portfolio.Transfer(future, 16)
+	portfolio.Transfer(stock, 200)
+	trades, err = builder.ToUnits(map[asset.IAssetReadOnly]float64{future: 21, stock: 250})
+	if err != nil || len(trades) != 0 {
+		t.Errorf("Expecting no trades - %v", err)
+	}
+	trades, err = builder.ToUnits(map[asset.IAssetReadOnly]float64{future: 22})
+	if err != nil || len(trades) != 1 || trades[0].GetUnits() != 6 {
+		t.Error("Expecting a trade above the minimum notional")
+	}
+}
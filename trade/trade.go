@@ -38,9 +38,13 @@ func (t *Trade) GetUnits() float64 {
 	return t.units
 }
 
-// GetBaseCurrencyCash returns the cash base currency for this trade.
+// GetBaseCurrencyCash returns the cash base currency for this trade
+// from the cash registry of the trade's portfolio.
 func (t *Trade) GetBaseCurrencyCash() (asset.IAssetReadOnly, error) {
-	return asset.NewCash(t.targetAsset.GetBaseCurrency())
+	if t.portfolio == nil {
+		return asset.NewCash(t.targetAsset.GetBaseCurrency())
+	}
+	return t.portfolio.GetCash(t.targetAsset.GetBaseCurrency())
 }
 
 // GetLocalCurrencyValue returns the trade value.
@@ -96,9 +100,19 @@ func (t *Trade) PassesCompliance() (bool, error) {
 	return passes, nil
 }
 
+// SetExecutedUnits records the units a broker executed for this
+// trade where these differ from the units requested.
+func (t *Trade) SetExecutedUnits(units float64) {
+	t.units = units
+}
+
 // Execute will execute this trade if compliance passes.
 // Returns a boolean representing whether the trade has
-// been executed along with an error.
+// been executed along with an error. Where the broker checks
+// units, compliance is tested on the units the broker will
+// execute and an asset.UnitRuleError is returned if the broker
+// rejects the trade. The trade units are only updated to the
+// units executed once the broker has executed the trade.
 func (t *Trade) Execute() (bool, error) {
 	// check if compliance passes
 	passes, err := t.PassesCompliance()
@@ -34,6 +34,14 @@ func TestTradeInit(t *testing.T) {
 	if cash, _ := trade.GetBaseCurrencyCash(); cash != aud {
 		t.Error("Unexpected base currency cash")
 	}
+
+	// cash is taken from the portfolio's registry
+	registry := asset.NewCashRegistry()
+	portfolio.SetCashRegistry(registry)
+	registryAud, _ := registry.GetCash("AUD")
+	if cash, _ := trade.GetBaseCurrencyCash(); cash != registryAud {
+		t.Error("Expecting base currency cash from the portfolio registry")
+	}
 }
 
 func TestGetLocalCurrencyValue(t *testing.T) {
@@ -250,3 +258,57 @@ func TestTradeExecute(t *testing.T) {
 		t.Error("Unexpected cash position")
 	}
 }
+
+func TestExecuteUnitRules(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	rules, err3 := asset.NewTradingRules(100, 0, 0, false)
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset init - %s", err)
+	}
+	stock.SetPrice(asset.Price{Float64: 2, Valid: true})
+	stock.SetTradingRules(rules)
+	executingBroker := broker.NewBroker(broker.NewNoCharges(), broker.NewFillAtLast())
+	portfolio.SetBroker(executingBroker)
+
+	executed, err := NewTrade(portfolio, stock, 137).Execute()
+	if _, ok := err.(*asset.UnitRuleError); !ok || executed {
+		t.Errorf("Expecting a unit rule error - %v", err)
+	}
+
+	// the trade is resized to the units executed
+	executingBroker.SetUnitPolicy(broker.RoundUnits)
+	trade := NewTrade(portfolio, stock, 137)
+	executed, err = trade.Execute()
+	if err != nil || !executed {
+		t.Fatalf("Error in Execute - %v", err)
+	}
+	if trade.GetUnits() != 100 || portfolio.GetUnits(stock) != 100 {
+		t.Errorf("Unexpected units %f", trade.GetUnits())
+	}
+}
+
+func TestExecuteUnitRulesFailsCompliance(t *testing.T) {
+	portfolio, err1 := asset.NewPortfolio("XXX", "AUD")
+	stock, err2 := asset.NewStock("ZZB AU", "AUD")
+	rules, err3 := asset.NewTradingRules(100, 0, 0, false)
+	if err := btutil.AnyValidError(err1, err2, err3); err != nil {
+		t.Fatalf("Error in asset init - %s", err)
+	}
+	stock.SetPrice(asset.Price{Float64: 2, Valid: true})
+	stock.SetTradingRules(rules)
+	executingBroker := broker.NewBroker(broker.NewNoCharges(), broker.NewFillAtLast())
+	executingBroker.SetUnitPolicy(broker.RoundUnits)
+	portfolio.SetBroker(executingBroker)
+	portfolio.AddComplianceRule(compliance.NewUnitLimit(stock, 50))
+
+	// a trade that fails compliance keeps the units requested
+	trade := NewTrade(portfolio, stock, 137)
+	executed, err := trade.Execute()
+	if err != nil || executed {
+		t.Errorf("Unexpected execution - %v", err)
+	}
+	if trade.GetUnits() != 137 || portfolio.GetUnits(stock) != 0 {
+		t.Errorf("Unexpected units %f", trade.GetUnits())
+	}
+}